@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zeropsio/zerops-mcp-v3/internal/config"
+	"github.com/zeropsio/zerops-mcp-v3/internal/tools"
+	"github.com/zeropsio/zerops-mcp-v3/internal/version"
+)
+
+func main() {
+	// stdout is reserved for the stdio transport, so all logging goes to stderr
+	log.SetOutput(os.Stderr)
+
+	cfg := config.Load()
+
+	transport := flag.String("transport", cfg.Transport, "MCP transport: stdio, sse or http (streamable HTTP)")
+	addr := flag.String("addr", cfg.ListenAddr, "Bind address for sse and http transports")
+	baseURL := flag.String("base-url", cfg.BaseURL, "Public base URL advertised to SSE clients (e.g. when behind a gateway)")
+	format := flag.String("format", cfg.OutputFormat, "Default tool output format: text or json")
+	profile := flag.String("profile", cfg.Profile, "Active API profile from the profiles file")
+	deployBackend := flag.String("deploy-backend", cfg.DeployBackend, "Deploy backend for deploy_push: api or zcli")
+	showVersion := flag.Bool("version", false, "Print version and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("zerops-mcp %s (commit %s, built %s)\n", version.Version, version.GitCommit, version.BuildTime)
+		return
+	}
+
+	cfg.Transport = *transport
+	cfg.ListenAddr = *addr
+	cfg.BaseURL = *baseURL
+	cfg.OutputFormat = *format
+	cfg.Profile = *profile
+	cfg.DeployBackend = *deployBackend
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	options := []server.ServerOption{
+		server.WithToolCapabilities(false),
+		server.WithLogging(),
+		server.WithRecovery(),
+		server.WithInstructions(tools.GetServerInstructions()),
+	}
+	// Client cancellations stop the tool call, including a running zcli push
+	options = append(options, tools.CancellationOptions()...)
+	s := server.NewMCPServer("zerops-mcp", version.Version, options...)
+	if err := tools.RegisterAll(s, cfg); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, s, cfg); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+// run serves the MCP server on the configured transport until ctx is cancelled
+func run(ctx context.Context, s *server.MCPServer, cfg *config.Config) error {
+	switch cfg.Transport {
+	case config.TransportStdio:
+		if cfg.Debug {
+			log.Printf("[DEBUG] Serving MCP over stdio")
+		}
+		stdio := server.NewStdioServer(s)
+		stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+		err := stdio.Listen(ctx, os.Stdin, os.Stdout)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+		return nil
+
+	case config.TransportSSE:
+		var opts []server.SSEOption
+		if cfg.BaseURL != "" {
+			opts = append(opts, server.WithBaseURL(cfg.BaseURL))
+		}
+		sse := server.NewSSEServer(s, opts...)
+		return serveHTTP(ctx, cfg, "SSE", sse.Start, sse.Shutdown)
+
+	case config.TransportStreamableHTTP:
+		streamable := server.NewStreamableHTTPServer(s)
+		return serveHTTP(ctx, cfg, "streamable HTTP", streamable.Start, streamable.Shutdown)
+
+	default:
+		return fmt.Errorf("unsupported transport %q", cfg.Transport)
+	}
+}
+
+// serveHTTP starts an HTTP-based transport and shuts it down gracefully on cancellation
+func serveHTTP(ctx context.Context, cfg *config.Config, name string, start func(string) error, shutdown func(context.Context) error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Serving MCP over %s on %s", name, cfg.ListenAddr)
+		errCh <- start(cfg.ListenAddr)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down %s server (timeout %s)", name, cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Command zerops-vpn-helper runs Zerops VPN commands for the MCP server so it never
+// needs interactive sudo. Run it as root, e.g. from a system service:
+//
+//	sudo zerops-vpn-helper -user "$USER"
+//
+// The socket is owned by the given user (and group, if set), so only they can use it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/version"
+	"github.com/zeropsio/zerops-mcp-v3/internal/vpnhelper"
+)
+
+func main() {
+	log.SetOutput(os.Stderr)
+
+	socketPath := flag.String("socket", vpnhelper.DefaultSocketPath, "Unix socket to listen on")
+	owner := flag.String("user", os.Getenv("SUDO_USER"), "User allowed to use the socket (default: the user running sudo)")
+	group := flag.String("group", "", "Group allowed to use the socket")
+	zcliPath := flag.String("zcli", "zcli", "zcli binary to run")
+	showVersion := flag.Bool("version", false, "Print version and exit")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("zerops-vpn-helper %s (commit %s, built %s)\n", version.Version, version.GitCommit, version.BuildTime)
+		return
+	}
+
+	if os.Geteuid() != 0 {
+		log.Printf("Warning: not running as root; zcli vpn commands will likely fail")
+	}
+	if *owner == "" && *group == "" {
+		log.Fatalf("Set -user or -group to choose who may use the helper")
+	}
+
+	uid, gid, err := socketOwner(*owner, *group)
+	if err != nil {
+		log.Fatalf("Invalid socket owner: %v", err)
+	}
+
+	l, err := listen(*socketPath, uid, gid, *group != "")
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", *socketPath, err)
+	}
+	defer os.Remove(*socketPath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &vpnhelper.Server{
+		ZCLI:    *zcliPath,
+		Version: version.Version,
+		Logger:  log.Default(),
+	}
+	log.Printf("Listening on %s", *socketPath)
+	if err := srv.Serve(ctx, l); err != nil {
+		log.Fatalf("Helper error: %v", err)
+	}
+}
+
+// socketOwner resolves the user and group the socket is handed to; -1 keeps the current one
+func socketOwner(owner, group string) (int, int, error) {
+	uid, gid := -1, -1
+	if owner != "" {
+		u, err := user.Lookup(owner)
+		if err != nil {
+			return 0, 0, err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, fmt.Errorf("unsupported uid %q", u.Uid)
+		}
+	}
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return 0, 0, err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, fmt.Errorf("unsupported gid %q", g.Gid)
+		}
+	}
+	return uid, gid, nil
+}
+
+// listen creates the socket, replacing a stale one, and restricts it to its owner
+func listen(path string, uid, gid int, groupAccess bool) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := os.FileMode(0o600)
+	if groupAccess {
+		mode = 0o660
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Chown(path, uid, gid); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,1159 @@
+package apitest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"gopkg.in/yaml.v3"
+)
+
+// managedTypes lists service types that run without a user deployment
+var managedTypes = []string{
+	"postgresql", "mariadb", "mysql", "mongodb", "valkey", "keydb", "redis",
+	"rabbitmq", "elasticsearch", "opensearch", "meilisearch", "typesense",
+	"clickhouse", "qdrant", "nats", "kafka", "object-storage", "shared-storage",
+}
+
+// hostnamePattern matches valid Zerops service hostnames
+var hostnamePattern = regexp.MustCompile(`^[a-z][a-z0-9]{0,39}$`)
+
+// importedService is a single service definition from import YAML
+type importedService struct {
+	Hostname              string                 `yaml:"hostname"`
+	Type                  string                 `yaml:"type"`
+	Mode                  string                 `yaml:"mode"`
+	MinContainers         int                    `yaml:"minContainers"`
+	MaxContainers         int                    `yaml:"maxContainers"`
+	EnableSubdomainAccess bool                   `yaml:"enableSubdomainAccess"`
+	EnvVariables          map[string]interface{} `yaml:"envVariables"`
+	EnvSecrets            map[string]interface{} `yaml:"envSecrets"`
+	Ports                 []struct {
+		Port        int    `yaml:"port"`
+		Protocol    string `yaml:"protocol"`
+		HTTPSupport bool   `yaml:"httpSupport"`
+	} `yaml:"ports"`
+}
+
+// routes builds the request router
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/rest/public/user/info", s.handleUserInfo)
+	mux.HandleFunc("GET /api/rest/public/region", s.handleRegions)
+
+	mux.HandleFunc("POST /api/rest/public/project", s.handleCreateProject)
+	mux.HandleFunc("POST /api/rest/public/project/search", s.handleSearchProjects)
+	mux.HandleFunc("GET /api/rest/public/project/{id}", s.handleGetProject)
+	mux.HandleFunc("DELETE /api/rest/public/project/{id}", s.handleDeleteProject)
+	mux.HandleFunc("GET /api/rest/public/project/{id}/service-stack", s.handleProjectServices)
+	mux.HandleFunc("GET /api/rest/public/project/{id}/log", s.handleLogAccess)
+	mux.HandleFunc("POST /api/rest/public/project-env", s.handleCreateProjectEnv)
+	mux.HandleFunc("POST /api/rest/public/project-env/search", s.handleSearchProjectEnvs)
+	mux.HandleFunc("PUT /api/rest/public/project-env/{id}", s.handleUpdateProjectEnv)
+	mux.HandleFunc("DELETE /api/rest/public/project-env/{id}", s.handleDeleteProjectEnv)
+
+	mux.HandleFunc("POST /api/rest/public/service-stack/import", s.handleImport)
+	mux.HandleFunc("POST /api/rest/public/service-stack/search", s.handleSearchServices)
+	mux.HandleFunc("GET /api/rest/public/service-stack/{id}", s.handleGetService)
+	mux.HandleFunc("DELETE /api/rest/public/service-stack/{id}", s.handleDeleteService)
+	mux.HandleFunc("PUT /api/rest/public/service-stack/{id}/start", s.handleStartService)
+	mux.HandleFunc("PUT /api/rest/public/service-stack/{id}/stop", s.handleStopService)
+	mux.HandleFunc("PUT /api/rest/public/service-stack/{id}/restart", s.handleRestartService)
+	mux.HandleFunc("PUT /api/rest/public/service-stack/{id}/reload", s.handleReloadService)
+	mux.HandleFunc("PUT /api/rest/public/service-stack/{id}/enable-subdomain-access", s.handleEnableSubdomain)
+	mux.HandleFunc("PUT /api/rest/public/service-stack/{id}/disable-subdomain-access", s.handleDisableSubdomain)
+
+	mux.HandleFunc("POST /api/rest/public/user-data", s.handleCreateServiceEnv)
+	mux.HandleFunc("POST /api/rest/public/user-data/search", s.handleSearchServiceEnvs)
+	mux.HandleFunc("PUT /api/rest/public/user-data/{id}", s.handleUpdateServiceEnv)
+	mux.HandleFunc("DELETE /api/rest/public/user-data/{id}", s.handleDeleteServiceEnv)
+
+	mux.HandleFunc("POST /api/rest/public/app-version", s.handleCreateAppVersion)
+	mux.HandleFunc("POST /api/rest/public/app-version/search", s.handleSearchAppVersions)
+	mux.HandleFunc("PUT /api/rest/public/app-version/{id}/upload", s.handleUploadAppVersion)
+	mux.HandleFunc("PUT /api/rest/public/app-version/{id}/build-and-deploy", s.handleBuildAndDeploy)
+
+	mux.HandleFunc("GET /api/rest/public/process/{id}", s.handleGetProcess)
+
+	mux.HandleFunc("GET /api/rest/log", s.handleLogProxy)
+
+	return s.middleware(mux)
+}
+
+// middleware records requests, applies injected errors and checks authentication
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		injected := s.matchError(r)
+		s.mu.Unlock()
+
+		if injected != nil {
+			writeErrorMeta(w, injected.StatusCode, injected.Code, injected.Message, injected.Meta)
+			return
+		}
+
+		// The log proxy authenticates via access token instead of the API key
+		if !strings.HasPrefix(r.URL.Path, "/api/rest/log") {
+			if r.Header.Get("Authorization") != "Bearer "+s.APIKey {
+				writeError(w, http.StatusUnauthorized, "authFailed", "Invalid or missing API key")
+				return
+			}
+		}
+
+		w.Header().Set("X-Request-Id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.user)
+}
+
+func (s *Server) handleRegions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": s.regions})
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateProjectRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "invalidParameter", "project name is required")
+		return
+	}
+	if !s.hasRegion(req.RegionID) {
+		writeError(w, http.StatusBadRequest, "invalidRegion", fmt.Sprintf("invalid region %q", req.RegionID))
+		return
+	}
+	if req.ClientID == "" {
+		writeError(w, http.StatusBadRequest, "invalidParameter", "clientId is required")
+		return
+	}
+	for _, p := range s.projects {
+		if p.ClientID == req.ClientID && p.Name == req.Name {
+			writeError(w, http.StatusConflict, "projectAlreadyExists", fmt.Sprintf("project %s already exists", req.Name))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, s.createProject(req))
+}
+
+func (s *Server) handleSearchProjects(w http.ResponseWriter, r *http.Request) {
+	var req api.SearchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := s.sortedProjects(filterValue(req.Search, "clientId"))
+	writeJSON(w, http.StatusOK, paginate(projects, req.Limit, req.Offset))
+}
+
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "projectNotFound", "Project not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleDeleteProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	p, ok := s.projects[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "projectNotFound", "Project not found")
+		return
+	}
+	p.Status = "DELETING"
+
+	proc := s.newProcess("project.delete", id, "", func() {
+		delete(s.projects, id)
+		for svcID, svc := range s.services {
+			if svc.ProjectID == id {
+				delete(s.services, svcID)
+			}
+		}
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleProjectServices(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.projects[id]; !ok {
+		writeError(w, http.StatusNotFound, "projectNotFound", "Project not found")
+		return
+	}
+	services := s.sortedServices(id)
+	if services == nil {
+		services = []api.Service{}
+	}
+	writeJSON(w, http.StatusOK, services)
+}
+
+func (s *Server) handleLogAccess(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.projects[id]; !ok {
+		writeError(w, http.StatusNotFound, "projectNotFound", "Project not found")
+		return
+	}
+	token := "logtoken-" + randomHex(8)
+	s.logTokens[token] = id
+	writeJSON(w, http.StatusOK, map[string]string{
+		"accessToken": token,
+		"url":         s.URL + "/api/rest/log",
+	})
+}
+
+func (s *Server) handleCreateProjectEnv(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateProjectEnvRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[req.ProjectID]; !ok {
+		writeError(w, http.StatusNotFound, "projectNotFound", "Project not found")
+		return
+	}
+	for _, env := range s.projectEnvs[req.ProjectID] {
+		if env.Key == req.Key {
+			writeError(w, http.StatusBadRequest, "projectEnvAlreadyExists", fmt.Sprintf("env %s already exists", req.Key))
+			return
+		}
+	}
+
+	now := time.Now()
+	s.projectEnvs[req.ProjectID] = append(s.projectEnvs[req.ProjectID], api.ProjectEnv{
+		ID:        s.nextID("env"),
+		ProjectID: req.ProjectID,
+		Key:       req.Key,
+		Content:   req.Content,
+		Sensitive: req.Sensitive,
+		Created:   now,
+		Updated:   now,
+	})
+
+	proc := s.newProcess("projectEnv.create", req.ProjectID, "", nil)
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleSearchProjectEnvs(w http.ResponseWriter, r *http.Request) {
+	var req api.SearchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	envs := append([]api.ProjectEnv(nil), s.projectEnvs[filterValue(req.Search, "projectId")]...)
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Key < envs[j].Key })
+	writeJSON(w, http.StatusOK, paginate(envs, req.Limit, req.Offset))
+}
+
+func (s *Server) handleUpdateProjectEnv(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateEnvRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	env := s.findProjectEnv(r.PathValue("id"))
+	if env == nil {
+		writeError(w, http.StatusNotFound, "projectEnvNotFound", "Project env not found")
+		return
+	}
+	env.Content = req.Content
+	env.Sensitive = req.Sensitive
+	env.Updated = time.Now()
+
+	proc := s.newProcess("projectEnv.update", env.ProjectID, "", nil)
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleDeleteProjectEnv(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	env := s.findProjectEnv(id)
+	if env == nil {
+		writeError(w, http.StatusNotFound, "projectEnvNotFound", "Project env not found")
+		return
+	}
+	projectID := env.ProjectID
+	envs := s.projectEnvs[projectID]
+	for i := range envs {
+		if envs[i].ID == id {
+			s.projectEnvs[projectID] = append(envs[:i], envs[i+1:]...)
+			break
+		}
+	}
+
+	proc := s.newProcess("projectEnv.delete", projectID, "", nil)
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	var req api.ImportRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	var doc struct {
+		Project  map[string]interface{} `yaml:"project"`
+		Services []importedService      `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(req.YAML), &doc); err != nil {
+		writeError(w, http.StatusBadRequest, "projectImportInvalidYaml", fmt.Sprintf("invalid yaml: %v", err))
+		return
+	}
+	if doc.Project != nil {
+		writeError(w, http.StatusBadRequest, "projectImportProjectIncluded", "project definition is not allowed when importing into an existing project")
+		return
+	}
+	if len(doc.Services) == 0 {
+		writeError(w, http.StatusBadRequest, "projectImportInvalidParameter", "services section is missing or empty")
+		return
+	}
+	for _, svc := range doc.Services {
+		if !hostnamePattern.MatchString(svc.Hostname) {
+			writeError(w, http.StatusBadRequest, "serviceStackNameInvalid", fmt.Sprintf("invalid service name %q", svc.Hostname))
+			return
+		}
+		if svc.Type == "" || (svc.Type != "static" && !strings.Contains(svc.Type, "@")) {
+			writeError(w, http.StatusBadRequest, "serviceStackTypeNotFound", fmt.Sprintf("unknown type %q", svc.Type))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[req.ProjectID]; !ok {
+		writeError(w, http.StatusNotFound, "projectNotFound", "Project not found")
+		return
+	}
+	for _, svc := range doc.Services {
+		for _, existing := range s.services {
+			if existing.ProjectID == req.ProjectID && existing.Name == svc.Hostname {
+				writeError(w, http.StatusConflict, "serviceStackNameUnavailable", fmt.Sprintf("service %s already exists", svc.Hostname))
+				return
+			}
+		}
+	}
+
+	var stacks []map[string]interface{}
+	for _, def := range doc.Services {
+		svc := s.createService(req.ProjectID, def)
+		proc := s.newProcess("serviceStack.import", req.ProjectID, svc.ID, nil)
+		stacks = append(stacks, map[string]interface{}{
+			"id":      svc.ID,
+			"name":    svc.Name,
+			"process": proc.Process,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"projectId":     req.ProjectID,
+		"serviceStacks": stacks,
+	})
+}
+
+func (s *Server) handleSearchServices(w http.ResponseWriter, r *http.Request) {
+	var req api.SearchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clientID := filterValue(req.Search, "clientId")
+	var services []api.Service
+	for _, svc := range s.sortedServices(filterValue(req.Search, "projectId")) {
+		if clientID == "" || svc.ClientID == clientID {
+			services = append(services, svc)
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(services, req.Limit, req.Offset))
+}
+
+func (s *Server) handleGetService(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, ok := s.services[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, svc)
+}
+
+func (s *Server) handleDeleteService(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	svc, ok := s.services[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	svc.Status = "DELETING"
+	proc := s.newProcess("serviceStack.delete", svc.ProjectID, id, func() {
+		delete(s.services, id)
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleStartService(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	svc, ok := s.services[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	if svc.Status != "STOPPED" {
+		writeError(w, http.StatusBadRequest, "invalidServiceStackStatus", fmt.Sprintf("service stack is in status %s", svc.Status))
+		return
+	}
+	svc.Status = "STARTING"
+	proc := s.newProcess("serviceStack.start", svc.ProjectID, id, func() {
+		svc.Status = runningStatus(svc)
+		svc.LastUpdate = time.Now()
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleStopService(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	svc, ok := s.services[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	if svc.Status != "RUNNING" && svc.Status != "ACTIVE" {
+		writeError(w, http.StatusBadRequest, "invalidServiceStackStatus", fmt.Sprintf("service stack is in status %s", svc.Status))
+		return
+	}
+	svc.Status = "STOPPING"
+	proc := s.newProcess("serviceStack.stop", svc.ProjectID, id, func() {
+		svc.Status = "STOPPED"
+		svc.LastUpdate = time.Now()
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleRestartService(w http.ResponseWriter, r *http.Request) {
+	s.restartService(w, r, "serviceStack.restart")
+}
+
+func (s *Server) handleReloadService(w http.ResponseWriter, r *http.Request) {
+	s.restartService(w, r, "serviceStack.reload")
+}
+
+// restartService starts a restart or reload process for a running service
+func (s *Server) restartService(w http.ResponseWriter, r *http.Request, action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	svc, ok := s.services[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	if svc.Status != "RUNNING" && svc.Status != "ACTIVE" {
+		writeError(w, http.StatusBadRequest, "invalidServiceStackStatus", fmt.Sprintf("service stack is in status %s", svc.Status))
+		return
+	}
+	proc := s.newProcess(action, svc.ProjectID, id, func() {
+		svc.LastUpdate = time.Now()
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleEnableSubdomain(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	svc, ok := s.services[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	port := httpPort(svc)
+	if port == 0 {
+		writeError(w, http.StatusBadRequest, "serviceStackIsNotHttp", "service stack has no HTTP ports")
+		return
+	}
+	proc := s.newProcess("serviceStack.enableSubdomainAccess", svc.ProjectID, id, func() {
+		svc.SubdomainAccess = true
+		if p, ok := s.projects[svc.ProjectID]; ok && p.ZeropsSubdomainHost != nil {
+			host := fmt.Sprintf("%s-%s-%d.%s.zerops.app", svc.Name, *p.ZeropsSubdomainHost, port, p.RegionID)
+			svc.ZeropsSubdomainHost = &host
+		}
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleDisableSubdomain(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	svc, ok := s.services[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	proc := s.newProcess("serviceStack.disableSubdomainAccess", svc.ProjectID, id, func() {
+		svc.SubdomainAccess = false
+		svc.ZeropsSubdomainHost = nil
+	})
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleCreateServiceEnv(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateServiceEnvRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, ok := s.services[req.ServiceStackID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	for _, env := range s.serviceEnvs[svc.ID] {
+		if env.Key == req.Key {
+			writeError(w, http.StatusBadRequest, "userDataAlreadyExists", fmt.Sprintf("env %s already exists", req.Key))
+			return
+		}
+	}
+
+	s.addServiceEnv(svc, req.Key, req.Content, req.Sensitive)
+	proc := s.newProcess("userData.create", svc.ProjectID, svc.ID, nil)
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleSearchServiceEnvs(w http.ResponseWriter, r *http.Request) {
+	var req api.SearchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	envs := append([]api.ServiceEnv(nil), s.serviceEnvs[filterValue(req.Search, "serviceStackId")]...)
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Key < envs[j].Key })
+	writeJSON(w, http.StatusOK, paginate(envs, req.Limit, req.Offset))
+}
+
+func (s *Server) handleUpdateServiceEnv(w http.ResponseWriter, r *http.Request) {
+	var req api.UpdateEnvRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	env := s.findServiceEnv(r.PathValue("id"))
+	if env == nil {
+		writeError(w, http.StatusNotFound, "userDataNotFound", "User data not found")
+		return
+	}
+	env.Content = req.Content
+	env.Sensitive = req.Sensitive
+	env.LastUpdate = time.Now()
+
+	projectID := ""
+	if svc, ok := s.services[env.ServiceStackID]; ok {
+		svc.EnvVariables[env.Key] = env.Content
+		projectID = svc.ProjectID
+	}
+	proc := s.newProcess("userData.update", projectID, env.ServiceStackID, nil)
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleDeleteServiceEnv(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	env := s.findServiceEnv(id)
+	if env == nil {
+		writeError(w, http.StatusNotFound, "userDataNotFound", "User data not found")
+		return
+	}
+	serviceID, key := env.ServiceStackID, env.Key
+	envs := s.serviceEnvs[serviceID]
+	for i := range envs {
+		if envs[i].ID == id {
+			s.serviceEnvs[serviceID] = append(envs[:i], envs[i+1:]...)
+			break
+		}
+	}
+
+	projectID := ""
+	if svc, ok := s.services[serviceID]; ok {
+		delete(svc.EnvVariables, key)
+		projectID = svc.ProjectID
+	}
+	proc := s.newProcess("userData.delete", projectID, serviceID, nil)
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleSearchAppVersions(w http.ResponseWriter, r *http.Request) {
+	var req api.SearchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	serviceID := filterValue(req.Search, "serviceStackId")
+	var versions []api.AppVersion
+	for _, v := range s.appVersions {
+		if serviceID == "" || v.ServiceStackID == serviceID {
+			versions = append(versions, *v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Sequence > versions[j].Sequence
+	})
+	writeJSON(w, http.StatusOK, paginate(versions, req.Limit, req.Offset))
+}
+
+func (s *Server) handleCreateAppVersion(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateAppVersionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, ok := s.services[req.ServiceStackID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "serviceStackNotFound", "Service stack not found")
+		return
+	}
+	if isManaged(svc.ServiceStackTypeInfo.ServiceStackTypeName) {
+		writeError(w, http.StatusBadRequest, "serviceStackIsNotDeployable", fmt.Sprintf("service stack %s does not accept deployments", svc.Name))
+		return
+	}
+
+	sequence := 1
+	for _, v := range s.appVersions {
+		if v.ServiceStackID == svc.ID && v.Sequence >= sequence {
+			sequence = v.Sequence + 1
+		}
+	}
+	now := time.Now()
+	version := &api.AppVersion{
+		ID:             s.nextID("appversion"),
+		ProjectID:      svc.ProjectID,
+		ServiceStackID: svc.ID,
+		Sequence:       sequence,
+		Status:         "UPLOADING",
+		Source:         "API",
+		Created:        now,
+		LastUpdate:     now,
+	}
+	version.UploadURL = fmt.Sprintf("%s/api/rest/public/app-version/%s/upload", s.URL, version.ID)
+	s.appVersions[version.ID] = version
+	writeJSON(w, http.StatusOK, version)
+}
+
+func (s *Server) handleUploadAppVersion(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidRequestBody", fmt.Sprintf("failed to read archive: %v", err))
+		return
+	}
+	if _, err := archiveFiles(body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidArchive", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, ok := s.appVersions[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "appVersionNotFound", "App version not found")
+		return
+	}
+	if version.Status != "UPLOADING" {
+		writeError(w, http.StatusBadRequest, "appVersionInvalidStatus", fmt.Sprintf("app version is in status %s", version.Status))
+		return
+	}
+	s.uploads[version.ID] = body
+	version.Status = "UPLOADED"
+	version.LastUpdate = time.Now()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleBuildAndDeploy(w http.ResponseWriter, r *http.Request) {
+	var req api.BuildAndDeployRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	zeropsYaml, err := base64.StdEncoding.DecodeString(req.ZeropsYaml)
+	if err != nil || len(zeropsYaml) == 0 {
+		writeError(w, http.StatusBadRequest, "zeropsYamlInvalid", "zeropsYaml must be base64-encoded zerops.yml content")
+		return
+	}
+	var doc struct {
+		Zerops []struct {
+			Setup string `yaml:"setup"`
+		} `yaml:"zerops"`
+	}
+	if err := yaml.Unmarshal(zeropsYaml, &doc); err != nil {
+		writeError(w, http.StatusBadRequest, "zeropsYamlInvalid", fmt.Sprintf("invalid zerops.yml: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, ok := s.appVersions[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "appVersionNotFound", "App version not found")
+		return
+	}
+	if version.Status != "UPLOADED" {
+		writeError(w, http.StatusBadRequest, "appVersionInvalidStatus", fmt.Sprintf("app version is in status %s", version.Status))
+		return
+	}
+	svc := s.services[version.ServiceStackID]
+	setup := req.ZeropsYamlSetup
+	if setup == "" && svc != nil {
+		setup = svc.Name
+	}
+	found := false
+	for _, entry := range doc.Zerops {
+		if entry.Setup == setup {
+			found = true
+		}
+	}
+	if !found {
+		writeError(w, http.StatusBadRequest, "zeropsYamlSetupNotFound", fmt.Sprintf("setup %q not found in zerops.yml", setup))
+		return
+	}
+
+	now := time.Now()
+	version.Status = "BUILDING"
+	version.LastUpdate = now
+	version.Build = &api.AppVersionBuild{
+		ServiceStackID: s.nextID("build"),
+		PipelineStart:  &now,
+	}
+	proc := s.newProcess("serviceStack.deploy", version.ProjectID, version.ServiceStackID, func() {
+		finished := time.Now()
+		version.Status = "ACTIVE"
+		version.LastUpdate = finished
+		version.Build.PipelineFinish = &finished
+		if svc != nil {
+			svc.Status = "RUNNING"
+			svc.LastUpdate = finished
+		}
+	})
+	if proc.fail {
+		version.Status = "BUILD_FAILED"
+		version.Build.PipelineFailed = &now
+	}
+	writeJSON(w, http.StatusOK, proc.Process)
+}
+
+func (s *Server) handleGetProcess(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.processes[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "processNotFound", "Process not found")
+		return
+	}
+	s.advanceProcess(p)
+	writeJSON(w, http.StatusOK, p.Process)
+}
+
+func (s *Server) handleLogProxy(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projectID, ok := s.logTokens[q.Get("accessToken")]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalidAccessToken", "Invalid log access token")
+		return
+	}
+
+	minSeverity := -1
+	if v, err := strconv.Atoi(q.Get("minimumSeverity")); err == nil {
+		minSeverity = v
+	}
+
+	var items []LogItem
+	serviceID := q.Get("serviceStackId")
+	tags := q.Get("tags")
+	for svcID, logs := range s.logs {
+		if serviceID != "" && svcID != serviceID {
+			continue
+		}
+		for _, item := range logs {
+			if item.ProjectID != projectID {
+				continue
+			}
+			if tags != "" && item.Tag != tags {
+				continue
+			}
+			if minSeverity >= 0 && item.Severity > minSeverity {
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+	sortLogs(items)
+
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 && len(items) > limit {
+		items = items[len(items)-limit:]
+	}
+	if items == nil {
+		items = []LogItem{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+}
+
+// createService stores a new service from an import definition
+func (s *Server) createService(projectID string, def importedService) *api.ServiceDetails {
+	now := time.Now()
+	typeName, _, _ := strings.Cut(def.Type, "@")
+	managed := isManaged(typeName)
+
+	category := "USER"
+	status := "READY_TO_DEPLOY"
+	if managed {
+		category = "STANDARD"
+		status = "ACTIVE"
+	}
+
+	mode := def.Mode
+	if mode == "" {
+		mode = "NON_HA"
+	}
+	minContainers, maxContainers := def.MinContainers, def.MaxContainers
+	if minContainers == 0 {
+		minContainers = 1
+	}
+	if maxContainers < minContainers {
+		maxContainers = minContainers
+	}
+
+	svc := &api.ServiceDetails{
+		Service: api.Service{
+			ID:                        s.nextID("service"),
+			ProjectID:                 projectID,
+			ClientID:                  DefaultClientID,
+			Name:                      def.Hostname,
+			Status:                    status,
+			Mode:                      mode,
+			Created:                   now,
+			LastUpdate:                now,
+			ServiceStackTypeID:        typeName,
+			ServiceStackTypeVersionID: def.Type,
+			ServiceStackTypeInfo: api.ServiceStackTypeInfo{
+				ServiceStackTypeName:        typeName,
+				ServiceStackTypeCategory:    category,
+				ServiceStackTypeVersionName: def.Type,
+			},
+			Ports:         []api.Port{},
+			MinContainers: minContainers,
+			MaxContainers: maxContainers,
+		},
+		EnvVariables: map[string]interface{}{
+			"hostname": def.Hostname,
+		},
+	}
+	if p, ok := s.projects[projectID]; ok {
+		svc.ClientID = p.ClientID
+	}
+
+	for _, port := range def.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		scheme := ""
+		if port.HTTPSupport {
+			scheme = "http"
+		}
+		svc.Ports = append(svc.Ports, api.Port{
+			Port:        port.Port,
+			Protocol:    protocol,
+			HTTPRouting: port.HTTPSupport,
+			Scheme:      scheme,
+			ServiceID:   svc.ID,
+		})
+	}
+	for key, value := range def.EnvVariables {
+		s.addServiceEnv(svc, key, fmt.Sprintf("%v", value), false)
+	}
+	for key, value := range def.EnvSecrets {
+		s.addServiceEnv(svc, key, fmt.Sprintf("%v", value), true)
+	}
+	if managed {
+		svc.EnvVariables["user"] = def.Hostname
+		svc.EnvVariables["password"] = randomHex(12)
+	}
+
+	s.services[svc.ID] = svc
+	return svc
+}
+
+// SimulateDeploy marks a runtime service as deployed and running, exposing an HTTP port
+func (s *Server) SimulateDeploy(serviceID string, port int) {
+	s.mu.Lock()
+	svc, ok := s.services[serviceID]
+	if ok {
+		if httpPort(svc) == 0 {
+			svc.Ports = append(svc.Ports, api.Port{
+				Port:        port,
+				Protocol:    "tcp",
+				HTTPRouting: true,
+				Scheme:      "http",
+				ServiceID:   serviceID,
+			})
+		}
+		svc.Status = "RUNNING"
+		svc.LastUpdate = time.Now()
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.AddLogs(serviceID, LogItem{Tag: "zerops", Message: "Application started", Severity: 6, Priority: 6})
+	}
+}
+
+// addServiceEnv stores a user-defined environment variable of a service
+func (s *Server) addServiceEnv(svc *api.ServiceDetails, key, content string, sensitive bool) {
+	now := time.Now()
+	s.serviceEnvs[svc.ID] = append(s.serviceEnvs[svc.ID], api.ServiceEnv{
+		ID:             s.nextID("userdata"),
+		ClientID:       svc.ClientID,
+		ServiceStackID: svc.ID,
+		Key:            key,
+		Content:        content,
+		Sensitive:      sensitive,
+		Created:        now,
+		LastUpdate:     now,
+	})
+	svc.EnvVariables[key] = content
+}
+
+// findProjectEnv returns the project environment variable with the given ID
+func (s *Server) findProjectEnv(id string) *api.ProjectEnv {
+	for _, envs := range s.projectEnvs {
+		for i := range envs {
+			if envs[i].ID == id {
+				return &envs[i]
+			}
+		}
+	}
+	return nil
+}
+
+// findServiceEnv returns the service environment variable with the given ID
+func (s *Server) findServiceEnv(id string) *api.ServiceEnv {
+	for _, envs := range s.serviceEnvs {
+		for i := range envs {
+			if envs[i].ID == id {
+				return &envs[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) hasRegion(name string) bool {
+	for _, r := range s.regions {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isManaged reports whether a service type is a managed (non-runtime) service
+func isManaged(typeName string) bool {
+	for _, t := range managedTypes {
+		if typeName == t {
+			return true
+		}
+	}
+	return false
+}
+
+// runningStatus returns the status a started service ends up in
+func runningStatus(svc *api.ServiceDetails) string {
+	if svc.ServiceStackTypeInfo.ServiceStackTypeCategory == "USER" {
+		return "RUNNING"
+	}
+	return "ACTIVE"
+}
+
+// httpPort returns the first HTTP port of a service or 0
+func httpPort(svc *api.ServiceDetails) int {
+	for _, p := range svc.Ports {
+		if p.HTTPRouting || p.Scheme == "http" || p.Scheme == "https" {
+			return p.Port
+		}
+	}
+	return 0
+}
+
+// archiveFiles lists the entries of a gzipped tar archive
+func archiveFiles(data []byte) ([]string, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("archive is not gzipped: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	var files []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive is not a valid tar: %w", err)
+		}
+		files = append(files, hdr.Name)
+	}
+}
+
+// filterValue returns the string value of an "eq" search filter
+func filterValue(filters []api.SearchFilter, name string) string {
+	for _, f := range filters {
+		if f.Name == name && (f.Operator == "eq" || f.Operator == "") {
+			return fmt.Sprintf("%v", f.Value)
+		}
+	}
+	return ""
+}
+
+// paginate applies limit/offset and wraps items in a search result
+func paginate[T any](items []T, limit, offset int) api.SearchResult[T] {
+	total := len(items)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	page := items[offset:end]
+	if page == nil {
+		page = []T{}
+	}
+	return api.SearchResult[T]{
+		Items:     page,
+		Limit:     limit,
+		Offset:    offset,
+		TotalHits: total,
+	}
+}
+
+// sortLogs orders log items chronologically
+func sortLogs(items []LogItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Timestamp.Before(items[j].Timestamp)
+	})
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidRequestBody", fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeErrorMeta(w, status, code, message, nil)
+}
+
+func writeErrorMeta(w http.ResponseWriter, status int, code, message string, meta []api.ErrorMeta) {
+	writeJSON(w, status, api.ErrorResponse{
+		Error: api.ErrorDetail{
+			Code:    code,
+			Message: message,
+			Meta:    meta,
+		},
+	})
+}
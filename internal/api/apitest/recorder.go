@@ -0,0 +1,551 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// Interaction is a single recorded API call with its result
+type Interaction struct {
+	Method   string            `json:"method"`
+	Args     []json.RawMessage `json:"args,omitempty"`
+	Response json.RawMessage   `json:"response,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	APIError *zerrors.APIError `json:"apiError,omitempty"`
+}
+
+// Recorder decorates a ZeropsAPI and records every call as an Interaction
+type Recorder struct {
+	next         api.ZeropsAPI
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder creates a recorder that forwards calls to next
+func NewRecorder(next api.ZeropsAPI) *Recorder {
+	return &Recorder{next: next}
+}
+
+// Interactions returns the calls recorded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes the recorded interactions to a JSON fixture file
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interactions: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// add appends an interaction
+func (r *Recorder) add(method string, args []interface{}, response interface{}, err error) {
+	in := Interaction{Method: method, Args: marshalArgs(args)}
+	if response != nil {
+		if data, mErr := json.Marshal(response); mErr == nil {
+			in.Response = data
+		}
+	}
+	if err != nil {
+		in.Error = err.Error()
+		if apiErr, ok := zerrors.AsAPIError(err); ok {
+			in.APIError = apiErr
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, in)
+}
+
+// record runs call and stores its result
+func record[T any](r *Recorder, method string, call func() (T, error), args ...interface{}) (T, error) {
+	res, err := call()
+	r.add(method, args, res, err)
+	return res, err
+}
+
+// recordErr runs a call without a result value and stores its error
+func (r *Recorder) recordErr(method string, call func() error, args ...interface{}) error {
+	err := call()
+	r.add(method, args, nil, err)
+	return err
+}
+
+func (r *Recorder) GetBaseURL(ctx context.Context) string {
+	url := r.next.GetBaseURL(ctx)
+	r.add("GetBaseURL", nil, url, nil)
+	return url
+}
+
+func (r *Recorder) GetCurrentUser(ctx context.Context) (*api.User, error) {
+	return record(r, "GetCurrentUser", func() (*api.User, error) { return r.next.GetCurrentUser(ctx) })
+}
+
+func (r *Recorder) GetClientID(ctx context.Context) (string, error) {
+	return record(r, "GetClientID", func() (string, error) { return r.next.GetClientID(ctx) })
+}
+
+func (r *Recorder) ListRegions(ctx context.Context) ([]api.Region, error) {
+	return record(r, "ListRegions", func() ([]api.Region, error) { return r.next.ListRegions(ctx) })
+}
+
+func (r *Recorder) CreateProject(ctx context.Context, req api.CreateProjectRequest) (*api.Project, error) {
+	return record(r, "CreateProject", func() (*api.Project, error) { return r.next.CreateProject(ctx, req) }, req)
+}
+
+func (r *Recorder) GetProject(ctx context.Context, projectID string) (*api.Project, error) {
+	return record(r, "GetProject", func() (*api.Project, error) { return r.next.GetProject(ctx, projectID) }, projectID)
+}
+
+func (r *Recorder) ListProjects(ctx context.Context, clientID string) ([]api.Project, error) {
+	return record(r, "ListProjects", func() ([]api.Project, error) { return r.next.ListProjects(ctx, clientID) }, clientID)
+}
+
+func (r *Recorder) SearchProjects(ctx context.Context, req api.SearchRequest) (*api.SearchResult[api.Project], error) {
+	return record(r, "SearchProjects", func() (*api.SearchResult[api.Project], error) { return r.next.SearchProjects(ctx, req) }, req)
+}
+
+func (r *Recorder) DeleteProject(ctx context.Context, projectID string) (*api.Process, error) {
+	return record(r, "DeleteProject", func() (*api.Process, error) { return r.next.DeleteProject(ctx, projectID) }, projectID)
+}
+
+func (r *Recorder) ImportProjectServices(ctx context.Context, projectID, clientID, yamlData string) error {
+	return r.recordErr("ImportProjectServices", func() error {
+		return r.next.ImportProjectServices(ctx, projectID, clientID, yamlData)
+	}, projectID, clientID, yamlData)
+}
+
+func (r *Recorder) ImportProject(ctx context.Context, req api.ImportRequest) error {
+	return r.recordErr("ImportProject", func() error { return r.next.ImportProject(ctx, req) }, req)
+}
+
+func (r *Recorder) CreateProjectEnv(ctx context.Context, projectID, key, content string, sensitive bool) (*api.Process, error) {
+	return record(r, "CreateProjectEnv", func() (*api.Process, error) {
+		return r.next.CreateProjectEnv(ctx, projectID, key, content, sensitive)
+	}, projectID, key, content, sensitive)
+}
+
+func (r *Recorder) ListProjectEnvs(ctx context.Context, projectID string) ([]api.ProjectEnv, error) {
+	return record(r, "ListProjectEnvs", func() ([]api.ProjectEnv, error) { return r.next.ListProjectEnvs(ctx, projectID) }, projectID)
+}
+
+func (r *Recorder) UpdateProjectEnv(ctx context.Context, envID, content string, sensitive bool) (*api.Process, error) {
+	return record(r, "UpdateProjectEnv", func() (*api.Process, error) { return r.next.UpdateProjectEnv(ctx, envID, content, sensitive) }, envID, content, sensitive)
+}
+
+func (r *Recorder) DeleteProjectEnv(ctx context.Context, envID string) (*api.Process, error) {
+	return record(r, "DeleteProjectEnv", func() (*api.Process, error) { return r.next.DeleteProjectEnv(ctx, envID) }, envID)
+}
+
+func (r *Recorder) GetProjectServices(ctx context.Context, projectID string) ([]api.Service, error) {
+	return record(r, "GetProjectServices", func() ([]api.Service, error) { return r.next.GetProjectServices(ctx, projectID) }, projectID)
+}
+
+func (r *Recorder) ListServices(ctx context.Context, projectID string) ([]api.Service, error) {
+	return record(r, "ListServices", func() ([]api.Service, error) { return r.next.ListServices(ctx, projectID) }, projectID)
+}
+
+func (r *Recorder) SearchServices(ctx context.Context, req api.SearchRequest) (*api.SearchResult[api.Service], error) {
+	return record(r, "SearchServices", func() (*api.SearchResult[api.Service], error) { return r.next.SearchServices(ctx, req) }, req)
+}
+
+func (r *Recorder) GetService(ctx context.Context, serviceID string) (*api.ServiceDetails, error) {
+	return record(r, "GetService", func() (*api.ServiceDetails, error) { return r.next.GetService(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) StartService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return record(r, "StartService", func() (*api.Process, error) { return r.next.StartService(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) StopService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return record(r, "StopService", func() (*api.Process, error) { return r.next.StopService(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) RestartService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return record(r, "RestartService", func() (*api.Process, error) { return r.next.RestartService(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) ReloadService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return record(r, "ReloadService", func() (*api.Process, error) { return r.next.ReloadService(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) DeleteService(ctx context.Context, serviceID string) error {
+	return r.recordErr("DeleteService", func() error { return r.next.DeleteService(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) GetServiceLogs(ctx context.Context, query api.LogQuery) ([]api.LogEntry, error) {
+	return record(r, "GetServiceLogs", func() ([]api.LogEntry, error) { return r.next.GetServiceLogs(ctx, query) }, query)
+}
+
+func (r *Recorder) GetProjectLogs(ctx context.Context, projectID string, query api.LogQuery) (*api.ProjectLogs, error) {
+	return record(r, "GetProjectLogs", func() (*api.ProjectLogs, error) { return r.next.GetProjectLogs(ctx, projectID, query) }, projectID, query)
+}
+
+func (r *Recorder) EnableSubdomainAccess(ctx context.Context, serviceID string) (*api.Process, error) {
+	return record(r, "EnableSubdomainAccess", func() (*api.Process, error) { return r.next.EnableSubdomainAccess(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) DisableSubdomainAccess(ctx context.Context, serviceID string) (*api.Process, error) {
+	return record(r, "DisableSubdomainAccess", func() (*api.Process, error) { return r.next.DisableSubdomainAccess(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) ListServiceEnvs(ctx context.Context, serviceID string) ([]api.ServiceEnv, error) {
+	return record(r, "ListServiceEnvs", func() ([]api.ServiceEnv, error) { return r.next.ListServiceEnvs(ctx, serviceID) }, serviceID)
+}
+
+func (r *Recorder) CreateServiceEnv(ctx context.Context, serviceID, key, content string, sensitive bool) (*api.Process, error) {
+	return record(r, "CreateServiceEnv", func() (*api.Process, error) { return r.next.CreateServiceEnv(ctx, serviceID, key, content, sensitive) }, serviceID, key, content, sensitive)
+}
+
+func (r *Recorder) UpdateServiceEnv(ctx context.Context, envID, content string, sensitive bool) (*api.Process, error) {
+	return record(r, "UpdateServiceEnv", func() (*api.Process, error) { return r.next.UpdateServiceEnv(ctx, envID, content, sensitive) }, envID, content, sensitive)
+}
+
+func (r *Recorder) DeleteServiceEnv(ctx context.Context, envID string) (*api.Process, error) {
+	return record(r, "DeleteServiceEnv", func() (*api.Process, error) { return r.next.DeleteServiceEnv(ctx, envID) }, envID)
+}
+
+func (r *Recorder) ListAppVersions(ctx context.Context, serviceID string, limit int) ([]api.AppVersion, error) {
+	return record(r, "ListAppVersions", func() ([]api.AppVersion, error) { return r.next.ListAppVersions(ctx, serviceID, limit) }, serviceID, limit)
+}
+
+func (r *Recorder) GetBuildLogs(ctx context.Context, version api.AppVersion, query api.LogQuery) ([]api.LogEntry, error) {
+	return record(r, "GetBuildLogs", func() ([]api.LogEntry, error) { return r.next.GetBuildLogs(ctx, version, query) }, version.ID, query)
+}
+
+func (r *Recorder) CreateAppVersion(ctx context.Context, serviceID, name string) (*api.AppVersion, error) {
+	return record(r, "CreateAppVersion", func() (*api.AppVersion, error) { return r.next.CreateAppVersion(ctx, serviceID, name) }, serviceID, name)
+}
+
+func (r *Recorder) UploadAppVersion(ctx context.Context, version api.AppVersion, archive io.Reader, size int64) error {
+	return r.recordErr("UploadAppVersion", func() error { return r.next.UploadAppVersion(ctx, version, archive, size) }, version.ID, size)
+}
+
+func (r *Recorder) BuildAndDeploy(ctx context.Context, appVersionID, zeropsYaml, setup string) (*api.Process, error) {
+	return record(r, "BuildAndDeploy", func() (*api.Process, error) {
+		return r.next.BuildAndDeploy(ctx, appVersionID, zeropsYaml, setup)
+	}, appVersionID, setup)
+}
+
+func (r *Recorder) GetProcess(ctx context.Context, processID string) (*api.Process, error) {
+	return record(r, "GetProcess", func() (*api.Process, error) { return r.next.GetProcess(ctx, processID) }, processID)
+}
+
+func (r *Recorder) WaitForProcess(ctx context.Context, processID string, timeout time.Duration) (*api.Process, error) {
+	return record(r, "WaitForProcess", func() (*api.Process, error) {
+		return r.next.WaitForProcess(ctx, processID, timeout)
+	}, processID, timeout)
+}
+
+// Replayer implements ZeropsAPI by answering calls from recorded interactions.
+// Each interaction is consumed once, matched by method name and arguments.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer creates a replayer from recorded interactions
+func NewReplayer(interactions []Interaction) *Replayer {
+	return &Replayer{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}
+}
+
+// LoadReplayer creates a replayer from a JSON fixture file written by Recorder.Save
+func LoadReplayer(path string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return NewReplayer(interactions), nil
+}
+
+// Remaining returns the interactions that have not been replayed yet
+func (r *Replayer) Remaining() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Interaction
+	for i, in := range r.interactions {
+		if !r.used[i] {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// next finds and consumes the first unused interaction matching the call
+func (r *Replayer) next(method string, args []interface{}) (*Interaction, error) {
+	want := marshalArgs(args)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.interactions {
+		in := &r.interactions[i]
+		if r.used[i] || in.Method != method || !argsEqual(in.Args, want) {
+			continue
+		}
+		r.used[i] = true
+		return in, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s%s", method, formatArgs(want))
+}
+
+// replay answers a call from the matching interaction
+func replay[T any](r *Replayer, method string, args ...interface{}) (T, error) {
+	var res T
+	in, err := r.next(method, args)
+	if err != nil {
+		return res, err
+	}
+	if len(in.Response) > 0 {
+		if err := json.Unmarshal(in.Response, &res); err != nil {
+			return res, fmt.Errorf("failed to decode recorded %s response: %w", method, err)
+		}
+	}
+	if in.APIError != nil {
+		apiErr := *in.APIError
+		return res, &apiErr
+	}
+	if in.Error != "" {
+		return res, errors.New(in.Error)
+	}
+	return res, nil
+}
+
+// replayErr answers a call without a result value
+func (r *Replayer) replayErr(method string, args ...interface{}) error {
+	_, err := replay[json.RawMessage](r, method, args...)
+	return err
+}
+
+func (r *Replayer) GetBaseURL(ctx context.Context) string {
+	url, _ := replay[string](r, "GetBaseURL")
+	return url
+}
+
+func (r *Replayer) GetCurrentUser(ctx context.Context) (*api.User, error) {
+	return replay[*api.User](r, "GetCurrentUser")
+}
+
+func (r *Replayer) GetClientID(ctx context.Context) (string, error) {
+	return replay[string](r, "GetClientID")
+}
+
+func (r *Replayer) ListRegions(ctx context.Context) ([]api.Region, error) {
+	return replay[[]api.Region](r, "ListRegions")
+}
+
+func (r *Replayer) CreateProject(ctx context.Context, req api.CreateProjectRequest) (*api.Project, error) {
+	return replay[*api.Project](r, "CreateProject", req)
+}
+
+func (r *Replayer) GetProject(ctx context.Context, projectID string) (*api.Project, error) {
+	return replay[*api.Project](r, "GetProject", projectID)
+}
+
+func (r *Replayer) ListProjects(ctx context.Context, clientID string) ([]api.Project, error) {
+	return replay[[]api.Project](r, "ListProjects", clientID)
+}
+
+func (r *Replayer) SearchProjects(ctx context.Context, req api.SearchRequest) (*api.SearchResult[api.Project], error) {
+	return replay[*api.SearchResult[api.Project]](r, "SearchProjects", req)
+}
+
+func (r *Replayer) DeleteProject(ctx context.Context, projectID string) (*api.Process, error) {
+	return replay[*api.Process](r, "DeleteProject", projectID)
+}
+
+func (r *Replayer) ImportProjectServices(ctx context.Context, projectID, clientID, yamlData string) error {
+	return r.replayErr("ImportProjectServices", projectID, clientID, yamlData)
+}
+
+func (r *Replayer) ImportProject(ctx context.Context, req api.ImportRequest) error {
+	return r.replayErr("ImportProject", req)
+}
+
+func (r *Replayer) CreateProjectEnv(ctx context.Context, projectID, key, content string, sensitive bool) (*api.Process, error) {
+	return replay[*api.Process](r, "CreateProjectEnv", projectID, key, content, sensitive)
+}
+
+func (r *Replayer) ListProjectEnvs(ctx context.Context, projectID string) ([]api.ProjectEnv, error) {
+	return replay[[]api.ProjectEnv](r, "ListProjectEnvs", projectID)
+}
+
+func (r *Replayer) UpdateProjectEnv(ctx context.Context, envID, content string, sensitive bool) (*api.Process, error) {
+	return replay[*api.Process](r, "UpdateProjectEnv", envID, content, sensitive)
+}
+
+func (r *Replayer) DeleteProjectEnv(ctx context.Context, envID string) (*api.Process, error) {
+	return replay[*api.Process](r, "DeleteProjectEnv", envID)
+}
+
+func (r *Replayer) GetProjectServices(ctx context.Context, projectID string) ([]api.Service, error) {
+	return replay[[]api.Service](r, "GetProjectServices", projectID)
+}
+
+func (r *Replayer) ListServices(ctx context.Context, projectID string) ([]api.Service, error) {
+	return replay[[]api.Service](r, "ListServices", projectID)
+}
+
+func (r *Replayer) SearchServices(ctx context.Context, req api.SearchRequest) (*api.SearchResult[api.Service], error) {
+	return replay[*api.SearchResult[api.Service]](r, "SearchServices", req)
+}
+
+func (r *Replayer) GetService(ctx context.Context, serviceID string) (*api.ServiceDetails, error) {
+	return replay[*api.ServiceDetails](r, "GetService", serviceID)
+}
+
+func (r *Replayer) StartService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return replay[*api.Process](r, "StartService", serviceID)
+}
+
+func (r *Replayer) StopService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return replay[*api.Process](r, "StopService", serviceID)
+}
+
+func (r *Replayer) RestartService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return replay[*api.Process](r, "RestartService", serviceID)
+}
+
+func (r *Replayer) ReloadService(ctx context.Context, serviceID string) (*api.Process, error) {
+	return replay[*api.Process](r, "ReloadService", serviceID)
+}
+
+func (r *Replayer) DeleteService(ctx context.Context, serviceID string) error {
+	return r.replayErr("DeleteService", serviceID)
+}
+
+func (r *Replayer) GetServiceLogs(ctx context.Context, query api.LogQuery) ([]api.LogEntry, error) {
+	return replay[[]api.LogEntry](r, "GetServiceLogs", query)
+}
+
+func (r *Replayer) GetProjectLogs(ctx context.Context, projectID string, query api.LogQuery) (*api.ProjectLogs, error) {
+	return replay[*api.ProjectLogs](r, "GetProjectLogs", projectID, query)
+}
+
+func (r *Replayer) EnableSubdomainAccess(ctx context.Context, serviceID string) (*api.Process, error) {
+	return replay[*api.Process](r, "EnableSubdomainAccess", serviceID)
+}
+
+func (r *Replayer) DisableSubdomainAccess(ctx context.Context, serviceID string) (*api.Process, error) {
+	return replay[*api.Process](r, "DisableSubdomainAccess", serviceID)
+}
+
+func (r *Replayer) ListServiceEnvs(ctx context.Context, serviceID string) ([]api.ServiceEnv, error) {
+	return replay[[]api.ServiceEnv](r, "ListServiceEnvs", serviceID)
+}
+
+func (r *Replayer) CreateServiceEnv(ctx context.Context, serviceID, key, content string, sensitive bool) (*api.Process, error) {
+	return replay[*api.Process](r, "CreateServiceEnv", serviceID, key, content, sensitive)
+}
+
+func (r *Replayer) UpdateServiceEnv(ctx context.Context, envID, content string, sensitive bool) (*api.Process, error) {
+	return replay[*api.Process](r, "UpdateServiceEnv", envID, content, sensitive)
+}
+
+func (r *Replayer) DeleteServiceEnv(ctx context.Context, envID string) (*api.Process, error) {
+	return replay[*api.Process](r, "DeleteServiceEnv", envID)
+}
+
+func (r *Replayer) ListAppVersions(ctx context.Context, serviceID string, limit int) ([]api.AppVersion, error) {
+	return replay[[]api.AppVersion](r, "ListAppVersions", serviceID, limit)
+}
+
+func (r *Replayer) GetBuildLogs(ctx context.Context, version api.AppVersion, query api.LogQuery) ([]api.LogEntry, error) {
+	return replay[[]api.LogEntry](r, "GetBuildLogs", version.ID, query)
+}
+
+func (r *Replayer) CreateAppVersion(ctx context.Context, serviceID, name string) (*api.AppVersion, error) {
+	return replay[*api.AppVersion](r, "CreateAppVersion", serviceID, name)
+}
+
+func (r *Replayer) UploadAppVersion(ctx context.Context, version api.AppVersion, archive io.Reader, size int64) error {
+	return r.replayErr("UploadAppVersion", version.ID, size)
+}
+
+func (r *Replayer) BuildAndDeploy(ctx context.Context, appVersionID, zeropsYaml, setup string) (*api.Process, error) {
+	return replay[*api.Process](r, "BuildAndDeploy", appVersionID, setup)
+}
+
+func (r *Replayer) GetProcess(ctx context.Context, processID string) (*api.Process, error) {
+	return replay[*api.Process](r, "GetProcess", processID)
+}
+
+func (r *Replayer) WaitForProcess(ctx context.Context, processID string, timeout time.Duration) (*api.Process, error) {
+	return replay[*api.Process](r, "WaitForProcess", processID, timeout)
+}
+
+// marshalArgs encodes call arguments for storage and comparison
+func marshalArgs(args []interface{}) []json.RawMessage {
+	if len(args) == 0 {
+		return nil
+	}
+	out := make([]json.RawMessage, len(args))
+	for i, arg := range args {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%q", fmt.Sprint(arg)))
+		}
+		out[i] = data
+	}
+	return out
+}
+
+// argsEqual compares encoded arguments
+func argsEqual(a, b []json.RawMessage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(compactJSON(a[i]), compactJSON(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func compactJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+func formatArgs(args []json.RawMessage) string {
+	parts := make([][]byte, len(args))
+	for i, a := range args {
+		parts[i] = a
+	}
+	return fmt.Sprintf("(%s)", bytes.Join(parts, []byte(", ")))
+}
+
+// Ensure Recorder and Replayer implement ZeropsAPI
+var (
+	_ api.ZeropsAPI = (*Recorder)(nil)
+	_ api.ZeropsAPI = (*Replayer)(nil)
+)
@@ -0,0 +1,534 @@
+// Package apitest provides an in-process fake of the Zerops REST API for
+// driving the API client and MCP tools end-to-end without network access.
+package apitest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+)
+
+// Default identifiers used by the simulator
+const (
+	DefaultAPIKey   = "test-api-key"
+	DefaultClientID = "client-1"
+	DefaultUserID   = "user-1"
+	DefaultRegion   = "prg1"
+)
+
+// Process statuses as reported by the Zerops API
+const (
+	ProcessPending  = "PENDING"
+	ProcessRunning  = "RUNNING"
+	ProcessFinished = "FINISHED"
+	ProcessFailed   = "FAILED"
+)
+
+// LogItem is a single log line served by the fake log proxy
+type LogItem struct {
+	ID             string    `json:"id"`
+	Timestamp      time.Time `json:"timestamp"`
+	Hostname       string    `json:"hostname"`
+	Tag            string    `json:"tag"`
+	Content        string    `json:"content"`
+	Message        string    `json:"message"`
+	Priority       int       `json:"priority"`
+	Severity       int       `json:"severity"`
+	ServiceStackID string    `json:"serviceStackId"`
+	ProjectID      string    `json:"projectId"`
+}
+
+// InjectedError describes a failure the simulator returns for a matching request
+type InjectedError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Code       string
+	Message    string
+	Meta       []api.ErrorMeta
+	// Times limits how often the error is returned (0 = always)
+	Times int
+}
+
+// process tracks a process together with its simulated lifecycle
+type process struct {
+	api.Process
+	polls      int
+	fail       bool
+	onFinished func()
+}
+
+// Server is a stateful fake Zerops API backed by httptest
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the bearer token accepted by the server
+	APIKey string
+	// ProcessPolls is the number of status polls before a process finishes
+	ProcessPolls int
+
+	mu          sync.Mutex
+	user        api.User
+	regions     []api.Region
+	projects    map[string]*api.Project
+	services    map[string]*api.ServiceDetails
+	processes   map[string]*process
+	projectEnvs map[string][]api.ProjectEnv
+	serviceEnvs map[string][]api.ServiceEnv
+	logs        map[string][]LogItem
+	appVersions map[string]*api.AppVersion
+	uploads     map[string][]byte
+	logTokens   map[string]string
+	failActions map[string]bool
+	errors      []*InjectedError
+	requests    []string
+	seq         int
+}
+
+// NewServer starts a fake Zerops API with a single user and client
+func NewServer() *Server {
+	s := &Server{
+		APIKey:       DefaultAPIKey,
+		ProcessPolls: 2,
+		user: api.User{
+			ID:       DefaultUserID,
+			Email:    "dev@example.com",
+			FullName: "Test Developer",
+			Status:   "ACTIVE",
+			Created:  time.Now().Add(-24 * time.Hour),
+			ClientUserList: []api.ClientUser{
+				{
+					ID:       "client-user-1",
+					ClientID: DefaultClientID,
+					UserID:   DefaultUserID,
+					Status:   "ACTIVE",
+					RoleCode: "OWNER",
+					Client:   api.ClientAccount{ID: DefaultClientID, AccountName: "Test Org"},
+				},
+			},
+		},
+		regions: []api.Region{
+			{Name: DefaultRegion, IsDefault: true, Address: "api.app-prg1.zerops.io"},
+		},
+		projects:    make(map[string]*api.Project),
+		services:    make(map[string]*api.ServiceDetails),
+		processes:   make(map[string]*process),
+		projectEnvs: make(map[string][]api.ProjectEnv),
+		serviceEnvs: make(map[string][]api.ServiceEnv),
+		logs:        make(map[string][]LogItem),
+		appVersions: make(map[string]*api.AppVersion),
+		uploads:     make(map[string][]byte),
+		logTokens:   make(map[string]string),
+		failActions: make(map[string]bool),
+	}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// SetUser replaces the authenticated user returned by user/info
+func (s *Server) SetUser(user api.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// SetRegions replaces the list of available regions
+func (s *Server) SetRegions(regions []api.Region) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regions = regions
+}
+
+// AddProject seeds a project and returns it
+func (s *Server) AddProject(name string) *api.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createProject(api.CreateProjectRequest{
+		Name:     name,
+		RegionID: DefaultRegion,
+		ClientID: DefaultClientID,
+	})
+}
+
+// AddService seeds a service in a project and returns it
+func (s *Server) AddService(projectID, hostname, serviceType string) *api.ServiceDetails {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createService(projectID, importedService{Hostname: hostname, Type: serviceType})
+}
+
+// SetServiceStatus forces the status of a service
+func (s *Server) SetServiceStatus(serviceID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if svc, ok := s.services[serviceID]; ok {
+		svc.Status = status
+	}
+}
+
+// Service returns a copy of a service's current state
+func (s *Server) Service(serviceID string) (api.ServiceDetails, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	svc, ok := s.services[serviceID]
+	if !ok {
+		return api.ServiceDetails{}, false
+	}
+	return *svc, true
+}
+
+// Project returns a copy of a project's current state
+func (s *Server) Project(projectID string) (api.Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[projectID]
+	if !ok {
+		return api.Project{}, false
+	}
+	return *p, true
+}
+
+// ProjectEnvs returns the project-level environment variables of a project
+func (s *Server) ProjectEnvs(projectID string) []api.ProjectEnv {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.ProjectEnv(nil), s.projectEnvs[projectID]...)
+}
+
+// ServiceEnvs returns the user-defined environment variables of a service
+func (s *Server) ServiceEnvs(serviceID string) []api.ServiceEnv {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.ServiceEnv(nil), s.serviceEnvs[serviceID]...)
+}
+
+// Process returns a copy of a process's current state
+func (s *Server) Process(processID string) (api.Process, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.processes[processID]
+	if !ok {
+		return api.Process{}, false
+	}
+	return p.Process, true
+}
+
+// AddLogs appends log lines for a service
+func (s *Server) AddLogs(serviceID string, items ...LogItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	svc := s.services[serviceID]
+	for _, item := range items {
+		s.seq++
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("log-%06d", s.seq)
+		}
+		if item.Timestamp.IsZero() {
+			item.Timestamp = time.Now()
+		}
+		if item.Content == "" {
+			item.Content = item.Message
+		}
+		if item.Priority == 0 {
+			// Lines without a priority are informational application (local0) logs
+			if item.Severity == 0 {
+				item.Severity = 6
+			}
+			item.Priority = 16*8 + item.Severity
+		}
+		item.ServiceStackID = serviceID
+		if svc != nil {
+			item.ProjectID = svc.ProjectID
+			if item.Hostname == "" {
+				item.Hostname = svc.Name
+			}
+		}
+		s.logs[serviceID] = append(s.logs[serviceID], item)
+	}
+}
+
+// AddAppVersion records a deployment of a service with the given status and
+// build pipeline log lines, and returns it
+func (s *Server) AddAppVersion(serviceID, status string, buildLogs ...LogItem) *api.AppVersion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc := s.services[serviceID]
+	sequence := 1
+	for _, v := range s.appVersions {
+		if v.ServiceStackID == serviceID && v.Sequence >= sequence {
+			sequence = v.Sequence + 1
+		}
+	}
+
+	now := time.Now()
+	start := now.Add(-time.Minute)
+	version := &api.AppVersion{
+		ID:             s.nextID("appversion"),
+		ServiceStackID: serviceID,
+		Sequence:       sequence,
+		Status:         status,
+		Source:         "CLI",
+		Created:        start,
+		LastUpdate:     now,
+		Build: &api.AppVersionBuild{
+			ServiceStackID: s.nextID("build"),
+			PipelineStart:  &start,
+		},
+	}
+	if svc != nil {
+		version.ProjectID = svc.ProjectID
+	}
+	switch {
+	case strings.HasSuffix(status, "FAILED"):
+		version.Build.PipelineFailed = &now
+	case status == "ACTIVE":
+		version.Build.PipelineFinish = &now
+	}
+	s.appVersions[version.ID] = version
+
+	buildID := version.Build.ServiceStackID
+	for i, item := range buildLogs {
+		s.seq++
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("log-%06d", s.seq)
+		}
+		if item.Timestamp.IsZero() {
+			// Spread lines across the pipeline so they keep their order
+			item.Timestamp = start.Add(time.Duration(i) * time.Millisecond)
+		}
+		if item.Content == "" {
+			item.Content = item.Message
+		}
+		if item.Priority == 0 {
+			if item.Severity == 0 {
+				item.Severity = 6
+			}
+			item.Priority = 16*8 + item.Severity
+		}
+		if item.Tag == "" {
+			item.Tag = "zbuilder"
+		}
+		item.ServiceStackID = buildID
+		item.ProjectID = version.ProjectID
+		if item.Hostname == "" && svc != nil {
+			item.Hostname = "build-" + svc.Name
+		}
+		s.logs[buildID] = append(s.logs[buildID], item)
+	}
+
+	copied := *version
+	return &copied
+}
+
+// AppVersion returns a copy of an app version's current state
+func (s *Server) AppVersion(appVersionID string) (api.AppVersion, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.appVersions[appVersionID]
+	if !ok {
+		return api.AppVersion{}, false
+	}
+	return *v, true
+}
+
+// UploadedFiles returns the entry names of the archive uploaded to an app version
+func (s *Server) UploadedFiles(appVersionID string) ([]string, bool) {
+	s.mu.Lock()
+	data, ok := s.uploads[appVersionID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	files, err := archiveFiles(data)
+	if err != nil {
+		return nil, false
+	}
+	return files, true
+}
+
+// FailProcesses makes every future process with the given action end as FAILED
+func (s *Server) FailProcesses(actionName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failActions[actionName] = true
+}
+
+// InjectError makes the server answer matching requests with an error
+func (s *Server) InjectError(e InjectedError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, &e)
+}
+
+// Requests returns the "METHOD path" lines of all requests received so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// nextID generates a unique identifier with the given prefix
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%d", prefix, s.seq)
+}
+
+// newProcess registers a process whose side effect runs when it finishes
+func (s *Server) newProcess(action, projectID, serviceID string, onFinished func()) *process {
+	now := time.Now()
+	p := &process{
+		Process: api.Process{
+			ID:             s.nextID("process"),
+			ActionName:     action,
+			Status:         ProcessPending,
+			ServiceStackID: serviceID,
+			ProjectID:      projectID,
+			ClientID:       DefaultClientID,
+			Created:        now,
+			LastUpdate:     now,
+			CreatedByUser: &api.ProcessUser{
+				ID:       s.user.ID,
+				Email:    s.user.Email,
+				FullName: s.user.FullName,
+				Type:     "USER",
+			},
+		},
+		fail:       s.failActions[action],
+		onFinished: onFinished,
+	}
+	if s.ProcessPolls <= 0 {
+		s.finishProcess(p)
+	}
+	s.processes[p.ID] = p
+	return p
+}
+
+// advanceProcess moves a process one step through PENDING -> RUNNING -> FINISHED
+func (s *Server) advanceProcess(p *process) {
+	if p.Status != ProcessPending && p.Status != ProcessRunning {
+		return
+	}
+	p.polls++
+	now := time.Now()
+	p.LastUpdate = now
+	if p.Started == nil {
+		p.Started = &now
+		p.Status = ProcessRunning
+	}
+	if p.polls >= s.ProcessPolls {
+		s.finishProcess(p)
+	}
+}
+
+// finishProcess completes a process and applies its side effect
+func (s *Server) finishProcess(p *process) {
+	now := time.Now()
+	if p.Started == nil {
+		p.Started = &now
+	}
+	p.Finished = &now
+	p.LastUpdate = now
+	if p.fail {
+		p.Status = ProcessFailed
+		return
+	}
+	p.Status = ProcessFinished
+	if p.onFinished != nil {
+		p.onFinished()
+	}
+}
+
+// createProject stores a new project
+func (s *Server) createProject(req api.CreateProjectRequest) *api.Project {
+	now := time.Now()
+	host := randomHex(2)
+	p := &api.Project{
+		ID:                  s.nextID("project"),
+		ClientID:            req.ClientID,
+		Name:                req.Name,
+		Description:         req.Description,
+		Status:              "ACTIVE",
+		Mode:                "LIGHT",
+		Created:             now,
+		LastUpdate:          now,
+		TagList:             req.TagList,
+		ZeropsSubdomainHost: &host,
+		RegionID:            req.RegionID,
+	}
+	if p.TagList == nil {
+		p.TagList = []string{}
+	}
+	s.projects[p.ID] = p
+	return p
+}
+
+// sortedProjects returns projects of a client sorted by creation time, newest first
+func (s *Server) sortedProjects(clientID string) []api.Project {
+	var out []api.Project
+	for _, p := range s.projects {
+		if clientID == "" || p.ClientID == clientID {
+			out = append(out, *p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Created.Equal(out[j].Created) {
+			return out[i].ID > out[j].ID
+		}
+		return out[i].Created.After(out[j].Created)
+	})
+	return out
+}
+
+// sortedServices returns services of a project sorted by creation time, newest first
+func (s *Server) sortedServices(projectID string) []api.Service {
+	var out []api.Service
+	for _, svc := range s.services {
+		if projectID == "" || svc.ProjectID == projectID {
+			out = append(out, svc.Service)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Created.Equal(out[j].Created) {
+			return out[i].ID > out[j].ID
+		}
+		return out[i].Created.After(out[j].Created)
+	})
+	return out
+}
+
+// matchError returns an injected error for the request, if any
+func (s *Server) matchError(r *http.Request) *InjectedError {
+	for i, e := range s.errors {
+		if e.Method != "" && e.Method != r.Method {
+			continue
+		}
+		if e.Path != "" && e.Path != r.URL.Path {
+			continue
+		}
+		if e.Times > 0 {
+			e.Times--
+			if e.Times == 0 {
+				s.errors = append(s.errors[:i], s.errors[i+1:]...)
+			}
+		}
+		return e
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "0000"
+	}
+	return hex.EncodeToString(b)
+}
@@ -3,14 +3,17 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 	"github.com/zeropsio/zerops-mcp-v3/internal/utils"
 )
 
@@ -21,6 +24,7 @@ type Client struct {
 	httpClient  *http.Client
 	debug       bool
 	retryConfig RetryConfig
+	defaultOrg  string
 }
 
 // ClientOptions contains options for creating a new client
@@ -30,6 +34,7 @@ type ClientOptions struct {
 	Timeout     time.Duration
 	Debug       bool
 	RetryConfig *RetryConfig
+	DefaultOrg  string // client ID or account name used when a call does not name one
 }
 
 // NewClient creates a new Zerops API client
@@ -49,6 +54,7 @@ func NewClient(opts ClientOptions) *Client {
 		apiKey:      opts.APIKey,
 		debug:       opts.Debug,
 		retryConfig: retryConfig,
+		defaultOrg:  opts.DefaultOrg,
 		httpClient: &http.Client{
 			Timeout: timeout,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -71,7 +77,7 @@ func NewClient(opts ClientOptions) *Client {
 }
 
 // GetBaseURL returns the base URL of the API
-func (c *Client) GetBaseURL() string {
+func (c *Client) GetBaseURL(ctx context.Context) string {
 	return c.baseURL
 }
 
@@ -122,18 +128,36 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Code != "" {
-			// Return structured error message
-			return nil, fmt.Errorf("%d: %s - %s", resp.StatusCode, errResp.Error.Code, errResp.Error.Message)
-		}
-		// Fallback to generic error
-		return nil, fmt.Errorf("%d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	return respBody, nil
 }
 
+// newAPIError builds a typed error from an API error response
+func newAPIError(resp *http.Response, body []byte) *zerrors.APIError {
+	apiErr := zerrors.NewAPIError(resp.StatusCode, "", strings.TrimSpace(string(body)))
+	apiErr.RequestID = resp.Header.Get("X-Request-Id")
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Code != "" {
+		apiErr.Code = errResp.Error.Code
+		apiErr.Message = errResp.Error.Message
+		for _, m := range errResp.Error.Meta {
+			apiErr.Meta = append(apiErr.Meta, zerrors.APIErrorMeta{
+				Error:    m.Error,
+				Code:     m.Code,
+				Metadata: m.Metadata,
+			})
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
 // GetCurrentUser gets the current user information
 func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
 	resp, err := c.doRequestWithRetry(ctx, "GET", "/api/rest/public/user/info", nil)
@@ -149,18 +173,19 @@ func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
 	return &user, nil
 }
 
-// GetClientID returns the first available client ID for the current user
+// GetClientID returns the default organization (client) ID for the current user
 func (c *Client) GetClientID(ctx context.Context) (string, error) {
 	user, err := c.GetCurrentUser(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get user info: %w", err)
 	}
 
-	if len(user.ClientUserList) == 0 {
-		return "", fmt.Errorf("no client associations found for user")
+	clientUser, err := user.FindClient(c.defaultOrg)
+	if err != nil {
+		return "", err
 	}
 
-	return user.ClientUserList[0].ClientID, nil
+	return clientUser.ClientID, nil
 }
 
 // ListRegions lists all available regions
@@ -216,26 +241,13 @@ func (c *Client) GetProject(ctx context.Context, projectID string) (*Project, er
 	return &project, nil
 }
 
-// ListProjects lists projects for the current user
+// ListProjects lists all projects of a client, walking every search page
 func (c *Client) ListProjects(ctx context.Context, clientID string) ([]Project, error) {
-	searchReq := SearchRequest{
-		Search: []SearchFilter{
-			{
-				Name:     "clientId",
-				Operator: "eq",
-				Value:    clientID,
-			},
-		},
-		Sort: []SortCriteria{
-			{
-				Name:      "created",
-				Ascending: false,
-			},
-		},
-		Limit:  100,
-		Offset: 0,
-	}
+	return ProjectPager(c, clientID, DefaultPageSize).All(ctx)
+}
 
+// SearchProjects fetches a single page of project search results
+func (c *Client) SearchProjects(ctx context.Context, searchReq SearchRequest) (*SearchResult[Project], error) {
 	resp, err := c.doRequestWithRetry(ctx, "POST", "/api/rest/public/project/search", searchReq)
 	if err != nil {
 		return nil, err
@@ -246,7 +258,7 @@ func (c *Client) ListProjects(ctx context.Context, clientID string) ([]Project,
 		return nil, fmt.Errorf("failed to unmarshal projects response: %w", err)
 	}
 
-	return result.Items, nil
+	return &result, nil
 }
 
 // DeleteProject deletes a project and returns the process
@@ -301,11 +313,12 @@ func (c *Client) ImportProject(ctx context.Context, req ImportRequest) error {
 	// If clientID is empty, get it from the API
 	clientID := req.ClientID
 	if clientID == "" {
-		var err error
-		clientID, err = c.GetClientID(ctx)
+		// Import into the organization that owns the project
+		project, err := c.GetProject(ctx, req.ProjectID)
 		if err != nil {
 			return fmt.Errorf("failed to get client ID: %w", err)
 		}
+		clientID = project.ClientID
 	}
 	
 	return c.ImportProjectServices(ctx, req.ProjectID, clientID, req.YAML)
@@ -348,44 +361,19 @@ func (c *Client) GetProjectServices(ctx context.Context, projectID string) ([]Se
 	return services, nil
 }
 
-// ListServices lists services in a project
+// ListServices lists all services in a project, walking every search page
 func (c *Client) ListServices(ctx context.Context, projectID string) ([]Service, error) {
-	// First, get user info to get the clientId
-	user, err := c.GetCurrentUser(ctx)
+	// Services are searched within the organization that owns the project
+	project, err := c.GetProject(ctx, projectID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
-	}
-
-	if len(user.ClientUserList) == 0 {
-		return nil, fmt.Errorf("no client associations found for user")
+		return nil, err
 	}
 
-	// Use the first client ID
-	clientID := user.ClientUserList[0].ClientID
-
-	searchReq := SearchRequest{
-		Search: []SearchFilter{
-			{
-				Name:     "clientId",
-				Operator: "eq",
-				Value:    clientID,
-			},
-			{
-				Name:     "projectId",
-				Operator: "eq",
-				Value:    projectID,
-			},
-		},
-		Sort: []SortCriteria{
-			{
-				Name:      "created",
-				Ascending: false,
-			},
-		},
-		Limit:  100,
-		Offset: 0,
-	}
+	return ServicePager(c, project.ClientID, projectID, DefaultPageSize).All(ctx)
+}
 
+// SearchServices fetches a single page of service search results
+func (c *Client) SearchServices(ctx context.Context, searchReq SearchRequest) (*SearchResult[Service], error) {
 	resp, err := c.doRequestWithRetry(ctx, "POST", "/api/rest/public/service-stack/search", searchReq)
 	if err != nil {
 		return nil, err
@@ -396,7 +384,7 @@ func (c *Client) ListServices(ctx context.Context, projectID string) ([]Service,
 		return nil, fmt.Errorf("failed to unmarshal services response: %w", err)
 	}
 
-	return result.Items, nil
+	return &result, nil
 }
 
 // GetService gets a specific service with full details
@@ -450,8 +438,14 @@ func (c *Client) DeleteService(ctx context.Context, serviceID string) error {
 	return err
 }
 
-// GetServiceLogs retrieves service logs
-func (c *Client) GetServiceLogs(ctx context.Context, serviceID string, container string, limit int, since string) ([]string, error) {
+// GetServiceLogs retrieves the service log entries selected by the query
+func (c *Client) GetServiceLogs(ctx context.Context, query LogQuery) ([]LogEntry, error) {
+	serviceID := query.ServiceID
+	params, err := query.proxyParams()
+	if err != nil {
+		return nil, err
+	}
+
 	// First, get the service to find the project ID and check status
 	if c.debug {
 		log.Printf("[DEBUG] GetServiceLogs: Getting service info for ID: %s", serviceID)
@@ -471,41 +465,275 @@ func (c *Client) GetServiceLogs(ctx context.Context, serviceID string, container
 	}
 	
 	// Step 1: Get project log access token
-	path := fmt.Sprintf("/api/rest/public/project/%s/log", service.ProjectID)
+	access, err := c.getLogAccess(ctx, service.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Step 2: Fetch logs from the region's proxy using the access token and query filters
+	entries, err := c.fetchLogs(ctx, access, params)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Apply the filters the proxy does not support and trim to the requested limit
+	return query.filter(entries), nil
+}
+
+// GetProjectLogs retrieves the log entries of every non-system service in a project,
+// merged by timestamp. The query's ServiceID and Cursor are ignored. Services that
+// were never deployed have no logs and are skipped; a service whose logs cannot be
+// fetched is skipped with the error as reason, and only when every service fails
+// is an error returned.
+func (c *Client) GetProjectLogs(ctx context.Context, projectID string, query LogQuery) (*ProjectLogs, error) {
+	services, err := c.ListServices(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project services: %w", err)
+	}
+
+	// The log access token is project-scoped, so one token serves every service
+	access, err := c.getLogAccess(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	query.Cursor = ""
+	result := &ProjectLogs{}
+	var merged []LogEntry
+	var firstErr error
+	for _, service := range services {
+		if strings.EqualFold(service.ServiceStackTypeInfo.ServiceStackTypeCategory, "system") {
+			continue
+		}
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, SkippedServiceLog{ServiceID: service.ID, Hostname: service.Name, Reason: reason})
+		}
+		if service.Status == "READY_TO_DEPLOY" || service.Status == "NEW" {
+			skip(fmt.Sprintf("not deployed yet (status: %s)", service.Status))
+			continue
+		}
+
+		serviceQuery := query
+		serviceQuery.ServiceID = service.ID
+		params, err := serviceQuery.proxyParams()
+		if err != nil {
+			return nil, err
+		}
+		entries, err := c.fetchLogs(ctx, access, params)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to fetch logs of service %s: %w", service.Name, err)
+			}
+			skip(err.Error())
+			continue
+		}
+		result.Searched++
+		for i := range entries {
+			if entries[i].ServiceID == "" {
+				entries[i].ServiceID = service.ID
+			}
+		}
+		merged = append(merged, serviceQuery.filter(entries)...)
+	}
+
+	if result.Searched == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	result.Entries = query.filter(merged)
+	return result, nil
+}
+
+// ListAppVersions lists the app versions (deployments) of a service, newest first
+func (c *Client) ListAppVersions(ctx context.Context, serviceID string, limit int) ([]AppVersion, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	searchReq := SearchRequest{
+		Search: []SearchFilter{
+			{Name: "serviceStackId", Operator: "eq", Value: serviceID},
+		},
+		Sort: []SortCriteria{
+			{Name: "sequence", Ascending: false},
+		},
+		Limit: limit,
+	}
+
+	resp, err := c.doRequestWithRetry(ctx, "POST", "/api/rest/public/app-version/search", searchReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var result SearchResult[AppVersion]
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal app versions response: %w", err)
+	}
+
+	return result.Items, nil
+}
+
+// GetBuildLogs retrieves the build pipeline log of an app version. The build runs
+// in its own service stack, so the query's ServiceID is replaced by the build's.
+func (c *Client) GetBuildLogs(ctx context.Context, version AppVersion, query LogQuery) ([]LogEntry, error) {
+	if version.Build == nil || version.Build.ServiceStackID == "" {
+		return nil, fmt.Errorf("app version %s has no build pipeline", version.ID)
+	}
+
+	query.ServiceID = version.Build.ServiceStackID
+	params, err := query.proxyParams()
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := c.getLogAccess(ctx, version.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.fetchLogs(ctx, access, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.filter(entries), nil
+}
+
+// CreateAppVersion creates an app version of a service to upload a deployment archive to
+func (c *Client) CreateAppVersion(ctx context.Context, serviceID, name string) (*AppVersion, error) {
+	req := CreateAppVersionRequest{
+		ServiceStackID: serviceID,
+		Name:           name,
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/api/rest/public/app-version", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var version AppVersion
+	if err := json.Unmarshal(resp, &version); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal app version response: %w", err)
+	}
+	if version.UploadURL == "" {
+		return nil, fmt.Errorf("app version %s has no upload URL", version.ID)
+	}
+
+	return &version, nil
+}
+
+// UploadAppVersion uploads a gzipped tar archive of size bytes to an app version's upload URL.
+// The archive is streamed once, so the upload is not retried.
+func (c *Client) UploadAppVersion(ctx context.Context, version AppVersion, archive io.Reader, size int64) error {
+	uploadURL, err := c.resolveURL(version.UploadURL)
+	if err != nil {
+		return fmt.Errorf("invalid upload URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", uploadURL.String(), archive)
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	// Only send the API key to the API itself, not to a storage host
+	if base, err := url.Parse(c.baseURL); err == nil && base.Host == uploadURL.Host {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
 	if c.debug {
-		log.Printf("[DEBUG] GetServiceLogs: Getting log access token from: %s", path)
+		log.Printf("[DEBUG] PUT %s (%d bytes)", uploadURL.Redacted(), size)
+	}
+
+	// The default client timeout is sized for API calls, not archive uploads
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read upload response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return newAPIError(resp, body)
+	}
+
+	return nil
+}
+
+// BuildAndDeploy starts the build pipeline of an uploaded app version using the given
+// zerops.yml content and setup name, and returns the deploy process
+func (c *Client) BuildAndDeploy(ctx context.Context, appVersionID, zeropsYaml, setup string) (*Process, error) {
+	req := BuildAndDeployRequest{
+		ZeropsYaml:      base64.StdEncoding.EncodeToString([]byte(zeropsYaml)),
+		ZeropsYamlSetup: setup,
+	}
+
+	resp, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/api/rest/public/app-version/%s/build-and-deploy", appVersionID), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var process Process
+	if err := json.Unmarshal(resp, &process); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal process response: %w", err)
+	}
+
+	return &process, nil
+}
+
+// resolveURL resolves a URL returned by the API, which may be relative to the API base URL
+func (c *Client) resolveURL(ref string) (*url.URL, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(u), nil
+}
+
+// logAccess is a project-scoped log proxy access token
+type logAccess struct {
+	AccessToken string `json:"accessToken"`
+	URL         string `json:"url"`
+}
+
+// getLogAccess requests a log proxy access token for a project
+func (c *Client) getLogAccess(ctx context.Context, projectID string) (*logAccess, error) {
+	path := fmt.Sprintf("/api/rest/public/project/%s/log", projectID)
+	if c.debug {
+		log.Printf("[DEBUG] Getting log access token from: %s", path)
 	}
 	
 	resp, err := c.doRequestWithRetry(ctx, "GET", path, nil)
 	if err != nil {
 		if c.debug {
-			log.Printf("[DEBUG] GetServiceLogs: Failed to get log access token: %v", err)
+			log.Printf("[DEBUG] Failed to get log access token: %v", err)
 		}
 		return nil, fmt.Errorf("failed to get log access: %w", err)
 	}
 	
-	// Parse the access token response
-	var tokenResp struct {
-		AccessToken string `json:"accessToken"`
-		URL         string `json:"url"`
-	}
-	if err := json.Unmarshal(resp, &tokenResp); err != nil {
+	var access logAccess
+	if err := json.Unmarshal(resp, &access); err != nil {
 		return nil, fmt.Errorf("failed to parse log access response: %w", err)
 	}
 	
 	if c.debug {
-		log.Printf("[DEBUG] GetServiceLogs: Got access token, proxy URL: %s", tokenResp.URL)
+		log.Printf("[DEBUG] Got log access token, proxy URL: %s", access.URL)
 	}
-	
-	// Step 2: Fetch logs from proxy using the access token
-	// Build proxy URL with query parameters
-	proxyURL := fmt.Sprintf("https://proxy.app-prg1.zerops.io/api/rest/log?accessToken=%s", tokenResp.AccessToken)
-	
-	// Add filters for specific service
-	proxyURL += fmt.Sprintf("&serviceStackId=%s", serviceID)
-	
-	if limit > 0 {
-		proxyURL += fmt.Sprintf("&limit=%d", limit)
+	return &access, nil
+}
+
+// fetchLogs requests log entries from the log proxy
+func (c *Client) fetchLogs(ctx context.Context, access *logAccess, params url.Values) ([]LogEntry, error) {
+	proxyURL, err := logProxyURL(access.URL, access.AccessToken, params)
+	if err != nil {
+		return nil, err
 	}
 	
 	// Create new request to proxy
@@ -527,45 +755,17 @@ func (c *Client) GetServiceLogs(ctx context.Context, serviceID string, container
 	}
 	
 	if proxyResp.StatusCode != 200 {
-		return nil, fmt.Errorf("proxy returned status %d: %s", proxyResp.StatusCode, string(proxyBody))
+		return nil, fmt.Errorf("log proxy request failed: %w", newAPIError(proxyResp, proxyBody))
 	}
 	
 	// Parse the proxy response
 	var proxyLogResp struct {
-		Items []struct {
-			ID        string    `json:"id"`
-			Timestamp time.Time `json:"timestamp"`
-			Hostname  string    `json:"hostname"`
-			Tag       string    `json:"tag"`
-			Content   string    `json:"content"`
-			Message   string    `json:"message"`
-			Priority  int       `json:"priority"`
-			Severity  int       `json:"severity"`
-		} `json:"items"`
+		Items []LogEntry `json:"items"`
 	}
-	
 	if err := json.Unmarshal(proxyBody, &proxyLogResp); err != nil {
 		return nil, fmt.Errorf("failed to parse proxy log response: %w", err)
 	}
-	
-	// Convert to string array
-	var logs []string
-	for _, item := range proxyLogResp.Items {
-		// Format: timestamp [hostname] tag: message
-		logLine := fmt.Sprintf("%s [%s] %s: %s", 
-			item.Timestamp.Format("2006-01-02 15:04:05"),
-			item.Hostname,
-			item.Tag,
-			item.Message)
-		logs = append(logs, logLine)
-	}
-	
-	// If no logs found
-	if len(logs) == 0 {
-		return []string{}, nil
-	}
-	
-	return logs, nil
+	return proxyLogResp.Items, nil
 }
 
 // EnableSubdomainAccess enables subdomain access for a service
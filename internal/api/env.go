@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ListProjectEnvs returns the project-level environment variables of a project
+func (c *Client) ListProjectEnvs(ctx context.Context, projectID string) ([]ProjectEnv, error) {
+	return searchEnvs[ProjectEnv](ctx, c, "/api/rest/public/project-env/search", "projectId", projectID)
+}
+
+// UpdateProjectEnv changes the value of a project environment variable
+func (c *Client) UpdateProjectEnv(ctx context.Context, envID, content string, sensitive bool) (*Process, error) {
+	req := UpdateEnvRequest{Content: content, Sensitive: sensitive}
+	return c.processRequest(ctx, "PUT", fmt.Sprintf("/api/rest/public/project-env/%s", envID), req)
+}
+
+// DeleteProjectEnv deletes a project environment variable
+func (c *Client) DeleteProjectEnv(ctx context.Context, envID string) (*Process, error) {
+	return c.processRequest(ctx, "DELETE", fmt.Sprintf("/api/rest/public/project-env/%s", envID), nil)
+}
+
+// ListServiceEnvs returns the user-defined environment variables of a service
+func (c *Client) ListServiceEnvs(ctx context.Context, serviceID string) ([]ServiceEnv, error) {
+	return searchEnvs[ServiceEnv](ctx, c, "/api/rest/public/user-data/search", "serviceStackId", serviceID)
+}
+
+// CreateServiceEnv creates a service environment variable
+func (c *Client) CreateServiceEnv(ctx context.Context, serviceID, key, content string, sensitive bool) (*Process, error) {
+	req := CreateServiceEnvRequest{
+		ServiceStackID: serviceID,
+		Key:            key,
+		Content:        content,
+		Sensitive:      sensitive,
+	}
+	return c.processRequest(ctx, "POST", "/api/rest/public/user-data", req)
+}
+
+// UpdateServiceEnv changes the value of a service environment variable
+func (c *Client) UpdateServiceEnv(ctx context.Context, envID, content string, sensitive bool) (*Process, error) {
+	req := UpdateEnvRequest{Content: content, Sensitive: sensitive}
+	return c.processRequest(ctx, "PUT", fmt.Sprintf("/api/rest/public/user-data/%s", envID), req)
+}
+
+// DeleteServiceEnv deletes a service environment variable
+func (c *Client) DeleteServiceEnv(ctx context.Context, envID string) (*Process, error) {
+	return c.processRequest(ctx, "DELETE", fmt.Sprintf("/api/rest/public/user-data/%s", envID), nil)
+}
+
+// RestartService restarts the containers of a service so they pick up changed environment variables
+func (c *Client) RestartService(ctx context.Context, serviceID string) (*Process, error) {
+	return c.processRequest(ctx, "PUT", fmt.Sprintf("/api/rest/public/service-stack/%s/restart", serviceID), nil)
+}
+
+// ReloadService reloads a service's environment variables without restarting its containers
+func (c *Client) ReloadService(ctx context.Context, serviceID string) (*Process, error) {
+	return c.processRequest(ctx, "PUT", fmt.Sprintf("/api/rest/public/service-stack/%s/reload", serviceID), nil)
+}
+
+// processRequest sends a request that starts a process and returns the process
+func (c *Client) processRequest(ctx context.Context, method, path string, body interface{}) (*Process, error) {
+	resp, err := c.doRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var process Process
+	if err := json.Unmarshal(resp, &process); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal process response: %w", err)
+	}
+
+	return &process, nil
+}
+
+// searchEnvs walks every page of an environment variable search filtered by its owner
+func searchEnvs[T any](ctx context.Context, c *Client, path, ownerField, ownerID string) ([]T, error) {
+	envs, err := NewPager(DefaultPageSize, func(ctx context.Context, limit, offset int) (*SearchResult[T], error) {
+		req := SearchRequest{
+			Search: []SearchFilter{
+				{Name: ownerField, Operator: "eq", Value: ownerID},
+			},
+			Sort: []SortCriteria{
+				{Name: "key", Ascending: true},
+			},
+			Limit:  limit,
+			Offset: offset,
+		}
+		resp, err := c.doRequestWithRetry(ctx, "POST", path, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result SearchResult[T]
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal environment variables response: %w", err)
+		}
+		return &result, nil
+	}).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if envs == nil {
+		envs = []T{}
+	}
+	return envs, nil
+}
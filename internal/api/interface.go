@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ZeropsAPI is the set of Zerops API operations used by the MCP tools.
+// It is implemented by Client and can be substituted with fakes,
+// caching layers or auditing decorators.
+type ZeropsAPI interface {
+	// GetBaseURL returns the base URL of the API
+	GetBaseURL(ctx context.Context) string
+
+	// User and regions
+	GetCurrentUser(ctx context.Context) (*User, error)
+	GetClientID(ctx context.Context) (string, error)
+	ListRegions(ctx context.Context) ([]Region, error)
+
+	// Projects
+	CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error)
+	GetProject(ctx context.Context, projectID string) (*Project, error)
+	ListProjects(ctx context.Context, clientID string) ([]Project, error)
+	SearchProjects(ctx context.Context, req SearchRequest) (*SearchResult[Project], error)
+	DeleteProject(ctx context.Context, projectID string) (*Process, error)
+	ImportProjectServices(ctx context.Context, projectID, clientID, yamlData string) error
+	ImportProject(ctx context.Context, req ImportRequest) error
+	CreateProjectEnv(ctx context.Context, projectID, key, content string, sensitive bool) (*Process, error)
+	ListProjectEnvs(ctx context.Context, projectID string) ([]ProjectEnv, error)
+	UpdateProjectEnv(ctx context.Context, envID, content string, sensitive bool) (*Process, error)
+	DeleteProjectEnv(ctx context.Context, envID string) (*Process, error)
+
+	// Services
+	GetProjectServices(ctx context.Context, projectID string) ([]Service, error)
+	ListServices(ctx context.Context, projectID string) ([]Service, error)
+	SearchServices(ctx context.Context, req SearchRequest) (*SearchResult[Service], error)
+	GetService(ctx context.Context, serviceID string) (*ServiceDetails, error)
+	StartService(ctx context.Context, serviceID string) (*Process, error)
+	StopService(ctx context.Context, serviceID string) (*Process, error)
+	RestartService(ctx context.Context, serviceID string) (*Process, error)
+	ReloadService(ctx context.Context, serviceID string) (*Process, error)
+	DeleteService(ctx context.Context, serviceID string) error
+	GetServiceLogs(ctx context.Context, query LogQuery) ([]LogEntry, error)
+	GetProjectLogs(ctx context.Context, projectID string, query LogQuery) (*ProjectLogs, error)
+	EnableSubdomainAccess(ctx context.Context, serviceID string) (*Process, error)
+	DisableSubdomainAccess(ctx context.Context, serviceID string) (*Process, error)
+
+	// Service environment variables
+	ListServiceEnvs(ctx context.Context, serviceID string) ([]ServiceEnv, error)
+	CreateServiceEnv(ctx context.Context, serviceID, key, content string, sensitive bool) (*Process, error)
+	UpdateServiceEnv(ctx context.Context, envID, content string, sensitive bool) (*Process, error)
+	DeleteServiceEnv(ctx context.Context, envID string) (*Process, error)
+
+	// Deployments
+	ListAppVersions(ctx context.Context, serviceID string, limit int) ([]AppVersion, error)
+	GetBuildLogs(ctx context.Context, version AppVersion, query LogQuery) ([]LogEntry, error)
+	CreateAppVersion(ctx context.Context, serviceID, name string) (*AppVersion, error)
+	UploadAppVersion(ctx context.Context, version AppVersion, archive io.Reader, size int64) error
+	BuildAndDeploy(ctx context.Context, appVersionID, zeropsYaml, setup string) (*Process, error)
+
+	// Processes
+	GetProcess(ctx context.Context, processID string) (*Process, error)
+	WaitForProcess(ctx context.Context, processID string, timeout time.Duration) (*Process, error)
+}
+
+// Ensure Client implements ZeropsAPI
+var _ ZeropsAPI = (*Client)(nil)
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxLogLimit is the largest number of entries requested from the log proxy
+const MaxLogLimit = 1000
+
+// logCursorPrefix marks cursors built by LogCursor
+const logCursorPrefix = "log:"
+
+// Syslog severities used by the log proxy, most severe first
+var severityNames = []string{
+	"emergency",
+	"alert",
+	"critical",
+	"error",
+	"warning",
+	"notice",
+	"info",
+	"debug",
+}
+
+// LogQuery selects the log entries of a service
+type LogQuery struct {
+	ServiceID   string    `json:"serviceId"`
+	Container   string    `json:"container,omitempty"`   // log tag or container name, e.g. "runtime" or "prepare"
+	Since       time.Time `json:"since,omitempty"`       // only entries at or after this time
+	Until       time.Time `json:"until,omitempty"`       // only entries at or before this time
+	MinSeverity string    `json:"minSeverity,omitempty"` // severity name or number; entries at least this severe
+	Text        string    `json:"text,omitempty"`        // case-insensitive substring of the message
+	Pattern     string    `json:"pattern,omitempty"`     // regular expression the message must match
+	Cursor      string    `json:"cursor,omitempty"`      // only entries after the entry this LogCursor points to
+	Limit       int       `json:"limit,omitempty"`
+	Oldest      bool      `json:"oldest,omitempty"` // keep the oldest Limit entries instead of the newest (page forward)
+}
+
+// LogEntry is a single log line returned by the log proxy
+type LogEntry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	ServiceID string    `json:"serviceStackId,omitempty"`
+	Hostname  string    `json:"hostname"`
+	Tag       string    `json:"tag"`
+	Message   string    `json:"message"`
+	Content   string    `json:"content,omitempty"`
+	Severity  int       `json:"severity"`
+	Priority  int       `json:"priority"`
+	Facility  int       `json:"facility"`
+}
+
+// ProjectLogs are the log entries of a project's services, merged by timestamp
+type ProjectLogs struct {
+	Entries  []LogEntry          `json:"entries"`
+	Skipped  []SkippedServiceLog `json:"skipped,omitempty"` // services whose logs are missing from Entries
+	Searched int                 `json:"searched"`          // services whose logs were fetched
+}
+
+// SkippedServiceLog is a service left out of a project log search, and why
+type SkippedServiceLog struct {
+	ServiceID string `json:"serviceId"`
+	Hostname  string `json:"hostname"`
+	Reason    string `json:"reason"`
+}
+
+// SeverityName returns the syslog name of the entry's severity
+func (e LogEntry) SeverityName() string {
+	return SeverityName(e.Severity)
+}
+
+// String formats the entry as "timestamp SEVERITY [hostname] tag: message"
+func (e LogEntry) String() string {
+	return fmt.Sprintf("%s %s [%s] %s: %s",
+		e.Timestamp.Format("2006-01-02 15:04:05"),
+		strings.ToUpper(e.SeverityName()),
+		e.Hostname,
+		e.Tag,
+		e.Message)
+}
+
+// SeverityName returns the syslog name of a severity level
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(severityNames) {
+		return strconv.Itoa(severity)
+	}
+	return severityNames[severity]
+}
+
+// ParseSeverity converts a severity name ("error", "warn", ...) or number into a syslog level
+func ParseSeverity(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "warn":
+		value = "warning"
+	case "err":
+		value = "error"
+	case "crit":
+		value = "critical"
+	case "information", "informational":
+		value = "info"
+	}
+
+	for level, name := range severityNames {
+		if value == name {
+			return level, nil
+		}
+	}
+	if level, err := strconv.Atoi(value); err == nil && level >= 0 && level < len(severityNames) {
+		return level, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q (use one of: %s)", value, strings.Join(severityNames, ", "))
+}
+
+// SeverityNames returns the supported severity names, most severe first
+func SeverityNames() []string {
+	return append([]string(nil), severityNames...)
+}
+
+// ParseLogTime parses an RFC3339 timestamp or a duration relative to now ("15m" means 15 minutes ago)
+func ParseLogTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			d = -d
+		}
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339 (e.g. 2024-01-02T15:04:05Z) or a duration (e.g. 15m, 2h)", value)
+}
+
+// LogCursor builds an opaque cursor pointing just after entry. It carries the
+// entry's timestamp, so entries can be skipped even when the entry itself is no
+// longer in the proxy window.
+func LogCursor(entry LogEntry) string {
+	var nanos int64
+	if !entry.Timestamp.IsZero() {
+		nanos = entry.Timestamp.UnixNano()
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s%d:%s", logCursorPrefix, nanos, entry.ID)))
+}
+
+// ParseLogCursor returns the timestamp and ID of the entry a cursor from LogCursor
+// points to. The timestamp is zero when the entry had none.
+func ParseLogCursor(cursor string) (time.Time, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("malformed log cursor")
+	}
+	value, ok := strings.CutPrefix(string(data), logCursorPrefix)
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("unknown log cursor format")
+	}
+	nanosText, id, ok := strings.Cut(value, ":")
+	nanos, err := strconv.ParseInt(nanosText, 10, 64)
+	if !ok || err != nil || id == "" {
+		return time.Time{}, "", fmt.Errorf("invalid log cursor")
+	}
+	if nanos == 0 {
+		return time.Time{}, id, nil
+	}
+	return time.Unix(0, nanos).UTC(), id, nil
+}
+
+// hasClientFilters reports whether the query filters entries the proxy cannot filter itself
+func (q LogQuery) hasClientFilters() bool {
+	return !q.Since.IsZero() || !q.Until.IsZero() || q.Text != "" || q.Pattern != "" || q.Cursor != "" || q.Oldest
+}
+
+// proxyParams translates the query into log proxy query parameters
+func (q LogQuery) proxyParams() (url.Values, error) {
+	params := url.Values{}
+	params.Set("serviceStackId", q.ServiceID)
+
+	limit := q.Limit
+	if limit <= 0 || limit > MaxLogLimit || q.hasClientFilters() {
+		// Over-fetch so time, text, pattern and cursor filtering still yields a full page
+		// and the oldest entries of the window are available
+		limit = MaxLogLimit
+	}
+	params.Set("limit", strconv.Itoa(limit))
+
+	if q.Container != "" {
+		params.Set("tags", q.Container)
+	}
+	if q.MinSeverity != "" {
+		level, err := ParseSeverity(q.MinSeverity)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("minimumSeverity", strconv.Itoa(level))
+	}
+	if q.Pattern != "" {
+		if _, err := regexp.Compile(q.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if q.Cursor != "" {
+		if _, _, err := ParseLogCursor(q.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// filter applies the query to entries returned by the proxy and keeps the newest Limit entries
+func (q LogQuery) filter(entries []LogEntry) []LogEntry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	// Resume after the cursor entry. When it is no longer within the fetched window,
+	// drop everything up to its timestamp so old lines are not returned as new.
+	if q.Cursor != "" {
+		after, id, _ := ParseLogCursor(q.Cursor)
+		found := false
+		for i, entry := range entries {
+			if entry.ID == id {
+				entries = entries[i+1:]
+				found = true
+				break
+			}
+		}
+		if !found && !after.IsZero() {
+			start := sort.Search(len(entries), func(i int) bool {
+				return entries[i].Timestamp.After(after)
+			})
+			entries = entries[start:]
+		}
+	}
+
+	minLevel := -1
+	if q.MinSeverity != "" {
+		minLevel, _ = ParseSeverity(q.MinSeverity)
+	}
+	text := strings.ToLower(q.Text)
+	var pattern *regexp.Regexp
+	if q.Pattern != "" {
+		pattern, _ = regexp.Compile(q.Pattern)
+	}
+
+	out := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !q.Since.IsZero() && entry.Timestamp.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && entry.Timestamp.After(q.Until) {
+			continue
+		}
+		if minLevel >= 0 && entry.Severity > minLevel {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(entry.Message), text) {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(entry.Message) {
+			continue
+		}
+		out = append(out, entry)
+	}
+
+	if q.Limit > 0 && len(out) > q.Limit {
+		if q.Oldest {
+			out = out[:q.Limit]
+		} else {
+			out = out[len(out)-q.Limit:]
+		}
+	}
+	return out
+}
+
+// LogLines formats entries as text lines
+func LogLines(entries []LogEntry) []string {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.String()
+	}
+	return lines
+}
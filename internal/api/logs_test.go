@@ -0,0 +1,184 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+// testLogs returns one entry per minute, oldest first, with the given messages and severities
+func testLogs(base time.Time, lines ...struct {
+	msg      string
+	severity int
+}) []LogEntry {
+	entries := make([]LogEntry, len(lines))
+	for i, line := range lines {
+		entries[i] = LogEntry{
+			ID:        string(rune('a' + i)),
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Message:   line.msg,
+			Severity:  line.severity,
+		}
+	}
+	return entries
+}
+
+// ids returns the IDs of entries in order
+func ids(entries []LogEntry) string {
+	out := ""
+	for _, entry := range entries {
+		out += entry.ID
+	}
+	return out
+}
+
+func TestLogQueryFilter(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lines := []struct {
+		msg      string
+		severity int
+	}{
+		{"server started", 6},
+		{"connecting to db", 7},
+		{"WARN slow query", 4},
+		{"Error: connection refused", 3},
+		{"request GET /health 200", 6},
+		{"panic: nil map", 2},
+	}
+
+	tests := []struct {
+		name  string
+		query LogQuery
+		want  string
+	}{
+		{"no filters", LogQuery{}, "abcdef"},
+		{"since", LogQuery{Since: base.Add(3 * time.Minute)}, "def"},
+		{"until", LogQuery{Until: base.Add(time.Minute)}, "ab"},
+		{"since and until", LogQuery{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, "bcd"},
+		{"minimum severity name", LogQuery{MinSeverity: "error"}, "df"},
+		{"minimum severity number", LogQuery{MinSeverity: "4"}, "cdf"},
+		{"text is case-insensitive", LogQuery{Text: "CONNECT"}, "bd"},
+		{"pattern", LogQuery{Pattern: `^(Error|panic):`}, "df"},
+		{"limit keeps the newest", LogQuery{Limit: 2}, "ef"},
+		{"limit with oldest keeps the oldest", LogQuery{Limit: 2, Oldest: true}, "ab"},
+		{"filters apply before the limit", LogQuery{MinSeverity: "warning", Limit: 2}, "df"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.query.filter(testLogs(base, lines...))); got != tt.want {
+				t.Errorf("filter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogQueryFilterSortsByTimestamp(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []LogEntry{
+		{ID: "c", Timestamp: base.Add(2 * time.Second)},
+		{ID: "a", Timestamp: base},
+		{ID: "b", Timestamp: base.Add(time.Second)},
+	}
+
+	if got := ids(LogQuery{}.filter(entries)); got != "abc" {
+		t.Errorf("filter = %q, want entries oldest first", got)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+		ok    bool
+	}{
+		{"error", 3, true},
+		{"ERR", 3, true},
+		{"warn", 4, true},
+		{"info", 6, true},
+		{"7", 7, true},
+		{"8", 0, false},
+		{"loud", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSeverity(tt.value)
+		if (err == nil) != tt.ok || (tt.ok && got != tt.want) {
+			t.Errorf("ParseSeverity(%q) = %d, %v; want %d, ok %v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if got, err := ParseLogTime("15m", now); err != nil || !got.Equal(now.Add(-15*time.Minute)) {
+		t.Errorf("ParseLogTime(15m) = %v, %v", got, err)
+	}
+	if got, err := ParseLogTime("2024-05-01T10:00:00Z", now); err != nil || got.Hour() != 10 {
+		t.Errorf("ParseLogTime(RFC3339) = %v, %v", got, err)
+	}
+	if got, err := ParseLogTime("", now); err != nil || !got.IsZero() {
+		t.Errorf("ParseLogTime(\"\") = %v, %v", got, err)
+	}
+	if _, err := ParseLogTime("yesterday", now); err == nil {
+		t.Error("ParseLogTime(yesterday) should fail")
+	}
+}
+
+func TestLogCursorRoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC)
+
+	gotTime, gotID, err := ParseLogCursor(LogCursor(LogEntry{ID: "log-000042", Timestamp: at}))
+	if err != nil || gotID != "log-000042" || !gotTime.Equal(at) {
+		t.Errorf("ParseLogCursor = %v, %q, %v", gotTime, gotID, err)
+	}
+
+	gotTime, gotID, err = ParseLogCursor(LogCursor(LogEntry{ID: "no-time"}))
+	if err != nil || gotID != "no-time" || !gotTime.IsZero() {
+		t.Errorf("ParseLogCursor without timestamp = %v, %q, %v", gotTime, gotID, err)
+	}
+
+	for _, cursor := range []string{"log-000042", "%%%", "b2Zmc2V0OjEw"} {
+		if _, _, err := ParseLogCursor(cursor); err == nil {
+			t.Errorf("ParseLogCursor(%q) should fail", cursor)
+		}
+	}
+}
+
+func TestLogQueryFilterCursor(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []LogEntry{
+		{ID: "a", Timestamp: base},
+		{ID: "b", Timestamp: base.Add(time.Second)},
+		{ID: "c", Timestamp: base.Add(2 * time.Second)},
+		{ID: "d", Timestamp: base.Add(2 * time.Second)},
+		{ID: "e", Timestamp: base.Add(3 * time.Second)},
+	}
+
+	tests := []struct {
+		name   string
+		cursor LogEntry
+		want   string
+	}{
+		{"cursor entry in the window", entries[1], "cde"},
+		{"cursor entry shares its timestamp", entries[2], "de"},
+		{"cursor entry left the window", LogEntry{ID: "gone", Timestamp: base.Add(2 * time.Second)}, "e"},
+		{"cursor older than the window", LogEntry{ID: "gone", Timestamp: base.Add(-time.Hour)}, "abcde"},
+		{"cursor newer than the window", LogEntry{ID: "gone", Timestamp: base.Add(time.Hour)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := LogQuery{Cursor: LogCursor(tt.cursor)}
+			if got := ids(query.filter(append([]LogEntry(nil), entries...))); got != tt.want {
+				t.Errorf("filter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogQueryProxyParamsRejectsInvalidCursor(t *testing.T) {
+	if _, err := (LogQuery{Cursor: "log-000042"}).proxyParams(); err == nil {
+		t.Error("proxyParams should reject a cursor that was not built by LogCursor")
+	}
+}
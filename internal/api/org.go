@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoClient is returned when the user does not belong to any organization
+	ErrNoClient = errors.New("no client associations found for user")
+
+	// ErrUnknownOrg is returned when an organization cannot be matched
+	ErrUnknownOrg = errors.New("unknown organization")
+)
+
+// FindClient returns the organization matching a client ID or account name.
+// An empty org selects the first organization of the user.
+func (u *User) FindClient(org string) (*ClientUser, error) {
+	if len(u.ClientUserList) == 0 {
+		return nil, ErrNoClient
+	}
+	if org == "" {
+		return &u.ClientUserList[0], nil
+	}
+
+	for i, cu := range u.ClientUserList {
+		if cu.ClientID == org {
+			return &u.ClientUserList[i], nil
+		}
+	}
+	for i, cu := range u.ClientUserList {
+		if strings.EqualFold(cu.Client.AccountName, org) {
+			return &u.ClientUserList[i], nil
+		}
+	}
+
+	available := make([]string, 0, len(u.ClientUserList))
+	for _, cu := range u.ClientUserList {
+		available = append(available, fmt.Sprintf("%s (%s)", cu.Client.AccountName, cu.ClientID))
+	}
+	return nil, fmt.Errorf("%w '%s', available: %s", ErrUnknownOrg, org, strings.Join(available, ", "))
+}
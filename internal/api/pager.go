@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultPageSize is the number of items requested per search page
+const DefaultPageSize = 100
+
+// PageFetcher retrieves a single page of search results
+type PageFetcher[T any] func(ctx context.Context, limit, offset int) (*SearchResult[T], error)
+
+// Pager walks all pages of a search endpoint using TotalHits
+type Pager[T any] struct {
+	fetch    PageFetcher[T]
+	pageSize int
+	offset   int
+	total    int
+	done     bool
+}
+
+// NewPager creates a pager that requests pageSize items at a time
+func NewPager[T any](pageSize int, fetch PageFetcher[T]) *Pager[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Pager[T]{fetch: fetch, pageSize: pageSize, total: -1}
+}
+
+// HasNext reports whether another page may be available
+func (p *Pager[T]) HasNext() bool {
+	return !p.done
+}
+
+// Total returns the total number of items reported by the API, or -1 before the first page
+func (p *Pager[T]) Total() int {
+	return p.total
+}
+
+// Next fetches the next page of items
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if p.done {
+		return nil, nil
+	}
+
+	page, err := p.fetch(ctx, p.pageSize, p.offset)
+	if err != nil {
+		return nil, err
+	}
+
+	p.total = page.TotalHits
+	p.offset += len(page.Items)
+
+	// With TotalHits, paging ends once it is reached; a short page does not end it
+	// because the server may cap the page size below the requested limit. A missing
+	// TotalHits decodes as 0, and then a short page is the end. An empty page always
+	// ends paging, in case TotalHits is stale.
+	switch {
+	case len(page.Items) == 0:
+		p.done = true
+	case p.total > 0:
+		p.done = p.offset >= p.total
+	default:
+		p.done = len(page.Items) < p.pageSize
+	}
+
+	return page.Items, nil
+}
+
+// All fetches every remaining page
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for p.HasNext() {
+		page, err := p.Next(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch page at offset %d: %w", p.offset, err)
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
+
+// ProjectPager pages through the projects of a client
+func ProjectPager(client ZeropsAPI, clientID string, pageSize int) *Pager[Project] {
+	return NewPager(pageSize, func(ctx context.Context, limit, offset int) (*SearchResult[Project], error) {
+		return client.SearchProjects(ctx, SearchRequest{
+			Search: []SearchFilter{
+				{Name: "clientId", Operator: "eq", Value: clientID},
+			},
+			Sort: []SortCriteria{
+				{Name: "created", Ascending: false},
+			},
+			Limit:  limit,
+			Offset: offset,
+		})
+	})
+}
+
+// ServicePager pages through the services of a project
+func ServicePager(client ZeropsAPI, clientID, projectID string, pageSize int) *Pager[Service] {
+	return NewPager(pageSize, func(ctx context.Context, limit, offset int) (*SearchResult[Service], error) {
+		return client.SearchServices(ctx, SearchRequest{
+			Search: []SearchFilter{
+				{Name: "clientId", Operator: "eq", Value: clientID},
+				{Name: "projectId", Operator: "eq", Value: projectID},
+			},
+			Sort: []SortCriteria{
+				{Name: "created", Ascending: false},
+			},
+			Limit:  limit,
+			Offset: offset,
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// pagesOf serves items in pages, reporting totalHits with every page
+func pagesOf(items []int, totalHits int, calls *int) PageFetcher[int] {
+	return func(ctx context.Context, limit, offset int) (*SearchResult[int], error) {
+		*calls++
+		end := offset + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		page := []int{}
+		if offset < len(items) {
+			page = items[offset:end]
+		}
+		return &SearchResult[int]{Items: page, Limit: limit, Offset: offset, TotalHits: totalHits}, nil
+	}
+}
+
+func TestPagerAll(t *testing.T) {
+	items := make([]int, 25)
+	for i := range items {
+		items[i] = i
+	}
+
+	tests := []struct {
+		name      string
+		items     []int
+		totalHits int
+		wantItems int
+		wantCalls int
+	}{
+		{"accurate total", items, 25, 25, 3},
+		{"total is a multiple of the page size", items[:20], 20, 20, 2},
+		{"missing total", items, 0, 25, 3},
+		{"missing total with full last page", items[:20], 0, 20, 3},
+		{"stale total below the item count", items, 10, 10, 1},
+		{"stale total above the item count", items, 30, 25, 4},
+		{"no items", nil, 0, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			got, err := NewPager(10, pagesOf(tt.items, tt.totalHits, &calls)).All(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.wantItems {
+				t.Errorf("got %d items, want %d", len(got), tt.wantItems)
+			}
+			for i, v := range got {
+				if v != i {
+					t.Fatalf("item %d = %d, pages are out of order", i, v)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fetched %d pages, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPagerServerPageCap(t *testing.T) {
+	items := make([]int, 25)
+	for i := range items {
+		items[i] = i
+	}
+
+	// The server returns at most 4 items, whatever limit is requested
+	calls := 0
+	fetch := pagesOf(items, len(items), &calls)
+	capped := func(ctx context.Context, limit, offset int) (*SearchResult[int], error) {
+		return fetch(ctx, min(limit, 4), offset)
+	}
+
+	got, err := NewPager(10, capped).All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(items) {
+		t.Errorf("got %d items, want %d", len(got), len(items))
+	}
+	if calls != 7 {
+		t.Errorf("fetched %d pages, want 7", calls)
+	}
+}
+
+func TestPagerNextAndTotal(t *testing.T) {
+	calls := 0
+	pager := NewPager(2, pagesOf([]int{0, 1, 2}, 3, &calls))
+	if pager.Total() != -1 {
+		t.Errorf("Total before the first page = %d, want -1", pager.Total())
+	}
+
+	first, err := pager.Next(context.Background())
+	if err != nil || len(first) != 2 || !pager.HasNext() {
+		t.Fatalf("first page = %v, %v, HasNext %v", first, err, pager.HasNext())
+	}
+	if pager.Total() != 3 {
+		t.Errorf("Total = %d, want 3", pager.Total())
+	}
+
+	second, err := pager.Next(context.Background())
+	if err != nil || len(second) != 1 || pager.HasNext() {
+		t.Fatalf("second page = %v, %v, HasNext %v", second, err, pager.HasNext())
+	}
+
+	if extra, _ := pager.Next(context.Background()); extra != nil || calls != 2 {
+		t.Errorf("Next after the last page fetched again: %v (%d calls)", extra, calls)
+	}
+}
+
+func TestPagerAllReturnsFetchError(t *testing.T) {
+	failure := errors.New("boom")
+	pager := NewPager(10, func(ctx context.Context, limit, offset int) (*SearchResult[int], error) {
+		return nil, failure
+	})
+
+	if _, err := pager.All(context.Background()); !errors.Is(err, failure) {
+		t.Errorf("All error = %v, want %v", err, failure)
+	}
+}
@@ -0,0 +1,460 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownProfile is returned when a call names a profile that is not configured
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// profileKey is the context key carrying the per-call profile name
+type profileKey struct{}
+
+// WithProfile returns a context that routes API calls to the named profile
+func WithProfile(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, profileKey{}, name)
+}
+
+// ProfileFromContext returns the profile named in the context, if any
+func ProfileFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(profileKey{}).(string)
+	return name
+}
+
+// ProfileSet holds one API client per profile and routes each call to
+// the profile named in the context or, by default, the active profile
+type ProfileSet struct {
+	mu      sync.RWMutex
+	clients map[string]ZeropsAPI
+	active  string
+}
+
+// NewProfileSet creates a profile set with the given active profile
+func NewProfileSet(clients map[string]ZeropsAPI, active string) (*ProfileSet, error) {
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("no profiles configured")
+	}
+	p := &ProfileSet{clients: clients}
+	if err := p.Use(active); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Names returns the configured profile names in sorted order
+func (p *ProfileSet) Names() []string {
+	names := make([]string, 0, len(p.clients))
+	for name := range p.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether the named profile is configured
+func (p *ProfileSet) Has(name string) bool {
+	_, ok := p.clients[name]
+	return ok
+}
+
+// Active returns the name of the profile used when a call does not name one
+func (p *ProfileSet) Active() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.active
+}
+
+// Use switches the active profile
+func (p *ProfileSet) Use(name string) error {
+	if !p.Has(name) {
+		return p.unknown(name)
+	}
+	p.mu.Lock()
+	p.active = name
+	p.mu.Unlock()
+	return nil
+}
+
+// Profile returns the client of the named profile
+func (p *ProfileSet) Profile(name string) (ZeropsAPI, error) {
+	client, ok := p.clients[name]
+	if !ok {
+		return nil, p.unknown(name)
+	}
+	return client, nil
+}
+
+// client returns the client selected by the context
+func (p *ProfileSet) client(ctx context.Context) (ZeropsAPI, error) {
+	name := ProfileFromContext(ctx)
+	if name == "" {
+		name = p.Active()
+	}
+	return p.Profile(name)
+}
+
+// unknown builds the error for a missing profile
+func (p *ProfileSet) unknown(name string) error {
+	return fmt.Errorf("%w '%s', available: %s", ErrUnknownProfile, name, strings.Join(p.Names(), ", "))
+}
+
+// GetBaseURL returns the base URL of the selected profile
+func (p *ProfileSet) GetBaseURL(ctx context.Context) string {
+	client, err := p.client(ctx)
+	if err != nil {
+		return ""
+	}
+	return client.GetBaseURL(ctx)
+}
+
+// GetCurrentUser routes to the selected profile
+func (p *ProfileSet) GetCurrentUser(ctx context.Context) (*User, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetCurrentUser(ctx)
+}
+
+// GetClientID routes to the selected profile
+func (p *ProfileSet) GetClientID(ctx context.Context) (string, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return "", err
+	}
+	return client.GetClientID(ctx)
+}
+
+// ListRegions routes to the selected profile
+func (p *ProfileSet) ListRegions(ctx context.Context) ([]Region, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListRegions(ctx)
+}
+
+// CreateProject routes to the selected profile
+func (p *ProfileSet) CreateProject(ctx context.Context, req CreateProjectRequest) (*Project, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateProject(ctx, req)
+}
+
+// GetProject routes to the selected profile
+func (p *ProfileSet) GetProject(ctx context.Context, projectID string) (*Project, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetProject(ctx, projectID)
+}
+
+// ListProjects routes to the selected profile
+func (p *ProfileSet) ListProjects(ctx context.Context, clientID string) ([]Project, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListProjects(ctx, clientID)
+}
+
+// SearchProjects routes to the selected profile
+func (p *ProfileSet) SearchProjects(ctx context.Context, req SearchRequest) (*SearchResult[Project], error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.SearchProjects(ctx, req)
+}
+
+// DeleteProject routes to the selected profile
+func (p *ProfileSet) DeleteProject(ctx context.Context, projectID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteProject(ctx, projectID)
+}
+
+// ImportProjectServices routes to the selected profile
+func (p *ProfileSet) ImportProjectServices(ctx context.Context, projectID, clientID, yamlData string) error {
+	client, err := p.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.ImportProjectServices(ctx, projectID, clientID, yamlData)
+}
+
+// ImportProject routes to the selected profile
+func (p *ProfileSet) ImportProject(ctx context.Context, req ImportRequest) error {
+	client, err := p.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.ImportProject(ctx, req)
+}
+
+// CreateProjectEnv routes to the selected profile
+func (p *ProfileSet) CreateProjectEnv(ctx context.Context, projectID, key, content string, sensitive bool) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateProjectEnv(ctx, projectID, key, content, sensitive)
+}
+
+// ListProjectEnvs routes to the selected profile
+func (p *ProfileSet) ListProjectEnvs(ctx context.Context, projectID string) ([]ProjectEnv, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListProjectEnvs(ctx, projectID)
+}
+
+// UpdateProjectEnv routes to the selected profile
+func (p *ProfileSet) UpdateProjectEnv(ctx context.Context, envID, content string, sensitive bool) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateProjectEnv(ctx, envID, content, sensitive)
+}
+
+// DeleteProjectEnv routes to the selected profile
+func (p *ProfileSet) DeleteProjectEnv(ctx context.Context, envID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteProjectEnv(ctx, envID)
+}
+
+// GetProjectServices routes to the selected profile
+func (p *ProfileSet) GetProjectServices(ctx context.Context, projectID string) ([]Service, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetProjectServices(ctx, projectID)
+}
+
+// ListServices routes to the selected profile
+func (p *ProfileSet) ListServices(ctx context.Context, projectID string) ([]Service, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListServices(ctx, projectID)
+}
+
+// SearchServices routes to the selected profile
+func (p *ProfileSet) SearchServices(ctx context.Context, req SearchRequest) (*SearchResult[Service], error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.SearchServices(ctx, req)
+}
+
+// GetService routes to the selected profile
+func (p *ProfileSet) GetService(ctx context.Context, serviceID string) (*ServiceDetails, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetService(ctx, serviceID)
+}
+
+// StartService routes to the selected profile
+func (p *ProfileSet) StartService(ctx context.Context, serviceID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.StartService(ctx, serviceID)
+}
+
+// StopService routes to the selected profile
+func (p *ProfileSet) StopService(ctx context.Context, serviceID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.StopService(ctx, serviceID)
+}
+
+// RestartService routes to the selected profile
+func (p *ProfileSet) RestartService(ctx context.Context, serviceID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.RestartService(ctx, serviceID)
+}
+
+// ReloadService routes to the selected profile
+func (p *ProfileSet) ReloadService(ctx context.Context, serviceID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ReloadService(ctx, serviceID)
+}
+
+// DeleteService routes to the selected profile
+func (p *ProfileSet) DeleteService(ctx context.Context, serviceID string) error {
+	client, err := p.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.DeleteService(ctx, serviceID)
+}
+
+// GetServiceLogs routes to the selected profile
+func (p *ProfileSet) GetServiceLogs(ctx context.Context, query LogQuery) ([]LogEntry, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetServiceLogs(ctx, query)
+}
+
+// GetProjectLogs routes to the selected profile
+func (p *ProfileSet) GetProjectLogs(ctx context.Context, projectID string, query LogQuery) (*ProjectLogs, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetProjectLogs(ctx, projectID, query)
+}
+
+// EnableSubdomainAccess routes to the selected profile
+func (p *ProfileSet) EnableSubdomainAccess(ctx context.Context, serviceID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.EnableSubdomainAccess(ctx, serviceID)
+}
+
+// DisableSubdomainAccess routes to the selected profile
+func (p *ProfileSet) DisableSubdomainAccess(ctx context.Context, serviceID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DisableSubdomainAccess(ctx, serviceID)
+}
+
+// ListServiceEnvs routes to the selected profile
+func (p *ProfileSet) ListServiceEnvs(ctx context.Context, serviceID string) ([]ServiceEnv, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListServiceEnvs(ctx, serviceID)
+}
+
+// CreateServiceEnv routes to the selected profile
+func (p *ProfileSet) CreateServiceEnv(ctx context.Context, serviceID, key, content string, sensitive bool) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateServiceEnv(ctx, serviceID, key, content, sensitive)
+}
+
+// UpdateServiceEnv routes to the selected profile
+func (p *ProfileSet) UpdateServiceEnv(ctx context.Context, envID, content string, sensitive bool) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateServiceEnv(ctx, envID, content, sensitive)
+}
+
+// DeleteServiceEnv routes to the selected profile
+func (p *ProfileSet) DeleteServiceEnv(ctx context.Context, envID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteServiceEnv(ctx, envID)
+}
+
+// ListAppVersions routes to the selected profile
+func (p *ProfileSet) ListAppVersions(ctx context.Context, serviceID string, limit int) ([]AppVersion, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListAppVersions(ctx, serviceID, limit)
+}
+
+// GetBuildLogs routes to the selected profile
+func (p *ProfileSet) GetBuildLogs(ctx context.Context, version AppVersion, query LogQuery) ([]LogEntry, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetBuildLogs(ctx, version, query)
+}
+
+// CreateAppVersion routes to the selected profile
+func (p *ProfileSet) CreateAppVersion(ctx context.Context, serviceID, name string) (*AppVersion, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateAppVersion(ctx, serviceID, name)
+}
+
+// UploadAppVersion routes to the selected profile
+func (p *ProfileSet) UploadAppVersion(ctx context.Context, version AppVersion, archive io.Reader, size int64) error {
+	client, err := p.client(ctx)
+	if err != nil {
+		return err
+	}
+	return client.UploadAppVersion(ctx, version, archive, size)
+}
+
+// BuildAndDeploy routes to the selected profile
+func (p *ProfileSet) BuildAndDeploy(ctx context.Context, appVersionID, zeropsYaml, setup string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.BuildAndDeploy(ctx, appVersionID, zeropsYaml, setup)
+}
+
+// GetProcess routes to the selected profile
+func (p *ProfileSet) GetProcess(ctx context.Context, processID string) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetProcess(ctx, processID)
+}
+
+// WaitForProcess routes to the selected profile
+func (p *ProfileSet) WaitForProcess(ctx context.Context, processID string, timeout time.Duration) (*Process, error) {
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.WaitForProcess(ctx, processID, timeout)
+}
+
+// Ensure ProfileSet implements ZeropsAPI
+var _ ZeropsAPI = (*ProfileSet)(nil)
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProfileSetRoutesByContext(t *testing.T) {
+	profiles, err := NewProfileSet(map[string]ZeropsAPI{
+		"prod":    NewClient(ClientOptions{BaseURL: "https://api.prod.example"}),
+		"staging": NewClient(ClientOptions{BaseURL: "https://api.staging.example"}),
+	}, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if got := profiles.GetBaseURL(ctx); got != "https://api.prod.example" {
+		t.Errorf("active profile base URL = %q", got)
+	}
+	if got := profiles.GetBaseURL(WithProfile(ctx, "staging")); got != "https://api.staging.example" {
+		t.Errorf("per-call profile base URL = %q", got)
+	}
+	if got := profiles.GetBaseURL(WithProfile(ctx, "missing")); got != "" {
+		t.Errorf("unknown profile base URL = %q, want empty", got)
+	}
+
+	if err := profiles.Use("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if got := profiles.GetBaseURL(ctx); got != "https://api.staging.example" {
+		t.Errorf("base URL after Use = %q", got)
+	}
+	if err := profiles.Use("missing"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("Use of an unknown profile = %v, want ErrUnknownProfile", err)
+	}
+}
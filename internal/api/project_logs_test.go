@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+// hasLogLine reports whether one of the entries carries message
+func hasLogLine(entries []api.LogEntry, message string) bool {
+	for _, entry := range entries {
+		if entry.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetProjectLogsSkipsUndeployedAndFailingServices(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	api1 := sim.AddService(project.ID, "api", "nodejs@20")
+	web := sim.AddService(project.ID, "web", "nodejs@20")
+	worker := sim.AddService(project.ID, "worker", "go@1")
+	sim.SimulateDeploy(api1.ID, 3000)
+	sim.SimulateDeploy(web.ID, 3000)
+	sim.AddLogs(api1.ID, apitest.LogItem{Message: "api started"})
+	sim.AddLogs(web.ID, apitest.LogItem{Message: "web started"})
+
+	client := api.NewClient(api.ClientOptions{BaseURL: sim.URL, APIKey: sim.APIKey, Timeout: 5 * time.Second})
+	ctx := context.Background()
+
+	logs, err := client.GetProjectLogs(ctx, project.ID, api.LogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs.Searched != 2 || !hasLogLine(logs.Entries, "api started") || !hasLogLine(logs.Entries, "web started") {
+		t.Errorf("searched %d services with entries %v, want both deployed services", logs.Searched, logs.Entries)
+	}
+	if len(logs.Skipped) != 1 || logs.Skipped[0].ServiceID != worker.ID || !strings.Contains(logs.Skipped[0].Reason, "READY_TO_DEPLOY") {
+		t.Errorf("skipped = %+v, want the undeployed worker", logs.Skipped)
+	}
+
+	// The first service's logs fail; the other one is still searched
+	sim.InjectError(apitest.InjectedError{
+		Method: "GET", Path: "/api/rest/log", StatusCode: 500, Code: "injected", Message: "log proxy down", Times: 1,
+	})
+	logs, err = client.GetProjectLogs(ctx, project.ID, api.LogQuery{})
+	if err != nil {
+		t.Fatalf("one failing service must not fail the search: %v", err)
+	}
+	if logs.Searched != 1 || hasLogLine(logs.Entries, "api started") == hasLogLine(logs.Entries, "web started") || len(logs.Skipped) != 2 {
+		t.Errorf("logs = %+v, want one searched service and two skipped", logs)
+	}
+	var failed bool
+	for _, skipped := range logs.Skipped {
+		if strings.Contains(skipped.Reason, "log proxy down") {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Errorf("skipped = %+v, want the fetch error as a reason", logs.Skipped)
+	}
+
+	// Every service failing is an error
+	sim.InjectError(apitest.InjectedError{
+		Method: "GET", Path: "/api/rest/log", StatusCode: 500, Code: "injected", Message: "log proxy down",
+	})
+	if _, err := client.GetProjectLogs(ctx, project.ID, api.LogQuery{}); err == nil {
+		t.Error("expected an error when no service could be searched")
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SubdomainDomain returns the domain under which zerops.app subdomains of the region are served
+func (r Region) SubdomainDomain() string {
+	return fmt.Sprintf("%s.zerops.app", r.Name)
+}
+
+// ProjectRegion resolves the region of a project.
+// Projects that do not report a region are matched against the API endpoint the client talks to.
+func ProjectRegion(ctx context.Context, client ZeropsAPI, project *Project) (*Region, error) {
+	regions, err := client.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("no regions available")
+	}
+
+	if project != nil && project.RegionID != "" {
+		for i := range regions {
+			if regions[i].Name == project.RegionID {
+				return &regions[i], nil
+			}
+		}
+		return nil, fmt.Errorf("unknown region %q for project %s", project.RegionID, project.ID)
+	}
+
+	apiHost := hostOf(client.GetBaseURL(ctx))
+	for i := range regions {
+		if apiHost != "" && hostOf(regions[i].Address) == apiHost {
+			return &regions[i], nil
+		}
+	}
+	for i := range regions {
+		if regions[i].IsDefault {
+			return &regions[i], nil
+		}
+	}
+	return &regions[0], nil
+}
+
+// logProxyURL builds the log proxy request URL from the log access response.
+// The API may return the URL as "METHOD host/path", with or without a scheme.
+func logProxyURL(raw, accessToken string, params url.Values) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if method, rest, ok := strings.Cut(raw, " "); ok && method == strings.ToUpper(method) {
+		raw = strings.TrimSpace(rest)
+	}
+	if raw == "" {
+		return "", fmt.Errorf("log access response did not include a proxy URL")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid log proxy URL %q: %w", raw, err)
+	}
+	q := u.Query()
+	for key, values := range params {
+		q[key] = values
+	}
+	q.Set("accessToken", accessToken)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// hostOf returns the host of an address that may lack a scheme
+func hostOf(address string) string {
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"context"
+	"errors"
 	"log"
 	"math"
 	"math/rand"
 	"strings"
 	"time"
+
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
 // RetryConfig defines retry behavior
@@ -98,6 +101,15 @@ func isRetryableError(err error) bool {
 		return false
 	}
 	
+	// API responses carry their own retry classification
+	if apiErr, ok := zerrors.AsAPIError(err); ok {
+		return apiErr.IsRetryable()
+	}
+	
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	
 	errStr := err.Error()
 	
 	// Network errors
@@ -116,20 +128,6 @@ func isRetryableError(err error) bool {
 		}
 	}
 	
-	// HTTP status codes that are retryable
-	retryableStatuses := []string{
-		"429", // Too Many Requests
-		"502", // Bad Gateway
-		"503", // Service Unavailable
-		"504", // Gateway Timeout
-	}
-	
-	for _, status := range retryableStatuses {
-		if strings.Contains(errStr, status) {
-			return true
-		}
-	}
-	
 	return false
 }
 
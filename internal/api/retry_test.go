@@ -0,0 +1,66 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+// countRequests counts the requests the fake API received for one "METHOD path"
+func countRequests(sim *apitest.Server, request string) int {
+	n := 0
+	for _, r := range sim.Requests() {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCreateProjectRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantErr  bool
+		requests int
+	}{
+		{"internal error is not retried", 500, true, 1},
+		{"unavailable is retried", 503, false, 2},
+		{"rate limit is retried", 429, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := apitest.NewServer()
+			defer sim.Close()
+			sim.InjectError(apitest.InjectedError{
+				Method:     "POST",
+				Path:       "/api/rest/public/project",
+				StatusCode: tt.status,
+				Code:       "injected",
+				Message:    "injected failure",
+				Times:      1,
+			})
+
+			client := api.NewClient(api.ClientOptions{
+				BaseURL:     sim.URL,
+				APIKey:      sim.APIKey,
+				RetryConfig: &api.RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1},
+			})
+			_, err := client.CreateProject(context.Background(), api.CreateProjectRequest{
+				ClientID: apitest.DefaultClientID,
+				Name:     "demo",
+				RegionID: apitest.DefaultRegion,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := countRequests(sim, "POST /api/rest/public/project"); got != tt.requests {
+				t.Errorf("sent %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
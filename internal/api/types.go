@@ -58,6 +58,7 @@ type Project struct {
 	LastUpdate          time.Time `json:"lastUpdate"`
 	TagList             []string  `json:"tagList"`
 	ZeropsSubdomainHost *string   `json:"zeropsSubdomainHost"`
+	RegionID            string    `json:"regionId,omitempty"`
 }
 
 // ProjectEnvironment represents project environment settings
@@ -196,6 +197,42 @@ type ProcessUser struct {
 	Type     string `json:"type"`
 }
 
+// AppVersion represents a single deployment of a service
+type AppVersion struct {
+	ID             string           `json:"id"`
+	ProjectID      string           `json:"projectId"`
+	ServiceStackID string           `json:"serviceStackId"`
+	Sequence       int              `json:"sequence"`
+	Status         string           `json:"status"`
+	Source         string           `json:"source"`
+	Created        time.Time        `json:"created"`
+	LastUpdate     time.Time        `json:"lastUpdate"`
+	Build          *AppVersionBuild `json:"build,omitempty"`
+	UploadURL      string           `json:"uploadUrl,omitempty"`
+}
+
+// AppVersionBuild represents the build pipeline of an app version
+type AppVersionBuild struct {
+	ServiceStackID string     `json:"serviceStackId"`
+	ContainerID    string     `json:"containerId"`
+	PipelineStart  *time.Time `json:"pipelineStart,omitempty"`
+	PipelineFinish *time.Time `json:"pipelineFinish,omitempty"`
+	PipelineFailed *time.Time `json:"pipelineFailed,omitempty"`
+}
+
+// CreateAppVersionRequest represents a request to create an app version for an upload
+type CreateAppVersionRequest struct {
+	ServiceStackID string `json:"serviceStackId"`
+	Name           string `json:"name,omitempty"`
+}
+
+// BuildAndDeployRequest represents a request to build and deploy an uploaded app version.
+// ZeropsYaml is the base64-encoded content of zerops.yml.
+type BuildAndDeployRequest struct {
+	ZeropsYaml      string `json:"zeropsYaml"`
+	ZeropsYamlSetup string `json:"zeropsYamlSetup,omitempty"`
+}
+
 // ProjectEnv represents a project-level environment variable
 type ProjectEnv struct {
 	ID        string    `json:"id"`
@@ -215,6 +252,32 @@ type CreateProjectEnvRequest struct {
 	Sensitive bool   `json:"sensitive"`
 }
 
+// ServiceEnv represents a service-level environment variable (user data)
+type ServiceEnv struct {
+	ID             string    `json:"id"`
+	ClientID       string    `json:"clientId"`
+	ServiceStackID string    `json:"serviceStackId"`
+	Key            string    `json:"key"`
+	Content        string    `json:"content"`
+	Sensitive      bool      `json:"sensitive"`
+	Created        time.Time `json:"created"`
+	LastUpdate     time.Time `json:"lastUpdate"`
+}
+
+// CreateServiceEnvRequest represents a request to create a service environment variable
+type CreateServiceEnvRequest struct {
+	ServiceStackID string `json:"serviceStackId"`
+	Key            string `json:"key"`
+	Content        string `json:"content"`
+	Sensitive      bool   `json:"sensitive"`
+}
+
+// UpdateEnvRequest represents a request to change the value of an environment variable
+type UpdateEnvRequest struct {
+	Content   string `json:"content"`
+	Sensitive bool   `json:"sensitive"`
+}
+
 // ServiceDetails extends Service with additional fields from detail endpoint
 type ServiceDetails struct {
 	Service
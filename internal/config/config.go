@@ -3,30 +3,97 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// Supported MCP transports
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "http"
+)
+
+// Supported tool output formats
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
+// Supported deploy backends
+const (
+	DeployBackendAPI  = "api"
+	DeployBackendZCLI = "zcli"
+)
+
 // Config holds the application configuration
 type Config struct {
-	ZeropsAPIKey  string
-	ZeropsAPIURL  string
-	APITimeout    time.Duration
-	VPNWaitTime   time.Duration
-	Debug         bool
+	ZeropsAPIKey    string
+	ZeropsAPIURL    string
+	APITimeout      time.Duration
+	VPNWaitTime     time.Duration
+	Debug           bool
+	Transport       string
+	ListenAddr      string
+	BaseURL         string
+	ShutdownTimeout time.Duration
+	OutputFormat    string
+	DefaultOrg      string
+	ProfilesPath    string
+	Profile         string
+	Profiles        []Profile
+	LogRulePaths    []string
+	DeployBackend   string
+	VPNHelperSocket string // empty uses the helper's default socket
+	VPNHelperOff    bool   // ZEROPS_MCP_VPN_HELPER was set empty to disable the helper
+	ZCLITimeouts    map[string]time.Duration
+
+	profilesErr     error
+	zcliTimeoutsErr error
 }
 
 // Load loads configuration from environment variables
 func Load() *Config {
 	cfg := &Config{
-		ZeropsAPIKey: os.Getenv("ZEROPS_API_KEY"),
-		ZeropsAPIURL: os.Getenv("ZEROPS_API_URL"),
-		Debug:        os.Getenv("ZEROPS_DEBUG") == "true" || os.Getenv("DEBUG") == "true",
+		ZeropsAPIKey:  os.Getenv("ZEROPS_API_KEY"),
+		ZeropsAPIURL:  os.Getenv("ZEROPS_API_URL"),
+		Debug:         os.Getenv("ZEROPS_DEBUG") == "true" || os.Getenv("DEBUG") == "true",
+		Transport:     os.Getenv("ZEROPS_MCP_TRANSPORT"),
+		ListenAddr:    os.Getenv("ZEROPS_MCP_LISTEN_ADDR"),
+		BaseURL:       os.Getenv("ZEROPS_MCP_BASE_URL"),
+		OutputFormat:  os.Getenv("ZEROPS_MCP_OUTPUT_FORMAT"),
+		DefaultOrg:    os.Getenv("ZEROPS_ORG"),
+		ProfilesPath:  os.Getenv("ZEROPS_MCP_PROFILES"),
+		Profile:       os.Getenv("ZEROPS_PROFILE"),
+		LogRulePaths:  filepath.SplitList(os.Getenv("ZEROPS_MCP_LOG_RULES")),
+		DeployBackend: os.Getenv("ZEROPS_MCP_DEPLOY_BACKEND"),
 	}
 
 	// Set defaults
 	if cfg.ZeropsAPIURL == "" {
 		cfg.ZeropsAPIURL = "https://api.app-prg1.zerops.io"
 	}
+	if cfg.Transport == "" {
+		cfg.Transport = TransportStdio
+	}
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = ":8080"
+	}
+	if cfg.OutputFormat == "" {
+		cfg.OutputFormat = OutputFormatText
+	}
+	if cfg.ProfilesPath == "" {
+		cfg.ProfilesPath = DefaultProfilesPath()
+	}
+	if cfg.DeployBackend == "" {
+		cfg.DeployBackend = DeployBackendAPI
+	}
+	// An explicitly empty value disables the VPN helper
+	if socket, ok := os.LookupEnv("ZEROPS_MCP_VPN_HELPER"); ok {
+		cfg.VPNHelperSocket = socket
+		cfg.VPNHelperOff = socket == ""
+	}
 
 	// Parse timeout from environment or use default
 	timeoutStr := os.Getenv("ZEROPS_API_TIMEOUT")
@@ -50,13 +117,81 @@ func Load() *Config {
 		cfg.VPNWaitTime = 2 * time.Second
 	}
 
+	// Parse shutdown timeout from environment or use default
+	shutdownStr := os.Getenv("ZEROPS_MCP_SHUTDOWN_TIMEOUT")
+	if shutdownStr != "" {
+		if shutdown, err := time.ParseDuration(shutdownStr); err == nil {
+			cfg.ShutdownTimeout = shutdown
+		}
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = 10 * time.Second
+	}
+
+	// Errors are reported by Validate so flags can still be parsed
+	cfg.profilesErr = cfg.loadProfiles()
+	cfg.ZCLITimeouts, cfg.zcliTimeoutsErr = ParseZCLITimeouts(os.Getenv("ZEROPS_MCP_ZCLI_TIMEOUTS"))
+
 	return cfg
 }
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	if c.ZeropsAPIKey == "" {
-		return fmt.Errorf("ZEROPS_API_KEY environment variable not set")
+	if c.profilesErr != nil {
+		return c.profilesErr
+	}
+	if c.zcliTimeoutsErr != nil {
+		return fmt.Errorf("invalid ZEROPS_MCP_ZCLI_TIMEOUTS: %w", c.zcliTimeoutsErr)
+	}
+	if len(c.Profiles) == 0 {
+		if c.ZeropsAPIKey == "" {
+			return fmt.Errorf("ZEROPS_API_KEY environment variable not set and no profiles found in %s", c.ProfilesPath)
+		}
+	} else {
+		active := c.FindProfile(c.Profile)
+		if active == nil {
+			return fmt.Errorf("profile %q not found in %s", c.Profile, c.ProfilesPath)
+		}
+		for _, profile := range c.Profiles {
+			if profile.APIKey == "" {
+				return fmt.Errorf("profile %q has no API key (set api_key or api_key_env)", profile.Name)
+			}
+		}
+	}
+	switch c.Transport {
+	case TransportStdio, TransportSSE, TransportStreamableHTTP:
+	default:
+		return fmt.Errorf("unsupported transport %q (use %s, %s or %s)", c.Transport, TransportStdio, TransportSSE, TransportStreamableHTTP)
+	}
+	if c.Transport != TransportStdio && c.ListenAddr == "" {
+		return fmt.Errorf("listen address is required for %s transport", c.Transport)
+	}
+	if c.OutputFormat != OutputFormatText && c.OutputFormat != OutputFormatJSON {
+		return fmt.Errorf("unsupported output format %q (use %s or %s)", c.OutputFormat, OutputFormatText, OutputFormatJSON)
+	}
+	if c.DeployBackend != DeployBackendAPI && c.DeployBackend != DeployBackendZCLI {
+		return fmt.Errorf("unsupported deploy backend %q (use %s or %s)", c.DeployBackend, DeployBackendAPI, DeployBackendZCLI)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// ParseZCLITimeouts parses per-command zcli timeouts such as "service push=45m,vpn up=3m"
+func ParseZCLITimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		command, duration, ok := strings.Cut(entry, "=")
+		command = strings.Join(strings.Fields(command), " ")
+		if !ok || command == "" {
+			return nil, fmt.Errorf("expected <command>=<duration>, got %q", entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout for %q: %q", command, strings.TrimSpace(duration))
+		}
+		timeouts[command] = d
+	}
+	return timeouts, nil
+}
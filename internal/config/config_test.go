@@ -0,0 +1,50 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseZCLITimeouts(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string]time.Duration
+	}{
+		{"", map[string]time.Duration{}},
+		{"service push=45m", map[string]time.Duration{"service push": 45 * time.Minute}},
+		{" service   push = 45m , vpn up=3m,", map[string]time.Duration{"service push": 45 * time.Minute, "vpn up": 3 * time.Minute}},
+		{"version=10s,version=20s", map[string]time.Duration{"version": 20 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseZCLITimeouts(tt.value)
+		if err != nil {
+			t.Errorf("ParseZCLITimeouts(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("ParseZCLITimeouts(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseZCLITimeoutsRejectsInvalidEntries(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr string
+	}{
+		{"service push", "expected <command>=<duration>"},
+		{"=5m", "expected <command>=<duration>"},
+		{"vpn up=soon", `invalid timeout for "vpn up"`},
+		{"vpn up=0s", `invalid timeout for "vpn up"`},
+		{"vpn up=-1m", `invalid timeout for "vpn up"`},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseZCLITimeouts(tt.value); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseZCLITimeouts(%q) = %v, want an error containing %q", tt.value, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfileName is the profile built from ZEROPS_API_KEY when no profiles file defines one
+const DefaultProfileName = "default"
+
+// Profile is a named set of API credentials
+type Profile struct {
+	Name      string `yaml:"-"`
+	APIKey    string `yaml:"api_key"`
+	APIKeyEnv string `yaml:"api_key_env"`
+	APIURL    string `yaml:"api_url"`
+	Org       string `yaml:"org"`
+}
+
+// ProfilesFile is the on-disk layout of the profiles file
+type ProfilesFile struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultProfilesPath returns the profiles file location (~/.config/zerops-mcp/profiles.yaml on Linux)
+func DefaultProfilesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "zerops-mcp", "profiles.yaml")
+}
+
+// LoadProfiles reads the profiles file. A missing file is not an error.
+func LoadProfiles(path string) (*ProfilesFile, error) {
+	file := &ProfilesFile{}
+	if path == "" {
+		return file, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return file, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles file %s: %w", path, err)
+	}
+
+	for name, profile := range file.Profiles {
+		profile.Name = name
+		if profile.APIKey == "" && profile.APIKeyEnv != "" {
+			profile.APIKey = os.Getenv(profile.APIKeyEnv)
+		}
+		file.Profiles[name] = profile
+	}
+
+	return file, nil
+}
+
+// loadProfiles merges the profiles file with the environment credentials
+func (c *Config) loadProfiles() error {
+	file, err := LoadProfiles(c.ProfilesPath)
+	if err != nil {
+		return err
+	}
+
+	c.Profiles = nil
+	names := make([]string, 0, len(file.Profiles))
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := file.Profiles[name]
+		if profile.APIURL == "" {
+			profile.APIURL = c.ZeropsAPIURL
+		}
+		c.Profiles = append(c.Profiles, profile)
+	}
+
+	// Environment credentials remain available as the "default" profile
+	if c.ZeropsAPIKey != "" && c.FindProfile(DefaultProfileName) == nil {
+		c.Profiles = append(c.Profiles, Profile{
+			Name:   DefaultProfileName,
+			APIKey: c.ZeropsAPIKey,
+			APIURL: c.ZeropsAPIURL,
+			Org:    c.DefaultOrg,
+		})
+	}
+
+	if c.Profile == "" {
+		c.Profile = file.Default
+	}
+	if c.Profile == "" && len(c.Profiles) > 0 {
+		c.Profile = c.Profiles[0].Name
+		if c.FindProfile(DefaultProfileName) != nil {
+			c.Profile = DefaultProfileName
+		}
+	}
+
+	return nil
+}
+
+// FindProfile returns the profile with the given name, or nil
+func (c *Config) FindProfile(name string) *Profile {
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			return &c.Profiles[i]
+		}
+	}
+	return nil
+}
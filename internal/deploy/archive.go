@@ -0,0 +1,156 @@
+// Package deploy packages a working directory and deploys it through the Zerops API
+// without zcli.
+package deploy
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// File is a file or directory included in a deployment archive
+type File struct {
+	Path string // slash-separated, relative to the working directory
+	Info fs.FileInfo
+}
+
+// Files lists what a deployment of dir includes. The .git directory is always left
+// out; .gitignore files at any level and a .deployignore file in dir exclude more,
+// with .deployignore taking precedence so it can re-include ignored paths.
+func Files(dir string) ([]File, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("working directory %s is not a directory", dir)
+	}
+
+	git := &ignoreMatcher{}
+	deploy := &ignoreMatcher{}
+	if err := deploy.addFile(filepath.Join(dir, DeployIgnoreFile), ""); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", DeployIgnoreFile, err)
+	}
+
+	var files []File
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		abs := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := git.addFile(filepath.Join(abs, GitIgnoreFile), rel); err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Join(abs, GitIgnoreFile), err)
+		}
+
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return fmt.Errorf("failed to read directory: %w", err)
+		}
+		for _, entry := range entries {
+			p := relPath(rel, entry.Name())
+			isDir := entry.IsDir()
+			if isDir && entry.Name() == ".git" {
+				continue
+			}
+			if excluded(git, deploy, p, isDir) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			if !isDir && !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+				// Sockets, devices and pipes cannot be deployed
+				continue
+			}
+			files = append(files, File{Path: p, Info: info})
+			if isDir {
+				if err := walk(p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// excluded applies the .deployignore rules on top of the .gitignore rules
+func excluded(git, deploy *ignoreMatcher, p string, isDir bool) bool {
+	for _, rule := range deploy.rules {
+		if (!rule.dirOnly || isDir) && rule.re.MatchString(p) {
+			return deploy.ignored(p, isDir)
+		}
+	}
+	return git.ignored(p, isDir)
+}
+
+// Package writes a gzipped tar archive of the given files from dir to w and
+// returns the number of regular files written
+func Package(dir string, files []File, w io.Writer) (int, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	count := 0
+	for _, file := range files {
+		abs := filepath.Join(dir, filepath.FromSlash(file.Path))
+
+		link := ""
+		if file.Info.Mode()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(abs)
+			if err != nil {
+				return count, fmt.Errorf("failed to read link %s: %w", file.Path, err)
+			}
+			link = target
+		}
+
+		hdr, err := tar.FileInfoHeader(file.Info, link)
+		if err != nil {
+			return count, fmt.Errorf("failed to archive %s: %w", file.Path, err)
+		}
+		hdr.Name = file.Path
+		if file.Info.IsDir() {
+			hdr.Name += "/"
+		}
+		// Ownership of the local machine means nothing in the build container
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return count, fmt.Errorf("failed to archive %s: %w", file.Path, err)
+		}
+
+		if !file.Info.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(tw, abs); err != nil {
+			return count, fmt.Errorf("failed to archive %s: %w", file.Path, err)
+		}
+		count++
+	}
+
+	if err := tw.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return count, nil
+}
+
+// copyFile copies the content of a file into the archive
+func copyFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
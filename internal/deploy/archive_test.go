@@ -0,0 +1,125 @@
+package deploy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeTree creates files with the given contents under dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".git/HEAD":           "ref: refs/heads/main",
+		".gitignore":          "node_modules/\n*.log\n.env\ndist/\n",
+		".deployignore":       "!dist/\ndocs/\n",
+		"package.json":        "{}",
+		"src/index.js":        "console.log('hi')",
+		"src/.gitignore":      "generated.js\n",
+		"src/generated.js":    "",
+		"lib/generated.js":    "",
+		"node_modules/x/a.js": "",
+		"debug.log":           "",
+		".env":                "SECRET=1",
+		"dist/bundle.js":      "",
+		"docs/guide.md":       "",
+		"zerops.yml":          "zerops: []",
+	})
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+
+	want := []string{
+		".deployignore",
+		".gitignore",
+		"dist",
+		"dist/bundle.js",
+		"lib",
+		"lib/generated.js",
+		"package.json",
+		"src",
+		"src/.gitignore",
+		"src/index.js",
+		"zerops.yml",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Files =\n%v\nwant\n%v", paths, want)
+	}
+}
+
+func TestFilesRejectsMissingDirectory(t *testing.T) {
+	if _, err := Files(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Files should fail for a missing directory")
+	}
+}
+
+func TestPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"main.go":      "package main",
+		"static/a.css": "body{}",
+		"ignored.log":  "",
+		".gitignore":   "*.log\n",
+	})
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	count, err := Package(dir, files, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("Package wrote %d files, want 3", count)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	contents := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		contents[header.Name] = string(data)
+	}
+	if contents["main.go"] != "package main" || contents["static/a.css"] != "body{}" {
+		t.Errorf("archive contents = %v", contents)
+	}
+	if _, ok := contents["ignored.log"]; ok {
+		t.Error("archive contains an ignored file")
+	}
+}
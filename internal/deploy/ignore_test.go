@@ -0,0 +1,70 @@
+package deploy
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{"*.log", []string{"app.log", ".log"}, []string{"logs/app.log", "app.log.1"}},
+		{"build?", []string{"build1", "builds"}, []string{"build", "build/1", "build12"}},
+		{"**/cache", []string{"cache", "a/cache", "a/b/cache"}, []string{"cache2", "a/cachex"}},
+		{"dist/**", []string{"dist/a", "dist/a/b"}, []string{"dist", "distx/a"}},
+		{"a/**/z", []string{"a/z", "a/b/z", "a/b/c/z"}, []string{"a/bz", "b/a/z"}},
+		{"file[0-9].txt", []string{"file1.txt"}, []string{"filea.txt", "file10.txt"}},
+		{"file[!0-9].txt", []string{"filea.txt"}, []string{"file1.txt"}},
+		{"[unclosed", []string{"[unclosed"}, []string{"u"}},
+		{`\*.md`, []string{"*.md"}, []string{"README.md"}},
+		{"a+b(c).txt", []string{"a+b(c).txt"}, []string{"aab(c).txt"}},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile("^" + globToRegexp(tt.glob) + "$")
+		for _, p := range tt.match {
+			if !re.MatchString(p) {
+				t.Errorf("%q should match %q (regexp %s)", tt.glob, p, re)
+			}
+		}
+		for _, p := range tt.noMatch {
+			if re.MatchString(p) {
+				t.Errorf("%q should not match %q (regexp %s)", tt.glob, p, re)
+			}
+		}
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := &ignoreMatcher{}
+	m.addPatterns([]byte("# comment\n\n*.log\n!keep.log\n/build\ntmp/\nsrc/*.gen.go\n\\#hash\n"), "")
+	m.addPatterns([]byte("*.txt\n"), "docs")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"logs/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"web/build", true, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"src/a.gen.go", false, true},
+		{"src/pkg/a.gen.go", false, false},
+		{"#hash", false, true},
+		{"docs/readme.txt", false, true},
+		{"readme.txt", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := m.ignored(tt.path, tt.isDir); got != tt.ignored {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.ignored)
+		}
+	}
+}
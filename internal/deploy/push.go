@@ -0,0 +1,233 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// configNames are the file names looked up when no config path is given
+var configNames = []string{"zerops.yml", "zerops.yaml"}
+
+// PushOptions describes a deployment of a working directory
+type PushOptions struct {
+	ProjectID   string
+	ServiceName string // service hostname; also the zerops.yml setup to use
+	WorkDir     string
+	ConfigPath  string // relative to WorkDir; defaults to zerops.yml in WorkDir
+	VersionName string
+}
+
+// PushResult describes a started deployment
+type PushResult struct {
+	Service    api.Service     `json:"service"`
+	Setup      string          `json:"setup"`
+	ConfigPath string          `json:"configPath"`
+	AppVersion *api.AppVersion `json:"appVersion"`
+	Process    *api.Process    `json:"process"`
+	Files      int             `json:"files"`
+	Size       int64           `json:"size"`
+}
+
+// Config is a zerops.yml file read for a deployment
+type Config struct {
+	Path    string
+	Content string
+	Setups  []string
+}
+
+// ReadConfig reads a zerops.yml file and lists its setups. A relative path is
+// resolved against workDir; an empty path looks for zerops.yml or zerops.yaml
+// in workDir.
+func ReadConfig(workDir, configPath string) (*Config, error) {
+	if configPath != "" && !filepath.IsAbs(configPath) {
+		configPath = filepath.Join(workDir, configPath)
+	}
+	if configPath == "" {
+		for _, name := range configNames {
+			candidate := filepath.Join(workDir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				configPath = candidate
+				break
+			}
+		}
+		if configPath == "" {
+			return nil, zerrors.NewDeploymentError(
+				"CONFIG_NOT_FOUND",
+				fmt.Sprintf("No zerops.yml found in '%s'", workDir),
+				"Create zerops.yml in the working directory or pass config_path. Use 'knowledge_search_patterns' to start from a tested configuration",
+			)
+		}
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, zerrors.NewDeploymentError(
+			"CONFIG_NOT_FOUND",
+			fmt.Sprintf("Failed to read configuration file '%s': %v", configPath, err),
+			"Check the config_path parameter",
+		)
+	}
+
+	var doc struct {
+		Zerops []struct {
+			Setup string `yaml:"setup"`
+		} `yaml:"zerops"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, zerrors.NewDeploymentError(
+			"CONFIG_ERROR",
+			fmt.Sprintf("Invalid YAML in %s: %v", configPath, err),
+			"Fix the syntax error; 'knowledge_validate_config' can check the file",
+		).WithNextTool("knowledge_validate_config")
+	}
+
+	cfg := &Config{Path: configPath, Content: string(data)}
+	for _, entry := range doc.Zerops {
+		if entry.Setup != "" {
+			cfg.Setups = append(cfg.Setups, entry.Setup)
+		}
+	}
+	if len(cfg.Setups) == 0 {
+		return nil, zerrors.NewDeploymentError(
+			"CONFIG_ERROR",
+			fmt.Sprintf("%s has no setups", configPath),
+			"zerops.yml must contain a 'zerops' list with a 'setup' entry named after the service hostname",
+		).WithNextTool("knowledge_validate_config")
+	}
+	return cfg, nil
+}
+
+// HasSetup reports whether the config defines a setup
+func (c *Config) HasSetup(name string) bool {
+	for _, setup := range c.Setups {
+		if setup == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Push packages the working directory, uploads it as a new app version of the
+// service and starts its build. The returned process tracks the build and deploy.
+func Push(ctx context.Context, client api.ZeropsAPI, opts PushOptions) (*PushResult, error) {
+	if opts.WorkDir == "" {
+		opts.WorkDir = "."
+	}
+
+	cfg, err := ReadConfig(opts.WorkDir, opts.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	setup := opts.ServiceName
+	if setup == "" {
+		if len(cfg.Setups) > 1 {
+			return nil, zerrors.NewValidationError(
+				"SERVICE_SELECTION_REQUIRED",
+				"Service name must be specified for deployment",
+				fmt.Sprintf("%s defines several setups (%s). Pass service_name with the hostname to deploy", cfg.Path, strings.Join(cfg.Setups, ", ")),
+			)
+		}
+		setup = cfg.Setups[0]
+	}
+	if !cfg.HasSetup(setup) {
+		return nil, zerrors.NewDeploymentError(
+			"SETUP_NOT_FOUND",
+			fmt.Sprintf("Setup '%s' not found in %s", setup, cfg.Path),
+			fmt.Sprintf("Available setups: %s. The setup name must match the service hostname", strings.Join(cfg.Setups, ", ")),
+		)
+	}
+
+	service, err := findService(ctx, client, opts.ProjectID, setup)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := Files(opts.WorkDir)
+	if err != nil {
+		return nil, zerrors.NewDeploymentError(
+			"PACKAGE_FAILED",
+			fmt.Sprintf("Failed to package '%s': %v", opts.WorkDir, err),
+			"Check that working_dir exists and is readable",
+		)
+	}
+
+	archive, err := os.CreateTemp("", "zerops-deploy-*.tar.gz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	count, err := Package(opts.WorkDir, files, archive)
+	if err != nil {
+		return nil, zerrors.NewDeploymentError(
+			"PACKAGE_FAILED",
+			fmt.Sprintf("Failed to package '%s': %v", opts.WorkDir, err),
+			"Check that every file in working_dir is readable, or exclude it in .deployignore",
+		)
+	}
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	version, err := client.CreateAppVersion(ctx, service.ID, opts.VersionName)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.UploadAppVersion(ctx, *version, archive, size); err != nil {
+		return nil, err
+	}
+	process, err := client.BuildAndDeploy(ctx, version.ID, cfg.Content, setup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PushResult{
+		Service:    *service,
+		Setup:      setup,
+		ConfigPath: cfg.Path,
+		AppVersion: version,
+		Process:    process,
+		Files:      count,
+		Size:       size,
+	}, nil
+}
+
+// findService looks up a service of a project by hostname
+func findService(ctx context.Context, client api.ZeropsAPI, projectID, hostname string) (*api.Service, error) {
+	services, err := client.ListServices(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i := range services {
+		if services[i].Name == hostname {
+			return &services[i], nil
+		}
+		names = append(names, services[i].Name)
+	}
+
+	resolution := "Create the service with 'project_import' first"
+	if len(names) > 0 {
+		resolution = fmt.Sprintf("Available services: %s. The setup name in zerops.yml must match a service hostname", strings.Join(names, ", "))
+	}
+	return nil, zerrors.NewDeploymentError(
+		"SERVICE_NOT_FOUND",
+		fmt.Sprintf("Service '%s' not found in project %s", hostname, projectID),
+		resolution,
+	).WithNextTool("service_list")
+}
@@ -0,0 +1,110 @@
+package deploy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+	"github.com/zeropsio/zerops-mcp-v3/internal/deploy"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+const twoSetups = `zerops:
+  - setup: app
+    run:
+      start: node index.js
+  - setup: worker
+    run:
+      start: node worker.js
+`
+
+func TestReadConfigResolvesRelativePathAgainstWorkDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "deploy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "deploy", "zerops.yml"), []byte(twoSetups), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := deploy.ReadConfig(dir, "deploy/zerops.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Path != filepath.Join(dir, "deploy", "zerops.yml") || !slices.Equal(cfg.Setups, []string{"app", "worker"}) {
+		t.Errorf("ReadConfig = %s %v", cfg.Path, cfg.Setups)
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := deploy.ReadConfig(dir, "")
+	if toolErr, ok := err.(*zerrors.ToolError); !ok || toolErr.Code != "CONFIG_NOT_FOUND" {
+		t.Errorf("missing config error = %v, want CONFIG_NOT_FOUND", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "zerops.yml"), []byte("zerops: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = deploy.ReadConfig(dir, "")
+	if toolErr, ok := err.(*zerrors.ToolError); !ok || toolErr.Code != "CONFIG_ERROR" {
+		t.Errorf("invalid config error = %v, want CONFIG_ERROR", err)
+	}
+}
+
+func TestPushWithRelativeConfigPath(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	sim.AddService(project.ID, "app", "nodejs@20")
+	client := api.NewClient(api.ClientOptions{BaseURL: sim.URL, APIKey: sim.APIKey, Timeout: 5 * time.Second})
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"index.js":          "console.log('hi')",
+		"deploy/zerops.yml": twoSetups,
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := deploy.Push(context.Background(), client, deploy.PushOptions{
+		ProjectID:   project.ID,
+		ServiceName: "app",
+		WorkDir:     dir,
+		ConfigPath:  "deploy/zerops.yml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Setup != "app" || result.Files != 2 || result.Process == nil {
+		t.Errorf("Push = setup %q, %d files, process %v", result.Setup, result.Files, result.Process)
+	}
+	files, ok := sim.UploadedFiles(result.AppVersion.ID)
+	if !ok || !slices.Contains(files, "index.js") {
+		t.Errorf("uploaded files = %v", files)
+	}
+}
+
+func TestPushRequiresServiceForSeveralSetups(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zerops.yml"), []byte(twoSetups), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := deploy.Push(context.Background(), apitest.NewReplayer(nil), deploy.PushOptions{ProjectID: "project-1", WorkDir: dir})
+	if toolErr, ok := err.(*zerrors.ToolError); !ok || toolErr.Code != "SERVICE_SELECTION_REQUIRED" {
+		t.Errorf("Push error = %v, want SERVICE_SELECTION_REQUIRED", err)
+	}
+}
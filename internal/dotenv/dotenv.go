@@ -0,0 +1,154 @@
+// Package dotenv parses .env files into ordered key/value pairs.
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// keyPattern matches valid variable names
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Entry is a variable defined in a .env file
+type Entry struct {
+	Key   string
+	Value string
+	Line  int // line the definition starts on
+}
+
+// ReadFile parses the .env file at path
+func ReadFile(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data))
+}
+
+// Parse parses .env content. It accepts blank lines, # comments, an optional
+// 'export ' prefix, unquoted values with trailing comments, single-quoted literal
+// values and double-quoted values with \n, \r, \t, \", \$ and \\ escapes; quoted values
+// may span lines. Values are not expanded, so Zerops references such as
+// ${db_hostname} are kept as written. When a key repeats, the last value wins
+// and keeps the position of the first.
+func Parse(content string) ([]Entry, error) {
+	content = strings.TrimPrefix(content, "\uFEFF")
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var entries []Entry
+	index := make(map[string]int)
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", lineNo, key)
+		}
+
+		var value string
+		switch trimmed := strings.TrimLeft(rest, " \t"); {
+		case strings.HasPrefix(trimmed, `"`) || strings.HasPrefix(trimmed, "'"):
+			quote := trimmed[0]
+			raw, end, trailing, err := quoted(lines, i, trimmed[1:], quote)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if trailing = strings.TrimSpace(trailing); trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after closing quote", end+1, trailing)
+			}
+			value = raw
+			if quote == '"' {
+				value = unescape(raw)
+			}
+			i = end
+		default:
+			value = stripComment(rest)
+		}
+
+		if pos, seen := index[key]; seen {
+			entries[pos].Value = value
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, Entry{Key: key, Value: value, Line: lineNo})
+	}
+	return entries, nil
+}
+
+// quoted reads a quoted value that starts with rest on line start and may continue
+// on the following lines. It returns the raw value, the line the value ends on and
+// what follows the closing quote.
+func quoted(lines []string, start int, rest string, quote byte) (string, int, string, error) {
+	var b strings.Builder
+	text := rest
+	for line := start; ; {
+		for j := 0; j < len(text); j++ {
+			c := text[j]
+			if c == '\\' && quote == '"' && j+1 < len(text) {
+				b.WriteByte(c)
+				b.WriteByte(text[j+1])
+				j++
+				continue
+			}
+			if c == quote {
+				return b.String(), line, text[j+1:], nil
+			}
+			b.WriteByte(c)
+		}
+		line++
+		if line >= len(lines) {
+			return "", line, "", fmt.Errorf("missing closing %c", quote)
+		}
+		b.WriteByte('\n')
+		text = lines[line]
+	}
+}
+
+// unescape resolves the escapes allowed in double-quoted values
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// stripComment removes a trailing comment from an unquoted value. A # only starts
+// a comment after whitespace, so values like abc#123 are kept.
+func stripComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(value)
+}
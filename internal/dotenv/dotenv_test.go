@@ -0,0 +1,62 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := "\uFEFF# database\r\n" +
+		"DB_HOST=${db_hostname}\r\n" +
+		"\n" +
+		"export PORT = 3000 # http port\n" +
+		"HASH=abc#123\n" +
+		"EMPTY=\n" +
+		"SINGLE='literal \\n $HOME'\n" +
+		"DOUBLE=\"tab\\tnew\\nline \\\"q\\\" \\$x \\\\ \\w\"\n" +
+		"MULTI=\"first\n" +
+		"second\" # trailing comment\n" +
+		"PORT=8080\n"
+
+	entries, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Entry{
+		{"DB_HOST", "${db_hostname}", 2},
+		{"PORT", "8080", 4},
+		{"HASH", "abc#123", 5},
+		{"EMPTY", "", 6},
+		{"SINGLE", `literal \n $HOME`, 7},
+		{"DOUBLE", "tab\tnew\nline \"q\" $x \\ \\w", 8},
+		{"MULTI", "first\nsecond", 9},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i] != w {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], w)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"JUST_A_KEY", "line 1: expected KEY=value"},
+		{"OK=1\n1BAD=2", `line 2: invalid variable name "1BAD"`},
+		{"MY-KEY=1", "invalid variable name"},
+		{"OPEN=\"never closed\nstill open", `line 1: missing closing "`},
+		{"QUOTED='value' extra", `line 1: unexpected "extra" after closing quote`},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.content); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", tt.content, err, tt.wantErr)
+		}
+	}
+}
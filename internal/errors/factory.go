@@ -2,6 +2,18 @@ package errors
 
 import "time"
 
+// New creates a tool error in the given category
+func New(category Category, code, message, resolution string) *ToolError {
+	return &ToolError{
+		Category:   category,
+		Code:       code,
+		Message:    message,
+		Resolution: resolution,
+		Timestamp:  time.Now(),
+		Retryable:  category == CategoryTimeout || category == CategoryVPN,
+	}
+}
+
 // NewAuthError creates an authentication error
 func NewAuthError(message, resolution string) *ToolError {
 	return &ToolError{
@@ -91,12 +103,14 @@ func NewInternalError(err error) *ToolError {
 	}
 }
 
-// Helper function to determine if HTTP status code is retryable
+// Helper function to determine if HTTP status code is retryable.
+// A plain 500 is not retried: the request may have been processed, and retrying
+// a create would duplicate the resource.
 func isRetryableStatusCode(code int) bool {
 	switch code {
 	case 429, 502, 503, 504:
 		return true
 	default:
-		return code >= 500
+		return false
 	}
 }
\ No newline at end of file
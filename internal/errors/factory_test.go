@@ -0,0 +1,25 @@
+package errors
+
+import "testing"
+
+func TestNewAPIErrorRetryable(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{400, false},
+		{404, false},
+		{429, true},
+		{500, false},
+		{501, false},
+		{502, true},
+		{503, true},
+		{504, true},
+	}
+
+	for _, tt := range tests {
+		if got := NewAPIError(tt.status, "code", "message").IsRetryable(); got != tt.retryable {
+			t.Errorf("status %d: retryable = %v, want %v", tt.status, got, tt.retryable)
+		}
+	}
+}
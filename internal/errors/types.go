@@ -1,7 +1,10 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -20,14 +23,14 @@ const (
 
 // ToolError represents a structured error with user-friendly information
 type ToolError struct {
-	Category   Category
-	Code       string
-	Message    string
-	Resolution string
-	NextTool   string
-	Metadata   map[string]interface{}
-	Timestamp  time.Time
-	Retryable  bool
+	Category   Category               `json:"category"`
+	Code       string                 `json:"code"`
+	Message    string                 `json:"message"`
+	Resolution string                 `json:"resolution,omitempty"`
+	NextTool   string                 `json:"next_tool,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Retryable  bool                   `json:"retryable"`
 }
 
 // Error implements the error interface
@@ -49,17 +52,72 @@ func (e *ToolError) WithMetadata(key string, value interface{}) *ToolError {
 	return e
 }
 
+// WithNextTool sets the tool the agent should use to recover
+func (e *ToolError) WithNextTool(tool string) *ToolError {
+	e.NextTool = tool
+	return e
+}
+
+// WithRetryable overrides whether the operation may succeed when retried
+func (e *ToolError) WithRetryable(retryable bool) *ToolError {
+	e.Retryable = retryable
+	return e
+}
+
 // APIError represents an error from the Zerops API
 type APIError struct {
 	StatusCode int
 	Code       string
 	Message    string
+	Meta       []APIErrorMeta
 	RequestID  string
 	Retryable  bool
 }
 
+// APIErrorMeta contains per-field details returned with an API error
+type APIErrorMeta struct {
+	Error    string                 `json:"error"`
+	Code     string                 `json:"code"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
 func (e *APIError) Error() string {
-	return fmt.Sprintf("API Error %d: %s - %s (RequestID: %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+	msg := fmt.Sprintf("API Error %d: %s - %s", e.StatusCode, e.Code, e.Message)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (RequestID: %s)", e.RequestID)
+	}
+	return msg
+}
+
+// IsRetryable returns whether the request may succeed when retried
+func (e *APIError) IsRetryable() bool {
+	return e.Retryable
+}
+
+// AsAPIError extracts an APIError from the error chain
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if stderrors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// HasStatus reports whether err is an API error with the given HTTP status
+func HasStatus(err error, statusCode int) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+// HasCode reports whether err is an API error with the given error code
+func HasCode(err error, code string) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Code == code
+}
+
+// IsNotFound reports whether err is an API 404
+func IsNotFound(err error) bool {
+	return HasStatus(err, 404)
 }
 
 // ZCLIError represents an error from zcli execution
@@ -74,19 +132,53 @@ type ZCLIError struct {
 }
 
 func (e *ZCLIError) Error() string {
-	return fmt.Sprintf("zcli %s failed (exit %d): %v", e.Command, e.ExitCode, e.Err)
+	switch {
+	case e.IsTimeout():
+		precision := time.Second
+		if e.Duration < time.Second {
+			precision = time.Millisecond
+		}
+		return fmt.Sprintf("zcli %s timed out after %s", e.Command, e.Duration.Round(precision))
+	case e.IsCancelled():
+		return fmt.Sprintf("zcli %s was cancelled", e.Command)
+	default:
+		return fmt.Sprintf("zcli %s failed (exit %d): %v", e.Command, e.ExitCode, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *ZCLIError) Unwrap() error {
+	return e.Err
+}
+
+// Output returns stdout followed by stderr
+func (e *ZCLIError) Output() string {
+	if e.Stderr == "" {
+		return e.Stdout
+	}
+	if e.Stdout == "" {
+		return e.Stderr
+	}
+	return e.Stdout + "\n" + e.Stderr
 }
 
 // IsTimeout checks if the error is a timeout
 func (e *ZCLIError) IsTimeout() bool {
-	return e.Duration > 0 && e.Err != nil && e.Err.Error() == "context deadline exceeded"
+	return stderrors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// IsCancelled checks if the command was stopped because its caller went away
+func (e *ZCLIError) IsCancelled() bool {
+	return stderrors.Is(e.Err, context.Canceled)
 }
 
 // IsSudoRequired checks if the error requires sudo
 func (e *ZCLIError) IsSudoRequired() bool {
-	return contains(e.Stderr, "permission denied") || contains(e.Stderr, "requires sudo")
+	return contains(e.Stderr, "permission denied") || contains(e.Stderr, "requires sudo") ||
+		contains(e.Stderr, "a password is required") || contains(e.Stderr, "operation not permitted")
 }
 
+// contains reports whether s contains substr, ignoring case
 func contains(s, substr string) bool {
-	return len(s) >= len(substr) && s[:len(substr)] == substr
-}
\ No newline at end of file
+	return strings.Contains(strings.ToLower(s), substr)
+}
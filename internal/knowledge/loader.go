@@ -10,7 +10,7 @@ import (
 	"strings"
 )
 
-//go:embed data/runtimes/*.json data/patterns/*.json data/services/*.json data/*.md data/nginx/*.tmpl
+//go:embed data/runtimes/*.json data/patterns/*.json data/services/*.json data/logrules/*.json data/*.md data/nginx/*.tmpl
 var knowledgeFS embed.FS
 
 func GetRuntime(name string) (*RuntimeKnowledge, error) {
@@ -272,7 +272,30 @@ func GetAllServices() ([]*ServiceSummary, error) {
 		return nil, fmt.Errorf("failed to parse service index: %w", err)
 	}
 
-	services := index.Services
+	categories := make([]string, 0, len(index.Services))
+	for category := range index.Services {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var services []*ServiceSummary
+	for _, category := range categories {
+		types := make([]string, 0, len(index.Services[category]))
+		for serviceType := range index.Services[category] {
+			types = append(types, serviceType)
+		}
+		sort.Strings(types)
+		for _, serviceType := range types {
+			entry := index.Services[category][serviceType]
+			services = append(services, &ServiceSummary{
+				Type:        serviceType,
+				DisplayName: serviceType,
+				Category:    category,
+				Versions:    entry.Versions,
+				Description: entry.Description,
+			})
+		}
+	}
 	
 	// Add common recipe services
 	recipeServices := []*ServiceSummary{
@@ -0,0 +1,295 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Log sources a rule can apply to
+const (
+	LogSourceRuntime = "runtime"
+	LogSourceBuild   = "build"
+)
+
+// CommonLogScope marks rules that apply to every service type
+const CommonLogScope = "common"
+
+// logSeverityRank orders rule severities from most to least severe
+var logSeverityRank = map[string]int{
+	"critical": 0,
+	"error":    1,
+	"warning":  2,
+	"info":     3,
+}
+
+// LogRule describes a known log message and how to resolve it
+type LogRule struct {
+	ID         string   `json:"id" yaml:"id"`
+	Title      string   `json:"title" yaml:"title"`
+	Pattern    string   `json:"pattern" yaml:"pattern"`
+	Severity   string   `json:"severity" yaml:"severity"`
+	Sources    []string `json:"sources,omitempty" yaml:"sources"`
+	Resolution string   `json:"resolution" yaml:"resolution"`
+	Docs       string   `json:"docs,omitempty" yaml:"docs"`
+	Scope      string   `json:"scope" yaml:"-"`
+	Origin     string   `json:"origin" yaml:"-"`
+
+	re *regexp.Regexp
+}
+
+// LogRuleFile is the layout of a rule file; all rules in a file share its scope
+type LogRuleFile struct {
+	Scope string    `json:"scope" yaml:"scope"`
+	Rules []LogRule `json:"rules" yaml:"rules"`
+}
+
+// LogLine is a single log line to analyze
+type LogLine struct {
+	Time time.Time
+	Text string
+}
+
+// LogRuleMatch reports how often a rule matched and when
+type LogRuleMatch struct {
+	Rule    *LogRule  `json:"rule"`
+	Count   int       `json:"count"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+	Example string    `json:"example"`
+}
+
+// LogRuleSet is a validated collection of log rules
+type LogRuleSet struct {
+	rules []*LogRule
+}
+
+// DefaultLogRules returns the rules embedded in the knowledge base
+func DefaultLogRules() (*LogRuleSet, error) {
+	return LoadLogRules()
+}
+
+// LoadLogRules returns the embedded rules extended by rule files at the given paths.
+// A path may be a .json, .yaml or .yml file or a directory of them. A custom rule
+// with the ID of an existing rule replaces it.
+func LoadLogRules(paths ...string) (*LogRuleSet, error) {
+	set := &LogRuleSet{}
+
+	entries, err := knowledgeFS.ReadDir("data/logrules")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded log rules: %w", err)
+	}
+	for _, entry := range entries {
+		name := path.Join("data/logrules", entry.Name())
+		data, err := knowledgeFS.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := set.add(name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range paths {
+		files, err := ruleFiles(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read log rules: %w", err)
+			}
+			if err := set.add(file, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return set, nil
+}
+
+// ruleFiles expands a rule path into the rule files it names
+func ruleFiles(p string) ([]string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log rules: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log rules: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".json", ".yaml", ".yml":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(p, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// add parses a rule file and adds its rules to the set
+func (s *LogRuleSet) add(origin string, data []byte) error {
+	var file LogRuleFile
+	var err error
+	if strings.HasSuffix(origin, ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse log rules %s: %w", origin, err)
+	}
+
+	scope := strings.ToLower(strings.TrimSpace(file.Scope))
+	if scope == "" {
+		return fmt.Errorf("log rules %s: missing scope (use a service type such as 'nodejs' or '%s')", origin, CommonLogScope)
+	}
+
+	for i := range file.Rules {
+		rule := file.Rules[i]
+		rule.Scope = scope
+		rule.Origin = origin
+		rule.Severity = strings.ToLower(rule.Severity)
+		if rule.Severity == "" {
+			rule.Severity = "error"
+		}
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("log rules %s: rule %d: %w", origin, i+1, err)
+		}
+		s.put(&rule)
+	}
+	return nil
+}
+
+// put adds a rule, replacing a rule with the same ID
+func (s *LogRuleSet) put(rule *LogRule) {
+	for i, existing := range s.rules {
+		if existing.ID == rule.ID {
+			s.rules[i] = rule
+			return
+		}
+	}
+	s.rules = append(s.rules, rule)
+}
+
+// compile validates the rule and compiles its pattern
+func (r *LogRule) compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("missing id")
+	}
+	if r.Title == "" {
+		return fmt.Errorf("%s: missing title", r.ID)
+	}
+	if _, ok := logSeverityRank[r.Severity]; !ok {
+		return fmt.Errorf("%s: unknown severity %q (use critical, error, warning or info)", r.ID, r.Severity)
+	}
+	for _, source := range r.Sources {
+		if source != LogSourceRuntime && source != LogSourceBuild {
+			return fmt.Errorf("%s: unknown source %q (use %s or %s)", r.ID, source, LogSourceRuntime, LogSourceBuild)
+		}
+	}
+	if r.Pattern == "" {
+		return fmt.Errorf("%s: missing pattern", r.ID)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("%s: invalid pattern: %w", r.ID, err)
+	}
+	r.re = re
+	return nil
+}
+
+// AppliesTo reports whether the rule applies to logs of a service type and source.
+// An empty service type or source matches every rule.
+func (r *LogRule) AppliesTo(serviceType, source string) bool {
+	if scope := LogScope(serviceType); scope != "" && r.Scope != CommonLogScope && r.Scope != scope {
+		return false
+	}
+	if source == "" || len(r.Sources) == 0 {
+		return true
+	}
+	for _, s := range r.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules returns the rules that apply to a service type and source
+func (s *LogRuleSet) Rules(serviceType, source string) []*LogRule {
+	var rules []*LogRule
+	for _, rule := range s.rules {
+		if rule.AppliesTo(serviceType, source) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Match applies the rules for a service type and source to log lines and returns
+// the matched rules, most severe and most frequent first
+func (s *LogRuleSet) Match(lines []LogLine, serviceType, source string) []LogRuleMatch {
+	rules := s.Rules(serviceType, source)
+	byRule := make(map[*LogRule]*LogRuleMatch)
+	var matches []*LogRuleMatch
+
+	for _, line := range lines {
+		for _, rule := range rules {
+			if !rule.re.MatchString(line.Text) {
+				continue
+			}
+			m, ok := byRule[rule]
+			if !ok {
+				m = &LogRuleMatch{Rule: rule, First: line.Time, Last: line.Time, Example: line.Text}
+				byRule[rule] = m
+				matches = append(matches, m)
+			}
+			m.Count++
+			if line.Time.Before(m.First) {
+				m.First = line.Time
+			}
+			if line.Time.After(m.Last) {
+				m.Last = line.Time
+			}
+		}
+	}
+
+	result := make([]LogRuleMatch, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, *m)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		ri, rj := logSeverityRank[result[i].Rule.Severity], logSeverityRank[result[j].Rule.Severity]
+		if ri != rj {
+			return ri < rj
+		}
+		return result[i].Count > result[j].Count
+	})
+	return result
+}
+
+// LogScope returns the rule scope for a service type (e.g. "nodejs" for "nodejs@20")
+func LogScope(serviceType string) string {
+	base := strings.ToLower(strings.Split(serviceType, "@")[0])
+	if strings.HasPrefix(base, "php-") {
+		base = "php"
+	}
+	return base
+}
@@ -0,0 +1,186 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRuleFile writes a rule file into dir and returns its path
+func writeRuleFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// findRule returns the rule with the given ID, or nil
+func findRule(set *LogRuleSet, id string) *LogRule {
+	for _, rule := range set.Rules("", "") {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+func TestLoadLogRulesEmbedded(t *testing.T) {
+	set, err := DefaultLogRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := set.Rules("", "")
+	if len(rules) == 0 {
+		t.Fatal("no embedded rules")
+	}
+	ids := make(map[string]bool)
+	for _, rule := range rules {
+		if ids[rule.ID] {
+			t.Errorf("duplicate rule ID %s", rule.ID)
+		}
+		ids[rule.ID] = true
+		if rule.re == nil || rule.Scope == "" || !strings.HasPrefix(rule.Origin, "data/logrules/") {
+			t.Errorf("rule %s is not loaded completely: %+v", rule.ID, rule)
+		}
+	}
+}
+
+func TestLoadLogRulesMergesCustomRules(t *testing.T) {
+	dir := t.TempDir()
+	file := writeRuleFile(t, t.TempDir(), "team.json", `{
+  "scope": "nodejs",
+  "rules": [{"id": "nodejs-module-not-found", "title": "Team: run npm ci", "pattern": "Cannot find module", "resolution": "Run npm ci"}]
+}`)
+	writeRuleFile(t, dir, "queue.yaml", `scope: Common
+rules:
+  - id: queue-stalled
+    title: Job queue stalled
+    pattern: (?i)queue stalled
+    severity: Warning
+    sources: [runtime]
+    resolution: Restart the workers
+`)
+	writeRuleFile(t, dir, "notes.txt", "not a rule file")
+
+	// ZEROPS_MCP_LOG_RULES is a path list of rule files and directories
+	t.Setenv("ZEROPS_MCP_LOG_RULES", file+string(os.PathListSeparator)+dir)
+	set, err := LoadLogRules(filepath.SplitList(os.Getenv("ZEROPS_MCP_LOG_RULES"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded, err := DefaultLogRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(set.Rules("", "")), len(embedded.Rules("", ""))+1; got != want {
+		t.Errorf("got %d rules, want %d: a custom rule replaces the one with its ID", got, want)
+	}
+
+	replaced := findRule(set, "nodejs-module-not-found")
+	if replaced == nil || replaced.Title != "Team: run npm ci" || replaced.Origin != file || replaced.Severity != "error" {
+		t.Errorf("replaced rule = %+v, want the custom rule with the default severity", replaced)
+	}
+	added := findRule(set, "queue-stalled")
+	if added == nil || added.Scope != CommonLogScope || added.Severity != "warning" {
+		t.Errorf("added rule = %+v, want a common warning rule", added)
+	}
+}
+
+func TestLoadLogRulesRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing scope", `{"rules": [{"id": "a", "title": "A", "pattern": "a"}]}`, "missing scope"},
+		{"missing id", `{"scope": "go", "rules": [{"title": "A", "pattern": "a"}]}`, "missing id"},
+		{"missing pattern", `{"scope": "go", "rules": [{"id": "a", "title": "A"}]}`, "missing pattern"},
+		{"unknown severity", `{"scope": "go", "rules": [{"id": "a", "title": "A", "pattern": "a", "severity": "fatal"}]}`, "unknown severity"},
+		{"unknown source", `{"scope": "go", "rules": [{"id": "a", "title": "A", "pattern": "a", "sources": ["deploy"]}]}`, "unknown source"},
+		{"invalid pattern", `{"scope": "go", "rules": [{"id": "a", "title": "A", "pattern": "("}]}`, "invalid pattern"},
+		{"invalid json", `{"scope": `, "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeRuleFile(t, t.TempDir(), "rules.json", tt.content)
+			_, err := LoadLogRules(file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadLogRules(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing rule file")
+	}
+}
+
+func TestLogRuleAppliesTo(t *testing.T) {
+	nodeRuntime := &LogRule{Scope: "nodejs", Sources: []string{LogSourceRuntime}}
+	common := &LogRule{Scope: CommonLogScope}
+	php := &LogRule{Scope: "php"}
+
+	tests := []struct {
+		name        string
+		rule        *LogRule
+		serviceType string
+		source      string
+		want        bool
+	}{
+		{"matching scope and source", nodeRuntime, "nodejs@20", LogSourceRuntime, true},
+		{"other scope", nodeRuntime, "python@3.12", LogSourceRuntime, false},
+		{"other source", nodeRuntime, "nodejs@20", LogSourceBuild, false},
+		{"any service type", nodeRuntime, "", LogSourceRuntime, true},
+		{"any source", nodeRuntime, "nodejs@20", "", true},
+		{"common rules apply everywhere", common, "go@1", LogSourceBuild, true},
+		{"php variants share the php scope", php, "php-nginx@8.3", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.AppliesTo(tt.serviceType, tt.source); got != tt.want {
+				t.Errorf("AppliesTo(%q, %q) = %v, want %v", tt.serviceType, tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogRuleSetMatch(t *testing.T) {
+	set, err := DefaultLogRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	lines := []LogLine{
+		{Time: start, Text: "Error: Cannot find module 'express'"},
+		{Time: start.Add(time.Minute), Text: "Error: Cannot find module 'pg'"},
+		{Time: start.Add(2 * time.Minute), Text: "Killed process 42 (node)"},
+		{Time: start.Add(3 * time.Minute), Text: "server listening on :3000"},
+	}
+
+	matches := set.Match(lines, "nodejs@20", LogSourceRuntime)
+	if len(matches) != 2 {
+		t.Fatalf("got %d matches, want 2: %+v", len(matches), matches)
+	}
+	// The critical rule comes first although it matched less often
+	if matches[0].Rule.ID != "out-of-memory" || matches[0].Count != 1 {
+		t.Errorf("first match = %s ×%d, want out-of-memory ×1", matches[0].Rule.ID, matches[0].Count)
+	}
+	modules := matches[1]
+	if modules.Rule.ID != "nodejs-module-not-found" || modules.Count != 2 {
+		t.Errorf("second match = %s ×%d, want nodejs-module-not-found ×2", modules.Rule.ID, modules.Count)
+	}
+	if !modules.First.Equal(start) || !modules.Last.Equal(start.Add(time.Minute)) || modules.Example != lines[0].Text {
+		t.Errorf("match = %+v, want the first and last time and the first line as example", modules)
+	}
+
+	// Rules of another runtime do not apply
+	for _, m := range set.Match(lines, "python@3.12", LogSourceRuntime) {
+		if m.Rule.ID == "nodejs-module-not-found" {
+			t.Error("a Node.js rule matched Python logs")
+		}
+	}
+}
@@ -62,8 +62,16 @@ type ServiceSummary struct {
 	Category    string      `json:"category"`
 	Versions    interface{} `json:"versions"`  // Can be []string or []map[string]interface{}
 	Tags        []string    `json:"tags"`
+	Description string      `json:"description,omitempty"`
 }
 
 type ServiceIndex struct {
-	Services []*ServiceSummary `json:"services"`
+	Services map[string]map[string]ServiceIndexEntry `json:"services"` // category -> service type -> entry
+}
+
+// ServiceIndexEntry describes a service type in the service index
+type ServiceIndexEntry struct {
+	File        string      `json:"file"`
+	Description string      `json:"description"`
+	Versions    interface{} `json:"versions"`
 }
\ No newline at end of file
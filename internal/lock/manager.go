@@ -0,0 +1,178 @@
+// Package lock serializes conflicting operations, such as VPN transitions and
+// deployments, across concurrent tool calls.
+package lock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Mode is how an operation holds a key
+type Mode string
+
+const (
+	// Exclusive operations run alone on their key
+	Exclusive Mode = "exclusive"
+	// Shared operations may run together, but not alongside an exclusive one
+	Shared Mode = "shared"
+)
+
+// Ticket describes an operation holding or waiting for a key
+type Ticket struct {
+	ID        int        `json:"id"`
+	Key       string     `json:"key"`
+	Mode      Mode       `json:"mode"`
+	Operation string     `json:"operation"`
+	Detail    string     `json:"detail,omitempty"`
+	Enqueued  time.Time  `json:"enqueued"`
+	Started   *time.Time `json:"started,omitempty"`
+	// Position is the number of operations ahead of a waiting ticket (0 once running)
+	Position int `json:"position"`
+}
+
+// Running reports whether the operation holds its key
+func (t Ticket) Running() bool {
+	return t.Started != nil
+}
+
+// queue holds the tickets of a key in arrival order
+type queue struct {
+	tickets []*Ticket
+	changed chan struct{} // closed and replaced whenever the queue changes
+}
+
+// Manager grants keys to operations in arrival order. Waiting operations queue
+// behind the ones holding the key; shared operations only share with a run of
+// other shared operations at the head of the queue.
+type Manager struct {
+	mu     sync.Mutex
+	queues map[string]*queue
+	seq    int
+}
+
+// NewManager creates an empty lock manager
+func NewManager() *Manager {
+	return &Manager{queues: make(map[string]*queue)}
+}
+
+// Acquire waits until the operation may run on key and returns a function that
+// releases it. While waiting, onWait (if set) is called with the number of
+// operations ahead whenever it changes. If ctx ends first, the operation leaves
+// the queue and ctx's error is returned.
+func (m *Manager) Acquire(ctx context.Context, key string, mode Mode, operation, detail string, onWait func(position int)) (func(), error) {
+	m.mu.Lock()
+	q, ok := m.queues[key]
+	if !ok {
+		q = &queue{changed: make(chan struct{})}
+		m.queues[key] = q
+	}
+	m.seq++
+	ticket := &Ticket{
+		ID:        m.seq,
+		Key:       key,
+		Mode:      mode,
+		Operation: operation,
+		Detail:    detail,
+		Enqueued:  time.Now(),
+	}
+	q.tickets = append(q.tickets, ticket)
+	m.mu.Unlock()
+
+	reported := -1
+	for {
+		m.mu.Lock()
+		position, granted := q.position(ticket)
+		if granted {
+			now := time.Now()
+			ticket.Started = &now
+			m.mu.Unlock()
+			return m.releaser(key, ticket), nil
+		}
+		changed := q.changed
+		m.mu.Unlock()
+
+		if position != reported && onWait != nil {
+			onWait(position)
+			reported = position
+		}
+
+		select {
+		case <-ctx.Done():
+			m.remove(key, ticket)
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// releaser returns a function that removes the ticket from its queue once
+func (m *Manager) releaser(key string, ticket *Ticket) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { m.remove(key, ticket) })
+	}
+}
+
+// remove drops a ticket and wakes the operations waiting on its key
+func (m *Manager) remove(key string, ticket *Ticket) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.queues[key]
+	if !ok {
+		return
+	}
+	for i, t := range q.tickets {
+		if t == ticket {
+			q.tickets = append(q.tickets[:i], q.tickets[i+1:]...)
+			break
+		}
+	}
+	close(q.changed)
+	q.changed = make(chan struct{})
+	if len(q.tickets) == 0 {
+		delete(m.queues, key)
+	}
+}
+
+// position returns how many operations are ahead of a ticket and whether it may run
+func (q *queue) position(ticket *Ticket) (int, bool) {
+	allShared := true
+	for i, t := range q.tickets {
+		if t == ticket {
+			if i == 0 || (ticket.Mode == Shared && allShared) {
+				return 0, true
+			}
+			return i, false
+		}
+		if t.Mode != Shared {
+			allShared = false
+		}
+	}
+	return 0, false
+}
+
+// Snapshot returns the running and waiting operations, grouped by key in arrival order
+func (m *Manager) Snapshot() []Ticket {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.queues))
+	for key := range m.queues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var tickets []Ticket
+	for _, key := range keys {
+		q := m.queues[key]
+		for _, t := range q.tickets {
+			copied := *t
+			copied.Position, _ = q.position(t)
+			tickets = append(tickets, copied)
+		}
+	}
+	return tickets
+}
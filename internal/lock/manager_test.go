@@ -0,0 +1,152 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// tryAcquire acquires key, giving up after a short wait
+func tryAcquire(m *Manager, key string, mode Mode) (func(), bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	release, err := m.Acquire(ctx, key, mode, "test", "", nil)
+	return release, err == nil
+}
+
+func TestExclusiveRunsAlone(t *testing.T) {
+	m := NewManager()
+
+	release, ok := tryAcquire(m, "vpn", Exclusive)
+	if !ok {
+		t.Fatal("first operation should not wait")
+	}
+	if _, ok := tryAcquire(m, "vpn", Exclusive); ok {
+		t.Error("second exclusive operation should wait")
+	}
+	if _, ok := tryAcquire(m, "vpn", Shared); ok {
+		t.Error("shared operation should wait for an exclusive one")
+	}
+	if other, ok := tryAcquire(m, "deploy", Exclusive); !ok {
+		t.Error("operations on another key should not wait")
+	} else {
+		other()
+	}
+
+	release()
+	if again, ok := tryAcquire(m, "vpn", Exclusive); !ok {
+		t.Error("key should be free after release")
+	} else {
+		again()
+	}
+}
+
+func TestSharedRunTogether(t *testing.T) {
+	m := NewManager()
+
+	first, ok := tryAcquire(m, "vpn", Shared)
+	if !ok {
+		t.Fatal("first shared operation should not wait")
+	}
+	defer first()
+	second, ok := tryAcquire(m, "vpn", Shared)
+	if !ok {
+		t.Fatal("shared operations should run together")
+	}
+	defer second()
+	if _, ok := tryAcquire(m, "vpn", Exclusive); ok {
+		t.Error("exclusive operation should wait for shared ones")
+	}
+}
+
+func TestSharedQueuesBehindWaitingExclusive(t *testing.T) {
+	m := NewManager()
+	release, ok := tryAcquire(m, "vpn", Shared)
+	if !ok {
+		t.Fatal("first shared operation should not wait")
+	}
+
+	positions := make(chan int, 4)
+	granted := make(chan func())
+	go func() {
+		r, err := m.Acquire(context.Background(), "vpn", Exclusive, "connect", "", func(position int) { positions <- position })
+		if err == nil {
+			granted <- r
+		}
+	}()
+	if position := <-positions; position != 1 {
+		t.Errorf("waiting exclusive position = %d, want 1", position)
+	}
+
+	if _, ok := tryAcquire(m, "vpn", Shared); ok {
+		t.Error("shared operation should not overtake a waiting exclusive one")
+	}
+
+	release()
+	select {
+	case r := <-granted:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("waiting exclusive operation was not granted after release")
+	}
+}
+
+func TestAcquireCancelledLeavesQueue(t *testing.T) {
+	m := NewManager()
+	release, _ := tryAcquire(m, "vpn", Exclusive)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.Acquire(ctx, "vpn", Exclusive, "test", "", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if tickets := m.Snapshot(); len(tickets) != 1 {
+		t.Errorf("got %d tickets, want only the running one", len(tickets))
+	}
+	release()
+}
+
+func TestSnapshot(t *testing.T) {
+	m := NewManager()
+	release, _ := tryAcquire(m, "vpn", Exclusive)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	waiting := make(chan struct{})
+	go func() {
+		_, _ = m.Acquire(ctx, "vpn", Exclusive, "disconnect", "project-1", func(int) { close(waiting) })
+	}()
+	<-waiting
+
+	tickets := m.Snapshot()
+	if len(tickets) != 2 {
+		t.Fatalf("got %d tickets, want 2", len(tickets))
+	}
+	if !tickets[0].Running() || tickets[0].Position != 0 {
+		t.Errorf("first ticket = %+v, want running", tickets[0])
+	}
+	if tickets[1].Running() || tickets[1].Position != 1 || tickets[1].Operation != "disconnect" || tickets[1].Detail != "project-1" {
+		t.Errorf("second ticket = %+v, want disconnect waiting at position 1", tickets[1])
+	}
+
+	release()
+	release()
+	cancel()
+}
+
+func TestReleaseIsIdempotent(t *testing.T) {
+	m := NewManager()
+	first, _ := tryAcquire(m, "vpn", Exclusive)
+	first()
+
+	second, ok := tryAcquire(m, "vpn", Exclusive)
+	if !ok {
+		t.Fatal("key should be free after release")
+	}
+	first()
+	if _, ok := tryAcquire(m, "vpn", Exclusive); ok {
+		t.Error("releasing an old operation again freed the key of the current one")
+	}
+	second()
+}
@@ -7,17 +7,18 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
 // RegisterAuthTools registers all authentication tools
-func RegisterAuthTools(s *server.MCPServer, client *api.Client) {
+func RegisterAuthTools(s *server.MCPServer, client api.ZeropsAPI) {
 	// Register auth_validate tool
 	authValidateTool := mcp.NewTool(
 		"auth_validate",
 		mcp.WithDescription("Validate Zerops API key and check authentication"),
 	)
 
-	s.AddTool(authValidateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, authValidateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Validate API key by making a simple API call
 		user, err := client.GetCurrentUser(ctx)
 		if err != nil {
@@ -41,7 +42,7 @@ func RegisterAuthTools(s *server.MCPServer, client *api.Client) {
 		mcp.WithDescription("Get Zerops platform information and capabilities"),
 	)
 
-	s.AddTool(platformInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, platformInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Get user info to check platform access
 		user, err := client.GetCurrentUser(ctx)
 		if err != nil {
@@ -66,7 +67,7 @@ func RegisterAuthTools(s *server.MCPServer, client *api.Client) {
 
 		return SuccessResponse(map[string]interface{}{
 			"message":        "Platform information retrieved",
-			"apiUrl":         client.GetBaseURL(),
+			"apiUrl":         client.GetBaseURL(ctx),
 			"userEmail":      user.Email,
 			"availableRegions": regionNames,
 			"defaultRegion":  defaultRegion,
@@ -88,22 +89,23 @@ func RegisterAuthTools(s *server.MCPServer, client *api.Client) {
 		mcp.WithDescription("List all available Zerops regions"),
 	)
 
-	s.AddTool(regionListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, regionListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		regions, err := client.ListRegions(ctx)
 		if err != nil {
 			return HandleAPIError(err), nil
 		}
 
 		if len(regions) == 0 {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"NO_REGIONS",
 				"No regions available",
 				"Contact Zerops support if this persists",
-			), nil
+			)), nil
 		}
 
 		// Format region information
 		response := fmt.Sprintf("Available Zerops regions (%d total):\n\n", len(regions))
+		items := make([]map[string]interface{}, 0, len(regions))
 		
 		for _, region := range regions {
 			response += fmt.Sprintf("• %s", region.Name)
@@ -111,10 +113,70 @@ func RegisterAuthTools(s *server.MCPServer, client *api.Client) {
 				response += " (default)"
 			}
 			response += fmt.Sprintf("\n  API endpoint: %s\n", region.Address)
+
+			items = append(items, map[string]interface{}{
+				"name":    region.Name,
+				"address": region.Address,
+				"default": region.IsDefault,
+			})
 		}
 
 		response += "\nNext step: Use 'project_create' with one of these regions"
 
-		return mcp.NewToolResultText(response), nil
+		return StructuredResponse(response, map[string]interface{}{
+			"regions":   items,
+			"next_step": "Use 'project_create' with one of these regions",
+		}), nil
+	})
+
+	// Register org_list tool
+	orgListTool := mcp.NewTool(
+		"org_list",
+		mcp.WithDescription("List the organizations (clients) you belong to and which one is used by default"),
+	)
+
+	addTool(s, orgListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		user, err := client.GetCurrentUser(ctx)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+
+		if len(user.ClientUserList) == 0 {
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAuth,
+				"NO_CLIENT",
+				"No client associations found for user",
+				"Contact Zerops support to resolve account issues",
+			)), nil
+		}
+
+		defaultClientID, errResult := GetClientID(ctx, client, "")
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		response := fmt.Sprintf("Organizations (%d total):\n\n", len(user.ClientUserList))
+		orgs := make([]map[string]interface{}, 0, len(user.ClientUserList))
+		for _, cu := range user.ClientUserList {
+			response += fmt.Sprintf("• %s", cu.Client.AccountName)
+			if cu.ClientID == defaultClientID {
+				response += " (default)"
+			}
+			response += fmt.Sprintf("\n  Client ID: %s\n  Role: %s\n  Status: %s\n", cu.ClientID, cu.RoleCode, cu.Status)
+
+			orgs = append(orgs, map[string]interface{}{
+				"client_id":    cu.ClientID,
+				"account_name": cu.Client.AccountName,
+				"role":         cu.RoleCode,
+				"status":       cu.Status,
+				"default":      cu.ClientID == defaultClientID,
+			})
+		}
+
+		response += "\nNext step: Pass client_id (ID or account name) to 'project_list', 'project_create' or 'project_import' to work in another organization. Set ZEROPS_ORG to change the default"
+
+		return StructuredResponse(response, map[string]interface{}{
+			"default_client_id": defaultClientID,
+			"organizations":     orgs,
+		}), nil
 	})
-}
\ No newline at end of file
+}
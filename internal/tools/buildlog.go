@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+)
+
+// buildPhase is one step of the deployment pipeline
+type buildPhase struct {
+	Name   string
+	Step   string
+	marker *regexp.Regexp
+}
+
+// buildPhases lists the pipeline steps in execution order. A phase starts at
+// the first line announcing it; lines before any announcement belong to prepare.
+// The runtime is prepared after the build, before the app is deployed to it.
+var buildPhases = []buildPhase{
+	{"prepare", "build.prepareCommands", regexp.MustCompile(`(?i)\bprepare_?commands\b|^[\s=#>*➤-]*\[?prepar(e|ing)\b`)},
+	{"build", "build.buildCommands", regexp.MustCompile(`(?i)\bbuild_?commands\b|^[\s=#>*➤-]*\[?build(ing)?\b`)},
+	{"prepare_runtime", "run.prepareCommands", regexp.MustCompile(`(?i)\brun\.prepare_?commands\b|\bprepar(e|ing) (the )?runtime\b|\bruntime prepare\b`)},
+	{"deploy", "build.deployFiles / deploy", regexp.MustCompile(`(?i)\bdeploy_?files\b|^[\s=#>*➤-]*\[?deploy(ing)?\b`)},
+	{"init", "run.initCommands", regexp.MustCompile(`(?i)\binit_?commands\b|^[\s=#>*➤-]*\[?init(ializing)?\b`)},
+}
+
+// failedStatusPhase maps failed app version statuses to the phase that failed
+var failedStatusPhase = map[string]string{
+	"BUILD_FAILED":             "build",
+	"PREPARING_RUNTIME_FAILED": "prepare_runtime",
+	"DEPLOY_FAILED":            "deploy",
+}
+
+var (
+	exitCodePattern    = regexp.MustCompile(`(?i)exit(?:ed)?(?:\s+with)?(?:\s+(?:status|code))?[:\s]+([1-9][0-9]{0,2})\b`)
+	failureLinePattern = regexp.MustCompile(`(?i)non-zero exit|command failed|returned a non-zero|\bfailed\b|\berror\b`)
+)
+
+// phaseSummary reports the outcome of a single pipeline phase
+type phaseSummary struct {
+	Name   string `json:"name"`
+	Step   string `json:"step"`
+	Lines  int    `json:"lines"`
+	Status string `json:"status"`
+}
+
+// buildReport is the result of analyzing a deployment's logs
+type buildReport struct {
+	Phases      []phaseSummary `json:"phases"`
+	Current     string         `json:"current_phase,omitempty"`
+	Failed      bool           `json:"failed"`
+	FailedPhase string         `json:"failed_phase,omitempty"`
+	FailedStep  string         `json:"failed_step,omitempty"`
+	ExitLine    string         `json:"exit_line,omitempty"`
+	ExitCode    int            `json:"exit_code,omitempty"`
+}
+
+// analyzeBuildLog splits build pipeline and runtime lines of a deployment into phases
+// and locates the line the failing step exited on. Runtime lines come from the
+// service's own containers and therefore start in the deploy phase at the earliest.
+func analyzeBuildLog(version api.AppVersion, build, runtime []api.LogEntry) buildReport {
+	counts := make([]int, len(buildPhases))
+	current := 0
+	exitPhase, exitScore := -1, 0
+	var exitEntry api.LogEntry
+
+	scan := func(entries []api.LogEntry) {
+		for _, entry := range entries {
+			message := strings.TrimSpace(entry.Message)
+			for i := current + 1; i < len(buildPhases); i++ {
+				if buildPhases[i].marker.MatchString(message) {
+					current = i
+				}
+			}
+			counts[current]++
+
+			// Prefer explicit exit codes, then failure wording, then error severity.
+			// The first line of the best kind is where the step exited; later ones
+			// are fallout, such as cleanup or runtime errors.
+			score := 0
+			switch {
+			case exitCodePattern.MatchString(message):
+				score = 3
+			case failureLinePattern.MatchString(message):
+				score = 2
+			case entry.Severity <= 3:
+				score = 1
+			}
+			if score > exitScore {
+				exitPhase, exitScore, exitEntry = current, score, entry
+			}
+		}
+	}
+	scan(build)
+	if deploy := phaseIndex("deploy"); len(runtime) > 0 && current < deploy {
+		current = deploy
+	}
+	scan(runtime)
+
+	report := buildReport{Current: buildPhases[current].Name}
+	failedIdx := -1
+	if phase, ok := failedStatusPhase[version.Status]; ok {
+		report.Failed = true
+		failedIdx = phaseIndex(phase)
+		if exitPhase >= 0 {
+			failedIdx = exitPhase
+		}
+	}
+
+	if report.Failed {
+		report.FailedPhase = buildPhases[failedIdx].Name
+		report.FailedStep = buildPhases[failedIdx].Step
+		if exitPhase >= 0 {
+			report.ExitLine = exitEntry.String()
+			if m := exitCodePattern.FindStringSubmatch(exitEntry.Message); m != nil {
+				report.ExitCode, _ = strconv.Atoi(m[1])
+			}
+		}
+	}
+
+	for i, phase := range buildPhases {
+		summary := phaseSummary{Name: phase.Name, Step: phase.Step, Lines: counts[i]}
+		switch {
+		case report.Failed && i < failedIdx:
+			summary.Status = "ok"
+		case report.Failed && i == failedIdx:
+			summary.Status = "failed"
+		case report.Failed:
+			summary.Status = "not reached"
+		case version.Status == "ACTIVE":
+			summary.Status = "ok"
+		case i < current:
+			summary.Status = "ok"
+		case i == current:
+			summary.Status = "running"
+		default:
+			summary.Status = "pending"
+		}
+		report.Phases = append(report.Phases, summary)
+	}
+
+	return report
+}
+
+// phaseIndex returns the position of a phase in the pipeline
+func phaseIndex(name string) int {
+	for i, phase := range buildPhases {
+		if phase.Name == name {
+			return i
+		}
+	}
+	return 0
+}
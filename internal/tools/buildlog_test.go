@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+)
+
+// logLines builds informational entries with the given messages
+func logLines(messages ...string) []api.LogEntry {
+	entries := make([]api.LogEntry, len(messages))
+	for i, message := range messages {
+		entries[i] = api.LogEntry{ID: message, Message: message, Severity: 6}
+	}
+	return entries
+}
+
+// phaseStatuses returns the status of every phase in pipeline order
+func phaseStatuses(report buildReport) []string {
+	statuses := make([]string, len(report.Phases))
+	for i, phase := range report.Phases {
+		statuses[i] = phase.Status
+	}
+	return statuses
+}
+
+func TestAnalyzeBuildLogFailedBuild(t *testing.T) {
+	build := logLines(
+		"Running prepareCommands",
+		"apt-get install -y curl",
+		"Running buildCommands",
+		"npm run build",
+		"src/index.ts(3,1): error TS2304: Cannot find name 'foo'",
+		"Command exited with code 2",
+	)
+
+	report := analyzeBuildLog(api.AppVersion{Status: "BUILD_FAILED"}, build, nil)
+
+	if !report.Failed || report.FailedPhase != "build" || report.FailedStep != "build.buildCommands" {
+		t.Errorf("failed = %v, phase %q, step %q; want a failed build phase", report.Failed, report.FailedPhase, report.FailedStep)
+	}
+	if report.ExitCode != 2 || report.ExitLine == "" {
+		t.Errorf("exit code = %d, line %q; want the exit line with code 2", report.ExitCode, report.ExitLine)
+	}
+	want := []string{"ok", "failed", "not reached", "not reached", "not reached"}
+	if got := phaseStatuses(report); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if report.Phases[0].Lines != 2 || report.Phases[1].Lines != 4 {
+		t.Errorf("lines = %d/%d, want 2 prepare and 4 build lines", report.Phases[0].Lines, report.Phases[1].Lines)
+	}
+}
+
+func TestAnalyzeBuildLogFallsBackToStatusPhase(t *testing.T) {
+	build := logLines("Running prepareCommands", "Running buildCommands", "npm run build", "installing runtime packages")
+
+	report := analyzeBuildLog(api.AppVersion{Status: "PREPARING_RUNTIME_FAILED"}, build, nil)
+
+	if report.FailedPhase != "prepare_runtime" || report.FailedStep != "run.prepareCommands" || report.ExitLine != "" {
+		t.Errorf("phase %q, step %q, exit line %q; want run.prepareCommands from the status without an exit line", report.FailedPhase, report.FailedStep, report.ExitLine)
+	}
+	want := []string{"ok", "ok", "failed", "not reached", "not reached"}
+	if got := phaseStatuses(report); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeBuildLogRuntimePreparePhase(t *testing.T) {
+	build := logLines(
+		"Running buildCommands",
+		"npm run build",
+		"Running run.prepareCommands",
+		"apt-get install -y imagemagick",
+		"Command exited with code 100",
+	)
+
+	report := analyzeBuildLog(api.AppVersion{Status: "PREPARING_RUNTIME_FAILED"}, build, nil)
+
+	if report.FailedPhase != "prepare_runtime" || report.ExitCode != 100 {
+		t.Errorf("phase %q, exit code %d; want the runtime prepare phase exiting with 100", report.FailedPhase, report.ExitCode)
+	}
+	if report.Phases[1].Status != "ok" || report.Phases[2].Lines != 3 {
+		t.Errorf("phases = %+v, want a finished build and 3 runtime prepare lines", report.Phases)
+	}
+}
+
+func TestAnalyzeBuildLogPrefersExitCodeOverErrorSeverity(t *testing.T) {
+	build := logLines("Running buildCommands", "go build ./...", "exit status 1")
+	build = append(build, api.LogEntry{ID: "late", Message: "cleaning up", Severity: 3})
+
+	report := analyzeBuildLog(api.AppVersion{Status: "BUILD_FAILED"}, build, nil)
+
+	if report.ExitCode != 1 {
+		t.Errorf("exit code = %d, want 1 from the explicit exit line", report.ExitCode)
+	}
+}
+
+func TestAnalyzeBuildLogKeepsFirstExitLine(t *testing.T) {
+	build := logLines("Running buildCommands", "src/app.go:3: undefined: foo", "exit status 2")
+	runtime := []api.LogEntry{
+		{ID: "cleanup", Message: "removing build container: exit status 1"},
+		{ID: "runtime", Message: "container error: failed to start", Severity: 3},
+	}
+
+	report := analyzeBuildLog(api.AppVersion{Status: "BUILD_FAILED"}, build, runtime)
+
+	if report.ExitCode != 2 || report.FailedPhase != "build" {
+		t.Errorf("exit code %d, phase %q; want the build step's own exit line", report.ExitCode, report.FailedPhase)
+	}
+}
+
+func TestAnalyzeBuildLogRuntimeLinesStartInDeploy(t *testing.T) {
+	build := logLines("Running prepareCommands", "Running buildCommands", "npm run build")
+	runtime := logLines("server listening on :3000")
+
+	report := analyzeBuildLog(api.AppVersion{Status: "DEPLOYING"}, build, runtime)
+
+	if report.Failed || report.Current != "deploy" {
+		t.Errorf("failed = %v, current %q; want a running deploy phase", report.Failed, report.Current)
+	}
+	want := []string{"ok", "ok", "ok", "running", "pending"}
+	if got := phaseStatuses(report); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeBuildLogActiveVersion(t *testing.T) {
+	report := analyzeBuildLog(api.AppVersion{Status: "ACTIVE"}, logLines("Running buildCommands"), nil)
+
+	for _, phase := range report.Phases {
+		if phase.Status != "ok" {
+			t.Errorf("phase %s = %q, want ok for an active version", phase.Name, phase.Status)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// cancelledNotification is the notification a client sends to cancel a request
+const cancelledNotification = "notifications/cancelled"
+
+// requestIDMeta is the _meta field a tool call's request ID is passed to its handler in
+const requestIDMeta = "zeropsRequestId"
+
+// cancellations cancels the context of tool calls the client cancelled. mcp-go
+// keeps running a handler after notifications/cancelled arrives, so each call gets
+// a context of its own, registered under its session and request ID.
+type cancellations struct {
+	mu    sync.Mutex
+	calls map[string]context.CancelFunc
+}
+
+// CancellationOptions returns server options that stop a tool call, and the zcli
+// command it runs, when the client cancels the call
+func CancellationOptions() []server.ServerOption {
+	c := &cancellations{calls: make(map[string]context.CancelFunc)}
+
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(c.tagRequest)
+	return []server.ServerOption{
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(c.middleware),
+		func(s *server.MCPServer) {
+			s.AddNotificationHandler(cancelledNotification, c.handleCancelled)
+		},
+	}
+}
+
+// tagRequest passes the request ID of a tool call on to its handler, which mcp-go
+// does not do itself
+func (c *cancellations) tagRequest(ctx context.Context, id any, request *mcp.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[requestIDMeta] = callKey(ctx, id)
+}
+
+// middleware runs a tool call with a context the client can cancel
+func (c *cancellations) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta == nil {
+			return next(ctx, request)
+		}
+		key, ok := request.Params.Meta.AdditionalFields[requestIDMeta].(string)
+		if !ok {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		c.mu.Lock()
+		c.calls[key] = cancel
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// handleCancelled cancels the tool call named by a notifications/cancelled
+func (c *cancellations) handleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	cancel := c.calls[callKey(ctx, id)]
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// callKey identifies a request within the session it was sent on
+func callKey(ctx context.Context, id any) string {
+	session := ""
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		session = s.SessionID()
+	}
+	return fmt.Sprintf("%s/%v", session, id)
+}
@@ -0,0 +1,103 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+	"github.com/zeropsio/zerops-mcp-v3/internal/config"
+	"github.com/zeropsio/zerops-mcp-v3/internal/tools"
+)
+
+// fakeZCLIPush puts a zcli on PATH whose push touches started and then runs until
+// it is stopped, and records a VPN session on the loopback interface so the push
+// is not blocked on the tunnel
+func fakeZCLIPush(t *testing.T, started string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = login ]; then printf '{\"Token\":\"%s\"}' \"$ZEROPS_TOKEN\" > \"$ZEROPS_CLI_DATA_FILE_PATH\"; exit 0; fi\n" +
+		"echo 'Uploading package'\n" +
+		"touch " + started + "\n" +
+		"exec sleep 30\n"
+	if err := os.WriteFile(filepath.Join(dir, "zcli"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	session := filepath.Join(configDir, "zerops-mcp", "vpn.json")
+	if err := os.MkdirAll(filepath.Dir(session), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(session, []byte(`{"projectId":"project-1","interface":"lo"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCancelledNotificationStopsZCLIPush(t *testing.T) {
+	started := filepath.Join(t.TempDir(), "started")
+	fakeZCLIPush(t, started)
+
+	cfg := &config.Config{
+		ZeropsAPIKey:  apitest.DefaultAPIKey,
+		OutputFormat:  config.OutputFormatText,
+		DeployBackend: config.DeployBackendZCLI,
+		VPNHelperOff:  true,
+	}
+	options := append([]server.ServerOption{server.WithToolCapabilities(false)}, tools.CancellationOptions()...)
+	s := server.NewMCPServer("zerops-test", "test", options...)
+	if err := tools.RegisterAllWithAPI(s, cfg, apitest.NewReplayer(nil)); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		responses <- s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":"push-1","method":"tools/call",`+
+			`"params":{"name":"deploy_push","arguments":{"project_id":"project-1","service_name":"app","working_dir":"`+t.TempDir()+`","format":"json"}}}`))
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("zcli push did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Another request ID leaves the push running
+	s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"push-2"}}`))
+	select {
+	case <-responses:
+		t.Fatal("cancelling another request stopped the push")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"push-1","reason":"user"}}`))
+
+	var response mcp.JSONRPCMessage
+	select {
+	case response = <-responses:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deploy_push kept running after it was cancelled")
+	}
+	raw, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "DEPLOY_CANCELLED") {
+		t.Errorf("response = %s, want a DEPLOY_CANCELLED error", raw)
+	}
+}
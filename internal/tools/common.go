@@ -1,27 +1,44 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
-// ErrorResponse creates a standardized error response
-func ErrorResponse(code, message, resolution string) *mcp.CallToolResult {
-	errorText := fmt.Sprintf("Error: %s\n%s\n\nResolution: %s", code, message, resolution)
-	return mcp.NewToolResultError(errorText)
-}
-
-// ErrorResponseWithNext includes a suggestion for the next tool to use
-func ErrorResponseWithNext(code, message, resolution, nextTool string) *mcp.CallToolResult {
-	errorText := fmt.Sprintf("Error: %s\n%s\n\nResolution: %s\n\nNext step: Use '%s' tool",
-		code, message, resolution, nextTool)
-	return mcp.NewToolResultError(errorText)
+// ToolErrorResponse renders a tool error as human-readable text followed by
+// a JSON block that agents can parse
+func ToolErrorResponse(toolErr *zerrors.ToolError) *mcp.CallToolResult {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Error: %s\n%s", toolErr.Code, toolErr.Message))
+	if toolErr.Resolution != "" {
+		b.WriteString(fmt.Sprintf("\n\nResolution: %s", toolErr.Resolution))
+	}
+	if toolErr.NextTool != "" {
+		b.WriteString(fmt.Sprintf("\n\nNext step: Use '%s' tool", toolErr.NextTool))
+	}
+	if data, err := json.MarshalIndent(toolErr, "", "  "); err == nil {
+		b.WriteString("\n\n```json\n")
+		b.Write(data)
+		b.WriteString("\n```")
+	}
+	return withStructured(mcp.NewToolResultError(b.String()), toolErr)
 }
 
 // SuccessResponse creates a standardized success response
 func SuccessResponse(data map[string]interface{}) *mcp.CallToolResult {
+	structured := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		structured[key] = value
+	}
+
 	var response string
 	if msg, ok := data["message"].(string); ok {
 		response = msg + "\n\n"
@@ -35,7 +52,7 @@ func SuccessResponse(data map[string]interface{}) *mcp.CallToolResult {
 		response += fmt.Sprintf("%s: %v\n", humanKey, value)
 	}
 	
-	return mcp.NewToolResultText(response)
+	return StructuredResponse(response, structured)
 }
 
 // InfoResponse creates a standardized informational response (not an error)
@@ -44,7 +61,11 @@ func InfoResponse(title, message, nextStep string) *mcp.CallToolResult {
 	if nextStep != "" {
 		infoText += fmt.Sprintf("\n\nNext step: %s", nextStep)
 	}
-	return mcp.NewToolResultText(infoText)
+	return StructuredResponse(infoText, map[string]interface{}{
+		"title":     title,
+		"message":   message,
+		"next_step": nextStep,
+	})
 }
 
 // InfoResponseWithAction creates an info response with an action taken
@@ -53,102 +74,150 @@ func InfoResponseWithAction(title, message, action, nextStep string) *mcp.CallTo
 	if nextStep != "" {
 		infoText += fmt.Sprintf("\n\nNext step: %s", nextStep)
 	}
-	return mcp.NewToolResultText(infoText)
+	return StructuredResponse(infoText, map[string]interface{}{
+		"title":        title,
+		"message":      message,
+		"action_taken": action,
+		"next_step":    nextStep,
+	})
 }
 
 // HandleAPIError converts API errors to user-friendly messages
 func HandleAPIError(err error) *mcp.CallToolResult {
-	errStr := err.Error()
-	
-	// Check for specific error types
+	return ToolErrorResponse(NewAPIToolError(err))
+}
+
+// NewAPIToolError maps an API client error onto the tool error taxonomy
+func NewAPIToolError(err error) *zerrors.ToolError {
+	if errors.Is(err, api.ErrUnknownProfile) {
+		return zerrors.NewValidationError(
+			"UNKNOWN_PROFILE",
+			err.Error(),
+			"Use a profile name from 'profile_list'",
+		).WithNextTool("profile_list")
+	}
+
+	apiErr, ok := zerrors.AsAPIError(err)
+	if !ok {
+		return transportToolError(err)
+	}
+
+	toolErr := classifyAPIError(apiErr)
+	toolErr.Message += formatAPIErrorDetails(apiErr)
+	toolErr.Retryable = apiErr.IsRetryable()
+	toolErr.WithMetadata("status_code", apiErr.StatusCode)
+	if apiErr.Code != "" {
+		toolErr.WithMetadata("api_code", apiErr.Code)
+	}
+	if len(apiErr.Meta) > 0 {
+		toolErr.WithMetadata("api_meta", apiErr.Meta)
+	}
+	if apiErr.RequestID != "" {
+		toolErr.WithMetadata("request_id", apiErr.RequestID)
+	}
+	return toolErr
+}
+
+// classifyAPIError picks the code and resolution for an API error
+func classifyAPIError(apiErr *zerrors.APIError) *zerrors.ToolError {
 	switch {
-	case strings.Contains(errStr, "401"):
-		return ErrorResponse(
+	case apiErr.StatusCode == 401:
+		return zerrors.New(zerrors.CategoryAuth,
 			"AUTH_FAILED",
 			"Authentication failed - invalid API key",
 			"Set ZEROPS_API_KEY environment variable with a valid key",
 		)
-	case strings.Contains(errStr, "403"):
-		return ErrorResponse(
+	case apiErr.StatusCode == 403:
+		return zerrors.New(zerrors.CategoryAuth,
 			"FORBIDDEN",
 			"Access denied - insufficient permissions",
 			"Check that your API key has the required permissions for this operation",
 		)
-	case strings.Contains(errStr, "404"):
-		// Special handling for logs endpoint
-		if strings.Contains(errStr, "logs endpoint not available") {
-			return ErrorResponseWithNext(
-				"LOGS_NOT_AVAILABLE",
-				"Service logs are not available through the API",
-				"The service may be restarting or logs may not be enabled. Check service status first",
-				"service_info",
-			)
-		}
-		return ErrorResponse(
+	case apiErr.StatusCode == 404:
+		return zerrors.New(zerrors.CategoryAPI,
 			"NOT_FOUND",
 			"Resource not found",
 			"Check the ID and try again, or use list tools to find valid resources",
 		)
-	case strings.Contains(errStr, "409"):
-		return ErrorResponse(
+	case apiErr.StatusCode == 409:
+		return zerrors.New(zerrors.CategoryAPI,
 			"CONFLICT",
 			"Resource already exists or conflict detected",
 			"Use a different name or check existing resources with list tools",
 		)
-	case strings.Contains(errStr, "422"):
-		return ErrorResponse(
+	case apiErr.StatusCode == 422:
+		return zerrors.New(zerrors.CategoryValidation,
 			"INVALID_INPUT",
 			"Invalid input data",
 			"Check your input parameters and ensure they meet the requirements",
 		)
-	case strings.Contains(errStr, "400"):
+	case apiErr.StatusCode == 429:
+		return zerrors.New(zerrors.CategoryAPI,
+			"RATE_LIMITED",
+			"Too many requests to Zerops API",
+			"Wait a moment before retrying the operation",
+		)
+	case apiErr.StatusCode == 400:
 		// Parse common 400 errors
-		if strings.Contains(errStr, "processNotFound") {
-			return ErrorResponse(
+		switch apiErr.Code {
+		case "processNotFound":
+			return zerrors.New(zerrors.CategoryAPI,
 				"PROCESS_NOT_FOUND",
 				"Process not found or has expired",
 				"The process may have completed and been removed from the system. Processes are only tracked for a limited time after completion",
 			)
-		}
-		if strings.Contains(errStr, "projectImportInvalidParameter") {
-			return ErrorResponse(
+		case "projectImportInvalidParameter":
+			return zerrors.New(zerrors.CategoryValidation,
 				"INVALID_YAML_STRUCTURE",
 				"YAML must contain 'services:' section with proper structure",
 				"Ensure your YAML has 'services:' section and uses 'hostname' (not 'name') for service names",
 			)
-		}
-		if strings.Contains(errStr, "projectImportProjectIncluded") {
-			return ErrorResponse(
+		case "projectImportProjectIncluded":
+			return zerrors.New(zerrors.CategoryValidation,
 				"PROJECT_CONFIG_NOT_ALLOWED",
 				"Project configuration is not allowed in import YAML",
 				"Remove the 'project:' section from your YAML - only 'services:' section is needed",
 			)
 		}
-		return ErrorResponse(
+		return zerrors.New(zerrors.CategoryValidation,
 			"INVALID_REQUEST",
-			fmt.Sprintf("Invalid request: %v", err),
+			fmt.Sprintf("Invalid request: %s", apiErr.Message),
 			"Check your input parameters and try again",
 		)
-	case strings.Contains(errStr, "500") || strings.Contains(errStr, "502") || strings.Contains(errStr, "503"):
-		return ErrorResponse(
+	case apiErr.StatusCode >= 500:
+		return zerrors.New(zerrors.CategoryAPI,
 			"SERVER_ERROR",
 			"Zerops API is experiencing issues",
 			"Wait a moment and try again. If the problem persists, check Zerops status page",
 		)
+	default:
+		return zerrors.New(zerrors.CategoryAPI,
+			"API_ERROR",
+			fmt.Sprintf("API request failed: %s", apiErr.Message),
+			"Check your inputs and try again. If the problem persists, check API status",
+		)
+	}
+}
+
+// transportToolError converts errors that never reached the API
+func transportToolError(err error) *zerrors.ToolError {
+	errStr := err.Error()
+
+	switch {
 	case strings.Contains(errStr, "connection refused"):
-		return ErrorResponse(
+		return zerrors.New(zerrors.CategoryAPI,
 			"CONNECTION_FAILED",
 			"Failed to connect to Zerops API",
 			"Check your internet connection and try again",
-		)
-	case strings.Contains(errStr, "timeout"):
-		return ErrorResponse(
+		).WithRetryable(true)
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(errStr, "timeout"):
+		return zerrors.New(zerrors.CategoryTimeout,
 			"TIMEOUT",
 			"Request timed out",
 			"The operation took too long. Try again or check if the service is responding",
 		)
 	default:
-		return ErrorResponse(
+		return zerrors.New(zerrors.CategoryAPI,
 			"API_ERROR",
 			fmt.Sprintf("API request failed: %v", err),
 			"Check your inputs and try again. If the problem persists, check API status",
@@ -156,6 +225,37 @@ func HandleAPIError(err error) *mcp.CallToolResult {
 	}
 }
 
+// formatAPIErrorDetails renders the API error code, field details and request ID
+func formatAPIErrorDetails(apiErr *zerrors.APIError) string {
+	var b strings.Builder
+	if apiErr.Code != "" {
+		b.WriteString(fmt.Sprintf("\nAPI code: %s", apiErr.Code))
+	}
+	for _, meta := range apiErr.Meta {
+		line := meta.Error
+		if meta.Code != "" {
+			line = fmt.Sprintf("%s: %s", meta.Code, meta.Error)
+		}
+		if len(meta.Metadata) > 0 {
+			keys := make([]string, 0, len(meta.Metadata))
+			for k := range meta.Metadata {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			parts := make([]string, 0, len(keys))
+			for _, k := range keys {
+				parts = append(parts, fmt.Sprintf("%s=%v", k, meta.Metadata[k]))
+			}
+			line += fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+		}
+		b.WriteString("\n- " + line)
+	}
+	if apiErr.RequestID != "" {
+		b.WriteString(fmt.Sprintf("\nRequest ID: %s", apiErr.RequestID))
+	}
+	return b.String()
+}
+
 // camelCaseToHuman converts camelCase to human readable format
 func camelCaseToHuman(s string) string {
 	var result []rune
@@ -178,102 +278,18 @@ func camelCaseToHuman(s string) string {
 	return str
 }
 
-// AnalyzeRuntimeError analyzes common runtime errors and provides solutions
-func AnalyzeRuntimeError(logs []string) string {
-	var issues []string
-	
-	// Check each log line for common errors
-	for _, line := range logs {
-		lowerLine := strings.ToLower(line)
-		
-		// Python module errors
-		if strings.Contains(line, "ModuleNotFoundError") || strings.Contains(line, "ImportError") {
-			if strings.Contains(line, "flask") || strings.Contains(line, "django") || 
-			   strings.Contains(line, "fastapi") || strings.Contains(line, "requests") {
-				issues = append(issues, "Python dependencies not installed in runtime container")
-			}
-		}
-		
-		// Node.js module errors
-		if strings.Contains(line, "Cannot find module") || strings.Contains(line, "MODULE_NOT_FOUND") {
-			issues = append(issues, "Node.js dependencies not installed in runtime container")
-		}
-		
-		// Port binding errors
-		if strings.Contains(lowerLine, "address already in use") || strings.Contains(lowerLine, "eaddrinuse") {
-			issues = append(issues, "Port conflict - the specified port is already in use")
-		}
-		
-		// Permission errors
-		if strings.Contains(lowerLine, "permission denied") || strings.Contains(lowerLine, "eacces") {
-			issues = append(issues, "Permission denied - check file permissions or port numbers")
-		}
-		
-		// Database connection errors
-		if strings.Contains(lowerLine, "connection refused") || strings.Contains(lowerLine, "econnrefused") {
-			if strings.Contains(lowerLine, "postgres") || strings.Contains(lowerLine, "mysql") ||
-			   strings.Contains(lowerLine, "mongodb") {
-				issues = append(issues, "Database connection failed - check environment variables")
-			}
-		}
-		
-		// Memory errors
-		if strings.Contains(lowerLine, "out of memory") || strings.Contains(lowerLine, "heap out of memory") {
-			issues = append(issues, "Out of memory - increase container resources")
-		}
-	}
-	
-	// Remove duplicates and create resolution text
-	seen := make(map[string]bool)
-	var resolutions []string
-	
-	for _, issue := range issues {
-		if !seen[issue] {
-			seen[issue] = true
-			resolution := getResolutionForIssue(issue)
-			resolutions = append(resolutions, fmt.Sprintf("- %s: %s", issue, resolution))
-		}
-	}
-	
-	if len(resolutions) > 0 {
-		return "\n\nDetected Issues:\n" + strings.Join(resolutions, "\n")
-	}
-	
-	return ""
-}
-
-// getResolutionForIssue provides specific resolution for each issue type
-func getResolutionForIssue(issue string) string {
-	switch issue {
-	case "Python dependencies not installed in runtime container":
-		return "Add 'prepareCommands: [\"pip install -r requirements.txt\"]' to the 'run' section of zerops.yml"
-	case "Node.js dependencies not installed in runtime container":
-		return "Add 'prepareCommands: [\"npm ci --production\"]' to the 'run' section of zerops.yml"
-	case "Port conflict - the specified port is already in use":
-		return "Change the port in your application or update the port configuration in zerops.yml"
-	case "Permission denied - check file permissions or port numbers":
-		return "Use ports above 1024 or check file permissions in your deployment"
-	case "Database connection failed - check environment variables":
-		return "Verify environment variables are set correctly (DB_HOST, DB_USER, etc.) and database service is running"
-	case "Out of memory - increase container resources":
-		return "Increase minRAM/maxRAM in service configuration or optimize application memory usage"
-	default:
-		return "Check application configuration and logs for more details"
-	}
-}
-
 // GenerateSubdomainURL generates the subdomain URL for a service
 // The pattern is: 
-// - For port 80: https://{service-name}-{zeropsSubdomainHost}.prg1.zerops.app
-// - For other ports: https://{service-name}-{zeropsSubdomainHost}-{port}.prg1.zerops.app
+// - For port 80: https://{service-name}-{zeropsSubdomainHost}.{region}.zerops.app
+// - For other ports: https://{service-name}-{zeropsSubdomainHost}-{port}.{region}.zerops.app
 // Examples: 
 // - https://app-15e3.prg1.zerops.app (port 80)
 // - https://mailpit-15e3-8025.prg1.zerops.app (port 8025)
-func GenerateSubdomainURL(serviceName, zeropsSubdomainHost string, port int) string {
+func GenerateSubdomainURL(serviceName, zeropsSubdomainHost, domain string, port int) string {
 	if port == 80 {
-		return fmt.Sprintf("https://%s-%s.prg1.zerops.app", serviceName, zeropsSubdomainHost)
+		return fmt.Sprintf("https://%s-%s.%s", serviceName, zeropsSubdomainHost, domain)
 	}
-	return fmt.Sprintf("https://%s-%s-%d.prg1.zerops.app", serviceName, zeropsSubdomainHost, port)
+	return fmt.Sprintf("https://%s-%s-%d.%s", serviceName, zeropsSubdomainHost, port, domain)
 }
 
 // PreprocessYAML preprocesses Zerops YAML to handle special syntax
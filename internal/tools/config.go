@@ -9,19 +9,20 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
 	"gopkg.in/yaml.v3"
 )
 
 // RegisterConfigTools registers all configuration-related tools
-func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
+func RegisterConfigTools(s *server.MCPServer, client api.ZeropsAPI) {
 	// Register config_templates tool
 	configTemplatesTool := mcp.NewTool(
 		"config_templates",
 		mcp.WithDescription("List all available configuration templates with descriptions"),
 	)
 
-	s.AddTool(configTemplatesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, configTemplatesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		templates := []struct {
 			Name        string
 			Description string
@@ -112,14 +113,14 @@ func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(envVarsShowTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, envVarsShowTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		showValues := request.GetBool("show_values", false)
@@ -136,7 +137,7 @@ func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
 				"service_id":   serviceID,
 				"service_name": service.Name,
 				"env_count":    0,
-				"next_step":    "Environment variables can only be set during service creation via import",
+				"next_step":    "Use 'env_set' to add environment variables",
 			}), nil
 		}
 
@@ -226,45 +227,45 @@ func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(configValidateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, configValidateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		configPath, err := request.RequireString("config_path")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_CONFIG_PATH",
 				"Configuration path is required",
 				"Provide the path to your zerops.yml file",
-			), nil
+			)), nil
 		}
 
 		strict := request.GetBool("strict", false)
 
 		// Check if file exists
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIG_NOT_FOUND",
 				fmt.Sprintf("Configuration file not found: %s", configPath),
 				"Check the file path and ensure the file exists",
-			), nil
+			)), nil
 		}
 
 		// Read the configuration file
 		content, err := os.ReadFile(configPath)
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIG_READ_ERROR",
 				fmt.Sprintf("Failed to read configuration file: %v", err),
 				"Ensure the file is readable and not corrupted",
-			), nil
+			)), nil
 		}
 
 		// Parse YAML
 		var config map[string]interface{}
 		if err := yaml.Unmarshal(content, &config); err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIG_PARSE_ERROR",
 				fmt.Sprintf("Failed to parse YAML: %v", err),
 				"Check YAML syntax - ensure proper indentation and structure",
-			), nil
+			)), nil
 		}
 
 		// Validate structure
@@ -274,11 +275,11 @@ func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
 			for _, err := range validationErrors {
 				errorMsg += fmt.Sprintf("- %s\n", err)
 			}
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIG_INVALID",
 				errorMsg,
 				"Fix the validation errors in your zerops.yml file",
-			), nil
+			)), nil
 		}
 
 		// Extract key information
@@ -325,24 +326,24 @@ func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(nginxTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, nginxTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		framework, err := request.RequireString("framework")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_FRAMEWORK",
 				"Framework name is required",
 				"Provide a framework name like 'laravel', 'symfony', or 'wordpress'",
-			), nil
+			)), nil
 		}
 
 		// Get nginx config from knowledge base
 		config, err := knowledge.GetNginxConfig(framework)
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIG_NOT_FOUND",
 				fmt.Sprintf("Failed to get nginx config: %v", err),
 				"Try using 'laravel', 'symfony', 'wordpress', or 'default'",
-			), nil
+			)), nil
 		}
 
 		var response strings.Builder
@@ -365,7 +366,13 @@ func RegisterConfigTools(s *server.MCPServer, client *api.Client) {
 		response.WriteString("- For Laravel: Document root should be /var/www/public\n")
 		response.WriteString("- For WordPress/Symfony: Document root is usually /var/www\n")
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"framework":        framework,
+			"file_name":        "site.conf.tmpl",
+			"site_config_path": "site.conf.tmpl",
+			"template":         config,
+			"next_step":        "Save the template as site.conf.tmpl, set run.siteConfigPath and include it in deployFiles",
+		}), nil
 	})
 }
 
@@ -2,115 +2,220 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/config"
+	"github.com/zeropsio/zerops-mcp-v3/internal/deploy"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
+	"github.com/zeropsio/zerops-mcp-v3/internal/lock"
+	"github.com/zeropsio/zerops-mcp-v3/internal/vpnhelper"
 	"github.com/zeropsio/zerops-mcp-v3/internal/zcli"
 )
 
 // RegisterDeployTools registers all deployment tools
-func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *zcli.ZCLIWrapper) {
+func RegisterDeployTools(s *server.MCPServer, client api.ZeropsAPI, zcliWrapper *zcli.ZCLIWrapper, deployBackend string, logRules *knowledge.LogRuleSet) {
+	if deployBackend == "" {
+		deployBackend = config.DeployBackendAPI
+	}
+
+	// Serializes VPN transitions and deployments across parallel tool calls
+	locks := lock.NewManager()
+
 	// vpn_status
 	vpnStatusTool := mcp.NewTool(
 		"vpn_status",
-		mcp.WithDescription("Check VPN connection status"),
+		mcp.WithDescription("Check VPN connection status: tunnel interface, handshake freshness, the project the tunnel belongs to and whether project services resolve through the VPN's DNS"),
 		mcp.WithBoolean("detailed",
 			mcp.Description("Show detailed status information (default: false)"),
 		),
+		mcp.WithBoolean("probe",
+			mcp.Description("Resolve a service hostname of the connected project to verify the tunnel works (default: true)"),
+		),
 	)
 
-	s.AddTool(vpnStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, vpnStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		detailed := request.GetBool("detailed", false)
+		probe := request.GetBool("probe", true)
 
 		// Check if zcli is installed
 		if !zcliWrapper.IsInstalled() {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewDeploymentError(
 				"ZCLI_NOT_INSTALLED",
 				"zcli is not installed",
 				"Install zcli from https://docs.zerops.io/cli/installation/",
-			), nil
+			)), nil
 		}
 
 		// Get VPN status
-		connected, projectID, message := zcliWrapper.VPNStatus(ctx)
+		tunnel := zcliWrapper.Tunnel(ctx)
+		connected := tunnel.Up
+		projectID := ""
+		if connected && tunnel.Session != nil {
+			projectID = tunnel.Session.ProjectID
+		}
 
 		// Build response
 		var response strings.Builder
 		response.WriteString("VPN Status:\n\n")
 
-		if connected {
+		if connected && tunnel.Stale {
+			response.WriteString("⚠️ Connected, but the tunnel looks stale\n")
+		} else if connected {
 			response.WriteString("✅ Connected\n")
-			if projectID != "" {
-				response.WriteString(fmt.Sprintf("Project ID: %s\n", projectID))
-			}
+		} else if tunnel.State == zcli.TunnelUnknown {
+			response.WriteString("❔ Unknown (the tunnel cannot be inspected)\n")
 		} else {
 			response.WriteString("❌ Not connected\n")
 		}
+		if projectID != "" {
+			response.WriteString(fmt.Sprintf("Project ID: %s\n", projectID))
+		}
+		if tunnel.Interface != "" {
+			response.WriteString(fmt.Sprintf("Interface: %s\n", tunnel.Interface))
+		}
+		if tunnel.LastHandshake != nil {
+			response.WriteString(fmt.Sprintf("Last handshake: %s ago\n", time.Since(*tunnel.LastHandshake).Round(time.Second)))
+		}
 
+		// How vpn_connect and vpn_disconnect can run zcli as root
+		privilege := zcliWrapper.VPNPrivilege(ctx)
+		switch privilege {
+		case zcli.PrivilegeHelper:
+			response.WriteString(fmt.Sprintf("VPN control: zerops-vpn-helper (%s)\n", zcliWrapper.VPNHelperSocket()))
+		case zcli.PrivilegeRoot:
+			response.WriteString("VPN control: running as root\n")
+		case zcli.PrivilegeSudo:
+			response.WriteString("VPN control: passwordless sudo\n")
+		default:
+			response.WriteString("VPN control: ⚠️ unavailable (no helper, sudo needs a password)\n")
+		}
+		vpnLogin := zcliWrapper.VPNLogin(ctx, privilege)
+		if vpnLogin != "" {
+			response.WriteString(fmt.Sprintf("VPN login: %s\n", vpnLogin))
+		}
+
+		status := map[string]interface{}{
+			"connected":  connected,
+			"state":      tunnel.State,
+			"project_id": projectID,
+			"stale":      tunnel.Stale,
+			"tunnel":     tunnel,
+			"privilege":  privilege,
+			"vpn_login":  vpnLogin,
+		}
+
+		// Resolve a service of the project through the VPN's DNS
+		if probe && projectID != "" {
+			if hostname := probeHostname(ctx, client, projectID); hostname != "" {
+				result := zcliWrapper.ProbeDNS(ctx, hostname)
+				status["dns_probe"] = result
+				if result.OK() {
+					response.WriteString(fmt.Sprintf("DNS: ✅ %s resolves to %s\n", result.Hostname, strings.Join(result.Addresses, ", ")))
+				} else {
+					response.WriteString(fmt.Sprintf("DNS: ❌ %s does not resolve (%s)\n", hostname, result.Error))
+				}
+			}
+		}
+
+		message := tunnel.Message()
 		if detailed {
 			response.WriteString(fmt.Sprintf("\nDetails: %s\n", message))
-			
-			// Check zcli version
-			version, err := zcliWrapper.Version(ctx)
-			if err == nil {
-				response.WriteString(fmt.Sprintf("\nzcli version: %s\n", version))
+			status["details"] = message
+			if tunnel.Method != "" {
+				response.WriteString(fmt.Sprintf("Detected via: %s\n", tunnel.Method))
+			}
+			if tunnel.Session != nil {
+				response.WriteString(fmt.Sprintf("Session: project %s since %s\n", tunnel.Session.ProjectID, tunnel.Session.ConnectedAt.Format(time.RFC3339)))
+			}
+
+			// Check zcli version against the supported releases
+			if support, err := zcliWrapper.VersionSupport(ctx); err == nil {
+				response.WriteString(fmt.Sprintf("\nzcli version: %s (%s)\n", support.Version, support.Status))
+				if support.Status != zcli.VersionSupported {
+					response.WriteString(fmt.Sprintf("⚠️ %s\n", support.Message()))
+				}
+				status["zcli_version"] = support.Version
+				status["zcli_support"] = support
 			}
 		}
 
 		response.WriteString("\nNext steps:\n")
-		if connected {
-			response.WriteString("- Use 'deploy_push' to deploy your application\n")
+		if connected && tunnel.Stale {
+			response.WriteString("- Use 'vpn_connect' to re-establish the tunnel\n")
+		} else if tunnel.State == zcli.TunnelUnknown {
+			response.WriteString("- Use 'vpn_connect' to reconnect and record the tunnel interface\n")
+		} else if connected {
 			response.WriteString("- Use 'vpn_disconnect' to disconnect VPN\n")
 		} else {
 			response.WriteString("- Use 'vpn_connect' with a project ID to connect\n")
 			response.WriteString("- Use 'project_list' to see available projects\n")
 		}
+		if privilege == zcli.PrivilegeNone {
+			response.WriteString("- Start zerops-vpn-helper as root or allow passwordless sudo for zcli before connecting\n")
+		}
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), status), nil
 	})
 
 	// vpn_connect
 	vpnConnectTool := mcp.NewTool(
 		"vpn_connect",
-		mcp.WithDescription("Connect to Zerops VPN (requires sudo password)"),
+		mcp.WithDescription("Connect to Zerops VPN (runs through zerops-vpn-helper, passwordless sudo or as root; never prompts for a password)"),
 		mcp.WithString("project_id",
 			mcp.Required(),
 			mcp.Description("Project ID to connect to"),
 		),
 	)
 
-	s.AddTool(vpnConnectTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, vpnConnectTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectID, err := request.RequireString("project_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_ID",
 				"Project ID is required",
 				"Provide a valid project ID from 'project_list' tool",
-			), nil
+			)), nil
 		}
 
 		// Check if zcli is installed
 		if !zcliWrapper.IsInstalled() {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewDeploymentError(
 				"ZCLI_NOT_INSTALLED",
 				"zcli is not installed",
 				"Install zcli from https://docs.zerops.io/cli/installation/",
-			), nil
+			)), nil
+		}
+
+		// VPN transitions run one at a time and wait for running zcli deploys
+		release, errResult := acquireLock(ctx, locks, newNotifier(ctx, request, "vpn_connect"), vpnLockKey, lock.Exclusive, "vpn_connect", projectID)
+		if errResult != nil {
+			return errResult, nil
+		}
+		defer release()
+
+		// VPN commands run as root; fail fast rather than wait for a password prompt
+		privilege := zcliWrapper.VPNPrivilege(ctx)
+		if privilege == zcli.PrivilegeNone {
+			return ToolErrorResponse(vpnPrivilegeError(zcliWrapper, "up --projectId "+projectID)), nil
 		}
 
 		// Track if we're doing an automatic reconnection
 		var previousProjectInfo string
 		
-		// Check if already connected
-		if zcliWrapper.IsVPNConnected(ctx) {
+		// Check if already connected; a stale or unknown tunnel is reconnected
+		if tunnel := zcliWrapper.Tunnel(ctx); tunnel.State != zcli.TunnelDown {
 			connectedID, _ := zcliWrapper.GetConnectedProjectID(ctx)
-			if connectedID == projectID {
+			if connectedID == projectID && tunnel.Up && !tunnel.Stale {
 				// Already connected to the same project - this is success, not error
 				return SuccessResponse(map[string]interface{}{
 					"message":     fmt.Sprintf("Already connected to project %s", projectID),
@@ -125,13 +230,14 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			// Get the previous project info for logging
 			previousProjectInfo = fmt.Sprintf("(was connected to %s)", connectedID)
 			
-			// Automatically disconnect and connect to new project
-			if err := zcliWrapper.VPNDisconnect(ctx); err != nil {
-				return ErrorResponse(
+			// Automatically disconnect and connect to new project. A tunnel of
+			// unknown state may already be gone, so only a known one must go down.
+			if err := zcliWrapper.VPNDisconnect(ctx); err != nil && tunnel.State != zcli.TunnelUnknown {
+				return ToolErrorResponse(zerrors.NewVPNError(
 					"VPN_DISCONNECT_FAILED",
 					fmt.Sprintf("Failed to disconnect from current project: %v", err),
 					"Try manually running 'vpn_disconnect' first",
-				), nil
+				)), nil
 			}
 			
 			// Small delay to ensure clean disconnect
@@ -144,36 +250,27 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		// Verify project exists
 		project, err := client.GetProject(ctx, projectID)
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"PROJECT_NOT_FOUND",
 				fmt.Sprintf("Project %s not found", projectID),
 				"Verify the project ID with 'project_list' tool",
-			), nil
+			)), nil
 		}
 
 		// Connect VPN
 		if err := zcliWrapper.VPNConnect(ctx, projectID); err != nil {
 			// Check for common errors
-			if strings.Contains(err.Error(), "sudo") || strings.Contains(err.Error(), "password") {
-				return ErrorResponse(
-					"SUDO_REQUIRED",
-					"VPN connection requires sudo password",
-					"Run this tool from a terminal with sudo access, or configure passwordless sudo for zcli",
-				), nil
-			}
-			if strings.Contains(err.Error(), "already connected") {
-				return ErrorResponseWithNext(
-					"VPN_ALREADY_CONNECTED",
-					"VPN is already connected",
-					"Use 'vpn_disconnect' first to disconnect",
-					"vpn_disconnect",
-				), nil
-			}
-			return ErrorResponse(
+			if errors.Is(err, zcli.ErrPrivilegeRequired) {
+				return ToolErrorResponse(vpnPrivilegeError(zcliWrapper, "up --projectId "+projectID)), nil
+			}
+			if failure, ok := zcli.Classify(err); ok {
+				return ToolErrorResponse(failure.ToolError(err)), nil
+			}
+			return ToolErrorResponse(zerrors.NewVPNError(
 				"VPN_CONNECTION_FAILED",
 				fmt.Sprintf("Failed to connect VPN: %v", err),
 				"Check your network connection and try again. Ensure zcli is properly configured",
-			), nil
+			)), nil
 		}
 
 		// Check if we did an automatic reconnection
@@ -183,6 +280,9 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			"project_name": project.Name,
 			"next_step":   "Use 'deploy_push' to deploy your application",
 		}
+		if vpnLogin := zcliWrapper.VPNLogin(ctx, privilege); vpnLogin != "" {
+			successData["vpn_login"] = vpnLogin
+		}
 		
 		// Customize message based on whether we auto-reconnected
 		if previousProjectInfo != "" {
@@ -198,21 +298,27 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 	// vpn_disconnect
 	vpnDisconnectTool := mcp.NewTool(
 		"vpn_disconnect",
-		mcp.WithDescription("Disconnect from Zerops VPN (requires sudo password)"),
+		mcp.WithDescription("Disconnect from Zerops VPN (runs through zerops-vpn-helper, passwordless sudo or as root; never prompts for a password)"),
 	)
 
-	s.AddTool(vpnDisconnectTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, vpnDisconnectTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Check if zcli is installed
 		if !zcliWrapper.IsInstalled() {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewDeploymentError(
 				"ZCLI_NOT_INSTALLED",
 				"zcli is not installed",
 				"Install zcli from https://docs.zerops.io/cli/installation/",
-			), nil
+			)), nil
+		}
+
+		release, errResult := acquireLock(ctx, locks, newNotifier(ctx, request, "vpn_disconnect"), vpnLockKey, lock.Exclusive, "vpn_disconnect", "")
+		if errResult != nil {
+			return errResult, nil
 		}
+		defer release()
 
-		// Check if connected
-		if !zcliWrapper.IsVPNConnected(ctx) {
+		// Check if connected; stale and unknown tunnels can still be taken down
+		if zcliWrapper.Tunnel(ctx).State == zcli.TunnelDown {
 			return InfoResponse(
 				"VPN Status",
 				"VPN is not connected",
@@ -225,18 +331,17 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 
 		// Disconnect VPN
 		if err := zcliWrapper.VPNDisconnect(ctx); err != nil {
-			if strings.Contains(err.Error(), "sudo") || strings.Contains(err.Error(), "password") {
-				return ErrorResponse(
-					"SUDO_REQUIRED",
-					"VPN disconnection requires sudo password",
-					"Run this tool from a terminal with sudo access",
-				), nil
-			}
-			return ErrorResponse(
+			if errors.Is(err, zcli.ErrPrivilegeRequired) {
+				return ToolErrorResponse(vpnPrivilegeError(zcliWrapper, "down")), nil
+			}
+			if failure, ok := zcli.Classify(err); ok {
+				return ToolErrorResponse(failure.ToolError(err)), nil
+			}
+			return ToolErrorResponse(zerrors.NewVPNError(
 				"VPN_DISCONNECT_FAILED",
 				fmt.Sprintf("Failed to disconnect VPN: %v", err),
 				"Try running 'sudo zcli vpn down' manually",
-			), nil
+			)), nil
 		}
 
 		response := map[string]interface{}{
@@ -264,35 +369,52 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		),
 	)
 
-	s.AddTool(deployValidateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, deployValidateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		workDir := request.GetString("working_dir", ".")
 		configPath := request.GetString("config_path", "")
 
-		// Default config path
+		// Default config path; a relative one is inside the working directory, as for deploy_push
 		if configPath == "" {
 			configPath = filepath.Join(workDir, "zerops.yml")
+		} else if !filepath.IsAbs(configPath) {
+			configPath = filepath.Join(workDir, configPath)
 		}
 
-		// Check if zcli is installed
-		if !zcliWrapper.IsInstalled() {
-			return ErrorResponse(
+		// Only the zcli backend needs zcli and the VPN
+		useZCLI := deployBackend == config.DeployBackendZCLI
+		if useZCLI && !zcliWrapper.IsInstalled() {
+			return ToolErrorResponse(zerrors.NewDeploymentError(
 				"ZCLI_NOT_INSTALLED",
 				"zcli is not installed",
 				"Install zcli from https://docs.zerops.io/cli/installation/",
-			), nil
+			)), nil
 		}
 
 		var issues []string
 		var warnings []string
 
 		// Check working directory exists
+		fileCount := 0
 		if _, err := os.Stat(workDir); os.IsNotExist(err) {
 			issues = append(issues, fmt.Sprintf("Working directory '%s' does not exist", workDir))
+		} else if !useZCLI {
+			files, err := deploy.Files(workDir)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("Working directory '%s' cannot be packaged: %v", workDir, err))
+			}
+			for _, file := range files {
+				if file.Info.Mode().IsRegular() {
+					fileCount++
+				}
+			}
+			if err == nil && fileCount == 0 {
+				issues = append(issues, fmt.Sprintf("No files to deploy in '%s' (check .gitignore and .deployignore)", workDir))
+			}
 		}
 
 		// Check for git repository
 		gitDir := filepath.Join(workDir, ".git")
-		if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+		if _, err := os.Stat(gitDir); useZCLI && os.IsNotExist(err) {
 			warnings = append(warnings, "Working directory is not a git repository (deployment works better with git)")
 		}
 
@@ -307,8 +429,34 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		}
 
 		// Check VPN connection
-		if !zcliWrapper.IsVPNConnected(ctx) {
-			issues = append(issues, "VPN is not connected")
+		var vpnConnected bool
+		if useZCLI {
+			tunnel := zcliWrapper.Tunnel(ctx)
+			vpnConnected = tunnel.Up && !tunnel.Stale
+			switch {
+			case tunnel.State == zcli.TunnelUnknown:
+				warnings = append(warnings, tunnel.Message())
+			case !vpnConnected:
+				issues = append(issues, tunnel.Message())
+			}
+		}
+
+		// Check the zcli version and that it deploys as the server's account
+		var zcliSupport zcli.VersionSupport
+		if useZCLI {
+			zcliSupport, _ = zcliWrapper.VersionSupport(ctx)
+			switch zcliSupport.Status {
+			case zcli.VersionUnsupported:
+				issues = append(issues, zcliSupport.Message())
+			case zcli.VersionSupported:
+			default:
+				warnings = append(warnings, zcliSupport.Message())
+			}
+			if login, err := zcliWrapper.EnsureLogin(ctx); err != nil {
+				issues = append(issues, fmt.Sprintf("zcli login failed: %v", err))
+			} else if !login.Isolated || login.Refreshed {
+				warnings = append(warnings, login.Message())
+			}
 		}
 
 		// Build response
@@ -320,7 +468,16 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			response.WriteString("Ready to deploy:\n")
 			response.WriteString(fmt.Sprintf("- Working directory: %s\n", workDir))
 			response.WriteString(fmt.Sprintf("- Config file: %s\n", configPath))
-			response.WriteString("- VPN: Connected\n")
+			if useZCLI && vpnConnected {
+				response.WriteString("- VPN: Connected\n")
+			} else if useZCLI {
+				response.WriteString("- VPN: Unknown\n")
+			}
+			if useZCLI {
+				response.WriteString(fmt.Sprintf("- zcli: %s (%s)\n", zcliSupport.Version, zcliSupport.Status))
+			} else {
+				response.WriteString(fmt.Sprintf("- Files to deploy: %d\n", fileCount))
+			}
 			
 			if len(warnings) > 0 {
 				response.WriteString("\n⚠️ Warnings:\n")
@@ -331,37 +488,57 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			
 			response.WriteString("\nNext step: Use 'deploy_push' to deploy your application\n")
 		} else {
-			response.WriteString("❌ Validation failed:\n\n")
+			var message strings.Builder
+			message.WriteString("Deployment validation failed:\n")
 			for i, issue := range issues {
-				response.WriteString(fmt.Sprintf("%d. %s\n", i+1, issue))
+				message.WriteString(fmt.Sprintf("%d. %s\n", i+1, issue))
 			}
-			
-			response.WriteString("\nResolution steps:\n")
+
+			var steps []string
 			for _, issue := range issues {
 				if strings.Contains(issue, "VPN") {
-					response.WriteString("- Use 'vpn_connect' to connect to VPN\n")
+					steps = append(steps, "- Use 'vpn_connect' to connect to VPN")
 				}
 				if strings.Contains(issue, "zerops.yml") {
-					response.WriteString("- Create a zerops.yml configuration file\n")
-					response.WriteString("- Use 'config_generate' to create from template\n")
+					steps = append(steps, "- Create a zerops.yml configuration file", "- Use 'knowledge_search_patterns' or 'config_templates' to start from a tested template")
 				}
 				if strings.Contains(issue, "directory") {
-					response.WriteString("- Ensure you're in the correct directory\n")
-					response.WriteString("- Provide the correct working_dir parameter\n")
+					steps = append(steps, "- Ensure you're in the correct directory", "- Provide the correct working_dir parameter")
+				}
+				if strings.Contains(issue, "zcli") {
+					steps = append(steps, "- Install a supported zcli release (v1.x) from https://docs.zerops.io/cli/installation/", "- Check the API key with 'auth_validate'")
 				}
 			}
+
+			return ToolErrorResponse(zerrors.NewDeploymentError(
+				"DEPLOY_VALIDATION_FAILED",
+				strings.TrimSpace(message.String()),
+				strings.Join(steps, "\n"),
+			).WithMetadata("issues", issues)), nil
 		}
 
-		if len(issues) > 0 {
-			return mcp.NewToolResultError(response.String()), nil
+		data := map[string]interface{}{
+			"valid":       true,
+			"working_dir": workDir,
+			"config_path": configPath,
+			"backend":     deployBackend,
+			"warnings":    append([]string{}, warnings...),
+			"next_step":   "Use 'deploy_push' to deploy your application",
 		}
-		return mcp.NewToolResultText(response.String()), nil
+		if useZCLI {
+			data["vpn_connected"] = vpnConnected
+			data["zcli_version"] = zcliSupport.Version
+			data["zcli_status"] = zcliSupport.Status
+		} else {
+			data["file_count"] = fileCount
+		}
+		return StructuredResponse(response.String(), data), nil
 	})
 
 	// deploy_push
 	deployPushTool := mcp.NewTool(
 		"deploy_push",
-		mcp.WithDescription("Deploy application to Zerops. IMPORTANT: The service_name parameter is usually required unless your zerops.yml has only one service"),
+		mcp.WithDescription("Deploy application to Zerops: packages the working directory (honoring .deployignore and .gitignore), uploads it and starts the build. Returns the process ID. IMPORTANT: The service_name parameter is usually required unless your zerops.yml has only one service"),
 		mcp.WithString("project_id",
 			mcp.Required(),
 			mcp.Description("Project ID to deploy to (get from project_list)"),
@@ -375,141 +552,94 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		mcp.WithString("config_path",
 			mcp.Description("Path to zerops.yml configuration file (default: zerops.yml in working directory)"),
 		),
+		mcp.WithString("version_name",
+			mcp.Description("Optional name of the created app version (e.g. a git tag)"),
+		),
 	)
 
-	s.AddTool(deployPushTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, deployPushTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectID := request.GetString("project_id", "")
 		serviceName := request.GetString("service_name", "")
 		workDir := request.GetString("working_dir", ".")
 		configPath := request.GetString("config_path", "")
-		
-		// Check prerequisites
-		if !zcliWrapper.IsInstalled() {
-			return ErrorResponse(
-				"ZCLI_NOT_INSTALLED",
-				"zcli is not installed",
-				"Install zcli from https://docs.zerops.io/cli/installation/",
-			), nil
-		}
-
-		if !zcliWrapper.IsVPNConnected(ctx) {
-			return ErrorResponseWithNext(
-				"VPN_NOT_CONNECTED",
-				"VPN is not connected",
-				"Connect to VPN first before deploying",
-				"vpn_connect",
-			), nil
-		}
+		versionName := request.GetString("version_name", "")
 
 		// If project ID is not provided, we need to ask for it
 		if projectID == "" {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"PROJECT_ID_REQUIRED",
 				"Project ID is required for deployment",
 				"Provide the project ID using the 'project_id' parameter. You can get it from 'project_list' tool",
-			), nil
+			)), nil
 		}
 
-		// Execute deployment
-		output, err := zcliWrapper.Push(ctx, projectID, serviceName, workDir, configPath)
+		// Deploys of the same service run one at a time
+		n := newNotifier(ctx, request, "deploy_push")
+		target := deployTarget(serviceName, workDir, configPath)
+		release, errResult := acquireDeployLocks(ctx, locks, n, projectID, target, fmt.Sprintf("%s (%s backend)", target, deployBackend))
+		if errResult != nil {
+			return errResult, nil
+		}
+		keepLocks := false
+		defer func() {
+			if !keepLocks {
+				release()
+			}
+		}()
+
+		if deployBackend == config.DeployBackendZCLI {
+			// zcli pushes over the VPN, which must not change while they run
+			releaseVPN, errResult := acquireLock(ctx, locks, n, vpnLockKey, lock.Shared, "deploy_push", target)
+			if errResult != nil {
+				return errResult, nil
+			}
+			defer releaseVPN()
+			return pushWithZCLI(ctx, client, zcliWrapper, n, projectID, serviceName, workDir, configPath), nil
+		}
+
+		result, err := deploy.Push(ctx, client, deploy.PushOptions{
+			ProjectID:   projectID,
+			ServiceName: serviceName,
+			WorkDir:     workDir,
+			ConfigPath:  configPath,
+			VersionName: versionName,
+		})
 		if err != nil {
-			// Parse common errors
-			if strings.Contains(output, "Please, select a service") || strings.Contains(output, "Interactive selection can be used only in terminal mode") {
-				// Get the services from the project to help the user
-				services, listErr := client.ListServices(ctx, projectID)
-				var serviceHelp strings.Builder
-				serviceHelp.WriteString("Service selection is required for deployment.\n\n")
-				
-				if listErr == nil && len(services) > 0 {
-					serviceHelp.WriteString("Available services in your project:\n")
-					for _, svc := range services {
-						serviceHelp.WriteString(fmt.Sprintf("- %s (type: %s@%s)\n", svc.Name, svc.ServiceStackTypeInfo.ServiceStackTypeName, svc.ServiceStackTypeInfo.ServiceStackTypeVersionName))
-					}
-					serviceHelp.WriteString("\nUse the service_name parameter with one of the above service names (hostnames).\n")
-					serviceHelp.WriteString("Example: deploy_push(project_id=\"...\", service_name=\"app\", ...)")
-				} else {
-					serviceHelp.WriteString("Use 'service_list' tool to see available services in your project.\n")
-					serviceHelp.WriteString("Then provide the service_name parameter matching a service hostname from your zerops.yml.")
-				}
-				
-				return ErrorResponse(
-					"SERVICE_SELECTION_REQUIRED",
-					"Service name must be specified for deployment",
-					serviceHelp.String(),
-				), nil
-			}
-			if strings.Contains(err.Error(), "exit status 128") || strings.Contains(output, "exit status 128") {
-				return ErrorResponse(
-					"GIT_ERROR",
-					"Git repository error (exit status 128)",
-					"Ensure your git repository has at least one commit:\n1. git add .\n2. git commit -m \"Initial commit\"\n\nNote: zcli requires a git repository with commits, not just 'git init'",
-				), nil
-			}
-			if strings.Contains(output, "websocket: bad handshake") {
-				// This might not be a real failure - check if deployment actually succeeded
-				return ErrorResponse(
-					"LOG_STREAMING_ERROR", 
-					"Log streaming error during deployment",
-					"This is usually a temporary issue with log streaming. The deployment may still succeed.\nCheck deployment status with 'deploy_status' tool or check service logs",
-				), nil
-			}
-			if strings.Contains(output, "projectWillBeDeleted") || strings.Contains(output, "No action allowed") {
-				return ErrorResponse(
-					"PROJECT_NOT_READY",
-					"Project not ready for deployment",
-					"The project might be initializing or marked for deletion. Wait a moment and try again.\nEnsure services are fully initialized after import (wait 30+ seconds)",
-				), nil
-			}
-			if strings.Contains(err.Error(), "zerops.yml") || strings.Contains(err.Error(), "config") {
-				return ErrorResponse(
-					"CONFIG_ERROR",
-					"Configuration error",
-					"Check your zerops.yml file for syntax errors or missing required fields",
-				), nil
-			}
-			if strings.Contains(err.Error(), "VPN") || strings.Contains(err.Error(), "connection") {
-				return ErrorResponseWithNext(
-					"CONNECTION_ERROR",
-					"Connection error during deployment",
-					"Ensure VPN is connected and stable",
-					"vpn_status",
-				), nil
-			}
-			if strings.Contains(err.Error(), "service") {
-				return ErrorResponse(
-					"SERVICE_ERROR",
-					"Service configuration error",
-					"Ensure the service name in zerops.yml matches an existing service in your project",
-				), nil
-			}
-
-			return ErrorResponse(
-				"DEPLOY_FAILED",
-				fmt.Sprintf("Deployment failed: %v", err),
-				fmt.Sprintf("Check the error details:\n%s\n\nUse 'deploy_troubleshoot' for help", output),
-			), nil
+			var toolErr *zerrors.ToolError
+			if errors.As(err, &toolErr) {
+				return ToolErrorResponse(toolErr), nil
+			}
+			return HandleAPIError(err), nil
 		}
 
-		// Parse output for success indicators
+		// The build runs after the call returns; the next deploy of the service waits for it
+		keepLocks = true
+		go releaseWhenFinished(context.WithoutCancel(ctx), client, result.Process.ID, release)
+
 		var response strings.Builder
 		response.WriteString("Deployment initiated successfully!\n\n")
-		response.WriteString("Deployment progress:\n")
-		
-		// Include relevant output
-		lines := strings.Split(output, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" && !strings.Contains(line, "Using config file") {
-				response.WriteString(fmt.Sprintf("- %s\n", line))
-			}
-		}
+		response.WriteString(fmt.Sprintf("Service: %s (%s)\n", result.Service.Name, result.Service.ID))
+		response.WriteString(fmt.Sprintf("Setup: %s from %s\n", result.Setup, result.ConfigPath))
+		response.WriteString(fmt.Sprintf("Archive: %d files, %s\n", result.Files, formatBytes(result.Size)))
+		response.WriteString(fmt.Sprintf("App version: %s\n", result.AppVersion.ID))
+		response.WriteString(fmt.Sprintf("Process ID: %s (status: %s)\n", result.Process.ID, result.Process.Status))
 
 		response.WriteString("\nNext steps:\n")
-		response.WriteString("- Use 'deploy_status' to check deployment progress\n")
+		response.WriteString(fmt.Sprintf("- Use 'process_status' with process_id=%s to follow the build\n", result.Process.ID))
 		response.WriteString("- Use 'deploy_logs' to view build logs\n")
 		response.WriteString("- Use 'service_logs' to view runtime logs\n")
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"backend":      config.DeployBackendAPI,
+			"processId":    result.Process.ID,
+			"appVersionId": result.AppVersion.ID,
+			"serviceId":    result.Service.ID,
+			"setup":        result.Setup,
+			"configPath":   result.ConfigPath,
+			"files":        result.Files,
+			"size":         result.Size,
+			"process":      result.Process,
+		}), nil
 	})
 
 	// deploy_status
@@ -525,14 +655,14 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		),
 	)
 
-	s.AddTool(deployStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, deployStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		detailed := request.GetBool("detailed", false)
@@ -571,6 +701,15 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			response.WriteString(fmt.Sprintf("- Last Update: %s\n", service.LastUpdate.Format("2006-01-02 15:04:05")))
 		}
 
+		var nextSteps []string
+		if service.Status == "BUILDING" || service.Status == "DEPLOYING" {
+			nextSteps = []string{"deploy_logs"}
+		} else if service.Status == "FAILED" {
+			nextSteps = []string{"deploy_logs", "deploy_troubleshoot"}
+		} else if service.Status == "RUNNING" {
+			nextSteps = []string{"service_logs", "service_info"}
+		}
+
 		response.WriteString("\nNext steps:\n")
 		if service.Status == "BUILDING" || service.Status == "DEPLOYING" {
 			response.WriteString("- Use 'deploy_logs' to view build progress\n")
@@ -583,76 +722,224 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			response.WriteString("- Use 'service_info' for detailed information\n")
 		}
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"service_id":   service.ID,
+			"service_name": service.Name,
+			"status":       service.Status,
+			"type":         service.ServiceStackTypeInfo.ServiceStackTypeVersionName,
+			"mode":         service.Mode,
+			"created":      service.Created,
+			"last_update":  service.LastUpdate,
+			"next_tools":   nextSteps,
+		}), nil
 	})
 
 	// deploy_logs
 	deployLogsTool := mcp.NewTool(
 		"deploy_logs",
-		mcp.WithDescription("Get deployment/build logs for a service"),
+		mcp.WithDescription("Get the build pipeline logs of a service's deployment, with the pipeline phases, the failed step and its exit line"),
 		mcp.WithString("service_id",
 			mcp.Required(),
 			mcp.Description("Service ID to get deployment logs for"),
 		),
+		mcp.WithString("app_version_id",
+			mcp.Description("App version (deployment) to inspect (default: latest)"),
+		),
 		mcp.WithNumber("limit",
 			mcp.Description("Number of log lines to retrieve (default: 100, max: 1000)"),
 		),
 	)
 
-	s.AddTool(deployLogsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, deployLogsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		limit := request.GetInt("limit", 100)
-		if limit > 1000 {
-			limit = 1000
+		if limit <= 0 {
+			limit = 100
 		}
+		if limit > api.MaxLogLimit {
+			limit = api.MaxLogLimit
+		}
+		versionID := strings.TrimSpace(request.GetString("app_version_id", ""))
 
-		// Get build/deployment logs
-		// Using "prepare" container which typically contains build logs
-		logs, err := client.GetServiceLogs(ctx, serviceID, "prepare", limit, "")
+		versions, err := client.ListAppVersions(ctx, serviceID, 20)
 		if err != nil {
-			// Try runtime logs as fallback
-			logs, err = client.GetServiceLogs(ctx, serviceID, "", limit, "")
+			return HandleAPIError(err), nil
+		}
+		if len(versions) == 0 {
+			var response strings.Builder
+			response.WriteString(fmt.Sprintf("No deployments found for service %s.\n", serviceID))
+			response.WriteString("\nNext steps:\n")
+			response.WriteString("- Use 'deploy_push' to deploy the service\n")
+			response.WriteString("- Use 'service_logs' for runtime logs\n")
+			return StructuredResponse(response.String(), map[string]interface{}{
+				"service_id":  serviceID,
+				"app_version": nil,
+				"next_tools":  []string{"deploy_push", "service_logs"},
+			}), nil
+		}
+
+		index := 0
+		if versionID != "" {
+			index = -1
+			for i, v := range versions {
+				if v.ID == versionID {
+					index = i
+					break
+				}
+			}
+			if index < 0 {
+				return ToolErrorResponse(zerrors.NewValidationError(
+					"APP_VERSION_NOT_FOUND",
+					fmt.Sprintf("App version '%s' is not among the recent deployments of service %s", versionID, serviceID),
+					"Omit 'app_version_id' to inspect the latest deployment",
+				)), nil
+			}
+		}
+		version := versions[index]
+		hasBuild := version.Build != nil && version.Build.ServiceStackID != ""
+
+		var buildEntries []api.LogEntry
+		if hasBuild {
+			buildEntries, err = client.GetBuildLogs(ctx, version, api.LogQuery{Limit: limit})
 			if err != nil {
-				return ErrorResponse(
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"LOGS_NOT_AVAILABLE",
-					"Deployment logs not available",
+					fmt.Sprintf("Build logs not available: %v", err),
 					"Logs might not be available yet for new deployments. Try again in a few moments",
-				), nil
+				)), nil
 			}
 		}
 
+		// Deploy and init commands run in the service's own containers, so their
+		// output is in the runtime log of the deployment's time window
+		var runtimeEntries []api.LogEntry
+		if !hasBuild || version.Status == "DEPLOY_FAILED" {
+			query := api.LogQuery{ServiceID: serviceID, Since: version.Created, Limit: limit}
+			if index > 0 {
+				query.Until = versions[index-1].Created
+			}
+			// Best effort: a failed first deployment may leave no runtime containers
+			runtimeEntries, _ = client.GetServiceLogs(ctx, query)
+		}
+
+		report := analyzeBuildLog(version, buildEntries, runtimeEntries)
+		serviceType := lookupServiceType(ctx, client, serviceID)
+		issues := append(
+			AnalyzeLogs(logRules, buildEntries, serviceType, knowledge.LogSourceBuild),
+			AnalyzeLogs(logRules, runtimeEntries, serviceType, knowledge.LogSourceRuntime)...,
+		)
+
 		// Build response
 		var response strings.Builder
 		response.WriteString(fmt.Sprintf("Deployment Logs for service %s:\n\n", serviceID))
+		response.WriteString(fmt.Sprintf("App version: %s (#%d)\n", version.ID, version.Sequence))
+		response.WriteString(fmt.Sprintf("Status: %s\n", version.Status))
+		response.WriteString(fmt.Sprintf("Created: %s\n", version.Created.Format("2006-01-02 15:04:05")))
+		if version.Build != nil {
+			for _, t := range []struct {
+				label string
+				at    *time.Time
+			}{
+				{"Pipeline started", version.Build.PipelineStart},
+				{"Pipeline finished", version.Build.PipelineFinish},
+				{"Pipeline failed", version.Build.PipelineFailed},
+			} {
+				if t.at != nil {
+					response.WriteString(fmt.Sprintf("%s: %s\n", t.label, t.at.Format("2006-01-02 15:04:05")))
+				}
+			}
+		}
 
-		if len(logs) == 0 {
-			response.WriteString("No deployment logs found.\n")
-			response.WriteString("\nPossible reasons:\n")
-			response.WriteString("- Service has not been deployed yet\n")
-			response.WriteString("- Logs have been rotated\n")
-			response.WriteString("- Service is still initializing\n")
-		} else {
-			for _, log := range logs {
-				response.WriteString(log)
+		response.WriteString("\nPipeline phases:\n")
+		for _, phase := range report.Phases {
+			icon := map[string]string{
+				"ok":          "✅",
+				"failed":      "❌",
+				"running":     "🔄",
+				"pending":     "⏳",
+				"not reached": "⏭️",
+			}[phase.Status]
+			response.WriteString(fmt.Sprintf("%s %s (%s): %s, %d lines\n", icon, phase.Name, phase.Step, phase.Status, phase.Lines))
+		}
+
+		if report.Failed {
+			response.WriteString(fmt.Sprintf("\n❌ Failed step: %s (%s)\n", report.FailedPhase, report.FailedStep))
+			if report.ExitLine != "" {
+				response.WriteString(fmt.Sprintf("Exit line: %s\n", report.ExitLine))
+			} else {
+				response.WriteString("Exit line: not found in the retrieved logs (try a higher 'limit')\n")
+			}
+			if report.ExitCode != 0 {
+				response.WriteString(fmt.Sprintf("Exit code: %d\n", report.ExitCode))
+			}
+		} else if version.Status != "ACTIVE" {
+			response.WriteString(fmt.Sprintf("\n🔄 Current phase: %s\n", report.Current))
+		}
+
+		if !hasBuild {
+			response.WriteString("\nThis deployment has no build pipeline log.\n")
+		}
+		for _, section := range []struct {
+			title   string
+			entries []api.LogEntry
+		}{
+			{"Build log", buildEntries},
+			{"Runtime log since deployment", runtimeEntries},
+		} {
+			if len(section.entries) == 0 {
+				continue
+			}
+			response.WriteString(fmt.Sprintf("\n%s (last %d lines):\n", section.title, len(section.entries)))
+			for _, line := range api.LogLines(section.entries) {
+				response.WriteString(line)
 				response.WriteString("\n")
 			}
-			response.WriteString(fmt.Sprintf("\nShowing last %d lines\n", len(logs)))
+		}
+		if analysis := FormatLogAnalysis(issues); analysis != "" {
+			response.WriteString(analysis)
+			response.WriteString("\n")
+		}
+		if len(buildEntries) == 0 && len(runtimeEntries) == 0 {
+			response.WriteString("\nNo deployment logs found.\n")
+			response.WriteString("\nPossible reasons:\n")
+			response.WriteString("- The build has not started yet\n")
+			response.WriteString("- Logs have been rotated\n")
 		}
 
+		var nextSteps []string
 		response.WriteString("\nNext steps:\n")
-		response.WriteString("- Use 'service_logs' for runtime logs\n")
-		response.WriteString("- Use 'deploy_status' to check deployment state\n")
-		response.WriteString("- Use 'deploy_troubleshoot' if deployment failed\n")
+		if report.Failed {
+			nextSteps = []string{"deploy_troubleshoot", "deploy_push"}
+			response.WriteString(fmt.Sprintf("- Fix the %s step in zerops.yml and redeploy with 'deploy_push'\n", report.FailedStep))
+			response.WriteString("- Use 'deploy_troubleshoot' for help\n")
+		} else {
+			nextSteps = []string{"deploy_status", "service_logs"}
+			response.WriteString("- Use 'deploy_status' to check deployment state\n")
+			response.WriteString("- Use 'service_logs' for runtime logs\n")
+		}
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"service_id":   serviceID,
+			"app_version":  version,
+			"phases":       report.Phases,
+			"failed":       report.Failed,
+			"failed_phase": report.FailedPhase,
+			"failed_step":  report.FailedStep,
+			"exit_line":    report.ExitLine,
+			"exit_code":    report.ExitCode,
+			"build_logs":   buildEntries,
+			"runtime_logs": runtimeEntries,
+			"issues":       issues,
+			"next_tools":   nextSteps,
+		}), nil
 	})
 
 	// deploy_troubleshoot
@@ -667,7 +954,7 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		),
 	)
 
-	s.AddTool(deployTroubleshootTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, deployTroubleshootTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID := request.GetString("service_id", "")
 		errorMsg := request.GetString("error_message", "")
 
@@ -696,17 +983,26 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 					if !zcliWrapper.IsInstalled() {
 						return false, "zcli is not installed"
 					}
-					version, _ := zcliWrapper.Version(ctx)
-					return true, fmt.Sprintf("zcli version: %s", version)
+					support, _ := zcliWrapper.VersionSupport(ctx)
+					if support.Status == zcli.VersionUnsupported {
+						return false, support.Message()
+					}
+					return true, fmt.Sprintf("zcli version: %s (%s)", support.Version, support.Status)
 				},
-				resolution: "Install zcli from https://docs.zerops.io/cli/installation/",
+				resolution: "Install a supported zcli release (v1.x) from https://docs.zerops.io/cli/installation/",
 			},
 		}
 
 		// Run checks
 		response.WriteString("System Checks:\n")
+		checks := make([]map[string]interface{}, 0, len(issues))
 		for _, issue := range issues {
 			ok, status := issue.check()
+			check := map[string]interface{}{"name": issue.title, "ok": ok, "status": status}
+			if !ok {
+				check["resolution"] = issue.resolution
+			}
+			checks = append(checks, check)
 			if ok {
 				response.WriteString(fmt.Sprintf("✅ %s: %s\n", issue.title, status))
 			} else {
@@ -716,13 +1012,21 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		}
 
 		// Check specific service if provided
+		var serviceData map[string]interface{}
 		if serviceID != "" {
 			response.WriteString(fmt.Sprintf("\nService Analysis (%s):\n", serviceID))
 			
 			service, err := client.GetService(ctx, serviceID)
 			if err != nil {
 				response.WriteString("❌ Could not retrieve service information\n")
+				serviceData = map[string]interface{}{"id": serviceID, "error": err.Error()}
 			} else {
+				serviceData = map[string]interface{}{
+					"id":     serviceID,
+					"name":   service.Name,
+					"status": service.Status,
+					"type":   service.ServiceStackTypeInfo.ServiceStackTypeVersionName,
+				}
 				response.WriteString(fmt.Sprintf("- Status: %s\n", service.Status))
 				response.WriteString(fmt.Sprintf("- Type: %s\n", service.ServiceStackTypeInfo.ServiceStackTypeVersionName))
 				
@@ -737,12 +1041,14 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		}
 
 		// Analyze error message if provided
+		detected := []string{}
 		if errorMsg != "" {
 			response.WriteString(fmt.Sprintf("\nError Analysis:\n"))
 			response.WriteString(fmt.Sprintf("Error: %s\n\n", errorMsg))
 			
 			// Common error patterns
 			if strings.Contains(strings.ToLower(errorMsg), "config") || strings.Contains(errorMsg, "zerops.yml") {
+				detected = append(detected, "configuration")
 				response.WriteString("📋 Configuration Issue Detected:\n")
 				response.WriteString("- Check zerops.yml syntax (YAML format)\n")
 				response.WriteString("- Ensure service names match existing services\n")
@@ -751,6 +1057,7 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			}
 			
 			if strings.Contains(strings.ToLower(errorMsg), "connection") || strings.Contains(strings.ToLower(errorMsg), "vpn") {
+				detected = append(detected, "network")
 				response.WriteString("🌐 Network Issue Detected:\n")
 				response.WriteString("- Check VPN connection: vpn_status\n")
 				response.WriteString("- Reconnect if needed: vpn_connect\n")
@@ -758,6 +1065,7 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 			}
 			
 			if strings.Contains(strings.ToLower(errorMsg), "permission") || strings.Contains(strings.ToLower(errorMsg), "denied") {
+				detected = append(detected, "permission")
 				response.WriteString("🔒 Permission Issue Detected:\n")
 				response.WriteString("- Ensure you have access to the project\n")
 				response.WriteString("- Check API key permissions\n")
@@ -779,6 +1087,339 @@ func RegisterDeployTools(s *server.MCPServer, client *api.Client, zcliWrapper *z
 		response.WriteString("- Incorrect working directory\n")
 		response.WriteString("- Build command failures\n")
 
-		return mcp.NewToolResultText(response.String()), nil
+		data := map[string]interface{}{
+			"checks":          checks,
+			"detected_issues": detected,
+			"next_step":       "Use 'deploy_validate' to check prerequisites and 'deploy_logs' to review the build",
+		}
+		if serviceData != nil {
+			data["service"] = serviceData
+		}
+		if errorMsg != "" {
+			data["error_message"] = errorMsg
+		}
+		return StructuredResponse(response.String(), data), nil
+	})
+
+	// deploy_queue
+	deployQueueTool := mcp.NewTool(
+		"deploy_queue",
+		mcp.WithDescription("Show running and queued VPN and deployment operations. VPN changes run one at a time and deploys of the same service are queued; use this when a call is waiting"),
+	)
+
+	addTool(s, deployQueueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tickets := locks.Snapshot()
+
+		running, queued := []lock.Ticket{}, []lock.Ticket{}
+		for _, t := range tickets {
+			if t.Running() {
+				running = append(running, t)
+			} else {
+				queued = append(queued, t)
+			}
+		}
+
+		if len(tickets) == 0 {
+			return StructuredResponse("No VPN or deployment operations are running or queued", map[string]interface{}{
+				"running": running,
+				"queued":  queued,
+			}), nil
+		}
+
+		now := time.Now()
+		var response strings.Builder
+		response.WriteString(fmt.Sprintf("Running (%d):\n", len(running)))
+		for _, t := range running {
+			response.WriteString(fmt.Sprintf("- %s %s [%s] for %s\n", t.Operation, t.Detail, t.Key, now.Sub(*t.Started).Round(time.Second)))
+		}
+		if len(queued) > 0 {
+			response.WriteString(fmt.Sprintf("\nQueued (%d):\n", len(queued)))
+			for _, t := range queued {
+				response.WriteString(fmt.Sprintf("- %s %s [%s] position %d, waiting %s\n", t.Operation, t.Detail, t.Key, t.Position, now.Sub(t.Enqueued).Round(time.Second)))
+			}
+		}
+
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"running": running,
+			"queued":  queued,
+		}), nil
+	})
+}
+
+// deployStage is a milestone reached by a zcli deployment
+type deployStage struct {
+	Stage   zcli.Stage `json:"stage"`
+	Message string     `json:"message"`
+	Line    string     `json:"line"`
+	Time    time.Time  `json:"time"`
+}
+
+// pushWithZCLI deploys through 'zcli service push', which needs zcli and the VPN.
+// zcli output is streamed to the client as progress notifications while it runs.
+func pushWithZCLI(ctx context.Context, client api.ZeropsAPI, zcliWrapper *zcli.ZCLIWrapper, n *notifier, projectID, serviceName, workDir, configPath string) *mcp.CallToolResult {
+	// Check prerequisites
+	if !zcliWrapper.IsInstalled() {
+		return ToolErrorResponse(zerrors.NewDeploymentError(
+			"ZCLI_NOT_INSTALLED",
+			"zcli is not installed",
+			"Install zcli from https://docs.zerops.io/cli/installation/",
+		))
+	}
+
+	// A tunnel that cannot be inspected is given the benefit of the doubt
+	if tunnel := zcliWrapper.Tunnel(ctx); tunnel.State == zcli.TunnelDown || tunnel.Stale {
+		return ToolErrorResponse(zerrors.NewVPNError(
+			"VPN_NOT_CONNECTED",
+			tunnel.Message(),
+			"Connect to VPN first before deploying",
+		).WithNextTool("vpn_connect"))
+	}
+
+	// zcli deploys as the account of the server's API key, not whoever ran 'zcli login'
+	login, err := zcliWrapper.EnsureLogin(ctx)
+	if err != nil {
+		return ToolErrorResponse(zerrors.NewDeploymentError(
+			"ZCLI_LOGIN_FAILED",
+			fmt.Sprintf("Failed to log zcli in with the server's API key: %v", err),
+			"Check the API key with 'auth_validate' and that the installed zcli is supported ('deploy_validate' reports its version)",
+		).WithNextTool("auth_validate"))
+	}
+	if login.Refreshed {
+		n.Log(mcp.LoggingLevelInfo, map[string]any{"login": login.Message()})
+	}
+
+	// Execute deployment, forwarding output and milestones as they happen
+	var stages []deployStage
+	output, err := zcliWrapper.PushStream(ctx, projectID, serviceName, workDir, configPath, func(p zcli.PushProgress) {
+		n.Progress(p.Line.Text)
+		if p.Stage == "" {
+			return
+		}
+		stages = append(stages, deployStage{
+			Stage:   p.Stage,
+			Message: p.Stage.Description(),
+			Line:    p.Line.Text,
+			Time:    time.Now(),
+		})
+		n.Log(mcp.LoggingLevelInfo, map[string]any{
+			"stage":   p.Stage,
+			"message": p.Stage.Description(),
+			"line":    p.Line.Text,
+		})
+	})
+	// stdout and stderr are read concurrently, so restore the pipeline order
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].Stage.Order() < stages[j].Stage.Order()
+	})
+	if ctx.Err() != nil {
+		toolErr := zerrors.NewDeploymentError(
+			"DEPLOY_CANCELLED",
+			"Deployment was cancelled and zcli was stopped",
+			"If the package was already uploaded, the build may still run. Check it with 'deploy_status' or 'deploy_logs'",
+		).WithNextTool("deploy_status")
+		if len(stages) > 0 {
+			toolErr.WithMetadata("stages", stages)
+		}
+		return ToolErrorResponse(toolErr)
+	}
+	if err != nil {
+		// Recognize known zcli failures
+		if failure, ok := zcli.Classify(err); ok {
+			if failure.Code == zcli.FailureServiceSelection {
+				// Get the services from the project to help the user
+				services, listErr := client.ListServices(ctx, projectID)
+				var serviceHelp strings.Builder
+				serviceHelp.WriteString("Service selection is required for deployment.\n\n")
+				
+				if listErr == nil && len(services) > 0 {
+					serviceHelp.WriteString("Available services in your project:\n")
+					for _, svc := range services {
+						serviceHelp.WriteString(fmt.Sprintf("- %s (type: %s@%s)\n", svc.Name, svc.ServiceStackTypeInfo.ServiceStackTypeName, svc.ServiceStackTypeInfo.ServiceStackTypeVersionName))
+					}
+					serviceHelp.WriteString("\nUse the service_name parameter with one of the above service names (hostnames).\n")
+					serviceHelp.WriteString("Example: deploy_push(project_id=\"...\", service_name=\"app\", ...)")
+				} else {
+					serviceHelp.WriteString("Use 'service_list' tool to see available services in your project.\n")
+					serviceHelp.WriteString("Then provide the service_name parameter matching a service hostname from your zerops.yml.")
+				}
+				failure.Resolution = serviceHelp.String()
+			}
+			if failure.Code == zcli.FailureProjectNotFound {
+				failure.Message = fmt.Sprintf("zcli cannot find project %s", projectID)
+				failure.Resolution = fmt.Sprintf("%s. %s", login.Message(), failure.Resolution)
+			}
+			toolErr := failure.ToolError(err)
+			if len(stages) > 0 {
+				toolErr.WithMetadata("stages", stages)
+			}
+			return ToolErrorResponse(toolErr)
+		}
+
+		toolErr := zerrors.NewDeploymentError(
+			"DEPLOY_FAILED",
+			fmt.Sprintf("Deployment failed: %v", err),
+			fmt.Sprintf("Check the error details:\n%s\n\nUse 'deploy_troubleshoot' for help", output),
+		)
+		var zerr *zerrors.ZCLIError
+		if errors.As(err, &zerr) {
+			toolErr.WithMetadata("exit_code", zerr.ExitCode)
+		}
+		if len(stages) > 0 {
+			toolErr.WithMetadata("stages", stages)
+		}
+		return ToolErrorResponse(toolErr)
+	}
+
+	// Parse output for success indicators
+	var response strings.Builder
+	response.WriteString("Deployment initiated successfully!\n\n")
+	response.WriteString("Deployment progress:\n")
+	
+	// Include relevant output
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.Contains(line, "Using config file") {
+			response.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+	}
+
+	if login.Refreshed {
+		response.WriteString(fmt.Sprintf("\n%s\n", login.Message()))
+	}
+
+	if len(stages) > 0 {
+		response.WriteString("\nMilestones:\n")
+		for _, stage := range stages {
+			response.WriteString(fmt.Sprintf("- %s %s\n", stage.Time.Format("15:04:05"), stage.Message))
+		}
+	}
+
+	response.WriteString("\nNext steps:\n")
+	response.WriteString("- Use 'deploy_status' to check deployment progress\n")
+	response.WriteString("- Use 'deploy_logs' to view build logs\n")
+	response.WriteString("- Use 'service_logs' to view runtime logs\n")
+
+	return StructuredResponse(response.String(), map[string]interface{}{
+		"backend": config.DeployBackendZCLI,
+		"stages":  stages,
+		"output":  output,
+		"login":   login,
+	})
+}
+
+// formatBytes renders a byte count for display
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+
+// probeHostname picks a service of the project whose hostname can be resolved through the VPN
+func probeHostname(ctx context.Context, client api.ZeropsAPI, projectID string) string {
+	services, err := client.ListServices(ctx, projectID)
+	if err != nil {
+		return ""
+	}
+	for _, service := range services {
+		if !strings.EqualFold(service.ServiceStackTypeInfo.ServiceStackTypeCategory, "system") {
+			return service.Name
+		}
+	}
+	return ""
+}
+
+// vpnLockKey serializes VPN transitions; zcli deploys hold it shared
+const vpnLockKey = "vpn"
+
+// deployLockTimeout bounds how long a started build keeps its deploy locks
+const deployLockTimeout = 30 * time.Minute
+
+// deployLockKey identifies deploys of one service
+func deployLockKey(projectID, service string) string {
+	return "deploy:" + projectID + "/" + service
+}
+
+// deployProjectLockKey identifies deploys to a project. Deploys of a named service
+// hold it shared; a deploy whose service is unknown holds it exclusively, as it
+// may target any service.
+func deployProjectLockKey(projectID string) string {
+	return "deploy:" + projectID
+}
+
+// acquireDeployLocks waits for the locks of a deploy to target, which is "*"
+// when the service cannot be determined, and returns a function releasing them
+func acquireDeployLocks(ctx context.Context, locks *lock.Manager, n *notifier, projectID, target, detail string) (func(), *mcp.CallToolResult) {
+	mode := lock.Shared
+	if target == "*" {
+		mode = lock.Exclusive
+	}
+	releaseProject, errResult := acquireLock(ctx, locks, n, deployProjectLockKey(projectID), mode, "deploy_push", detail)
+	if errResult != nil || target == "*" {
+		return releaseProject, errResult
+	}
+
+	releaseService, errResult := acquireLock(ctx, locks, n, deployLockKey(projectID, target), lock.Exclusive, "deploy_push", detail)
+	if errResult != nil {
+		releaseProject()
+		return nil, errResult
+	}
+	return func() {
+		releaseService()
+		releaseProject()
+	}, nil
+}
+
+// releaseWhenFinished waits for a deploy process started through the API and
+// then releases its locks
+func releaseWhenFinished(ctx context.Context, client api.ZeropsAPI, processID string, release func()) {
+	defer release()
+	_, _ = client.WaitForProcess(ctx, processID, deployLockTimeout)
+}
+
+// deployTarget returns the service a deploy_push call targets. Without a service
+// name it is the only setup in zerops.yml, if that can be determined.
+func deployTarget(serviceName, workDir, configPath string) string {
+	if serviceName != "" {
+		return serviceName
+	}
+	if cfg, err := deploy.ReadConfig(workDir, configPath); err == nil && len(cfg.Setups) == 1 {
+		return cfg.Setups[0]
+	}
+	return "*"
+}
+
+// acquireLock waits for a lock, reporting the queue position as progress. It
+// returns an error result when the call is cancelled while waiting.
+func acquireLock(ctx context.Context, locks *lock.Manager, n *notifier, key string, mode lock.Mode, operation, detail string) (func(), *mcp.CallToolResult) {
+	release, err := locks.Acquire(ctx, key, mode, operation, detail, func(position int) {
+		n.Progress(fmt.Sprintf("Waiting for %d operation(s) ahead on %s; see 'deploy_queue'", position, key))
 	})
-}
\ No newline at end of file
+	if err != nil {
+		return nil, ToolErrorResponse(zerrors.NewDeploymentError(
+			"OPERATION_CANCELLED",
+			fmt.Sprintf("%s was cancelled while waiting for %s", operation, key),
+			"Check 'deploy_queue' for the operations holding it and retry",
+		).WithNextTool("deploy_queue"))
+	}
+	return release, nil
+}
+
+// vpnPrivilegeError explains how to let the server run VPN commands as root without a password prompt
+func vpnPrivilegeError(zcliWrapper *zcli.ZCLIWrapper, command string) *zerrors.ToolError {
+	socket := zcliWrapper.VPNHelperSocket()
+	if socket == "" {
+		socket = vpnhelper.DefaultSocketPath
+	}
+	return zerrors.NewVPNError(
+		"VPN_PRIVILEGES_REQUIRED",
+		"VPN commands need root privileges, and the server cannot prompt for a sudo password",
+		fmt.Sprintf("Start the VPN helper once with 'sudo zerops-vpn-helper -user $USER -socket %s' (set ZEROPS_MCP_VPN_HELPER if you use another socket), or allow passwordless sudo for zcli ('<user> ALL=(root) NOPASSWD: <path to zcli>' in sudoers), or run 'sudo zcli vpn %s' in a terminal and check with 'vpn_status'", socket, command),
+	).WithNextTool("vpn_status")
+}
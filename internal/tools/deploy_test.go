@@ -0,0 +1,65 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+func TestDeployPushHoldsLockUntilBuildFinishes(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	sim.AddService(project.ID, "app", "nodejs@20")
+	c := startTools(t, sim)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"zerops.yml": "zerops:\n  - setup: app\n    run:\n      start: node index.js\n",
+		"index.js":   "console.log('hi')",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := callJSON(t, c, "deploy_push", map[string]interface{}{"project_id": project.ID, "working_dir": dir})
+	if !out.OK {
+		t.Fatalf("deploy_push failed: %+v", out.Error)
+	}
+
+	running := func() []string {
+		var data struct {
+			Running []struct {
+				Key string `json:"key"`
+			} `json:"running"`
+		}
+		if err := json.Unmarshal(callJSON(t, c, "deploy_queue", nil).Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, ticket := range data.Running {
+			keys = append(keys, ticket.Key)
+		}
+		return keys
+	}
+
+	if keys := running(); len(keys) != 2 {
+		t.Fatalf("running locks after deploy_push = %v, want the project and service locks", keys)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for len(running()) > 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("deploy locks were not released after the build finished: %v", running())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/lock"
+)
+
+// tryDeployLocks acquires the deploy locks of target, giving up after a short wait
+func tryDeployLocks(t *testing.T, locks *lock.Manager, target string) (func(), bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	release, errResult := acquireDeployLocks(ctx, locks, &notifier{}, "project-1", target, target)
+	return release, errResult == nil
+}
+
+func TestAcquireDeployLocks(t *testing.T) {
+	locks := lock.NewManager()
+
+	releaseApp, ok := tryDeployLocks(t, locks, "app")
+	if !ok {
+		t.Fatal("first deploy of app should not wait")
+	}
+	if release, ok := tryDeployLocks(t, locks, "api"); !ok {
+		t.Error("deploys of different services should run together")
+	} else {
+		release()
+	}
+	if _, ok := tryDeployLocks(t, locks, "app"); ok {
+		t.Error("a second deploy of app should wait for the first")
+	}
+	if _, ok := tryDeployLocks(t, locks, "*"); ok {
+		t.Error("a deploy of an unknown service should wait for named deploys of the project")
+	}
+	releaseApp()
+
+	releaseAll, ok := tryDeployLocks(t, locks, "*")
+	if !ok {
+		t.Fatal("a deploy of an unknown service should run once the project is idle")
+	}
+	if _, ok := tryDeployLocks(t, locks, "app"); ok {
+		t.Error("named deploys should wait for a deploy of an unknown service")
+	}
+	releaseAll()
+
+	if tickets := locks.Snapshot(); len(tickets) != 0 {
+		t.Errorf("%d tickets left after releasing every deploy", len(tickets))
+	}
+}
@@ -0,0 +1,631 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/dotenv"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// Ways env_set, env_delete and env_sync make a change take effect
+const (
+	envApplyNone    = "none"
+	envApplyRestart = "restart"
+	envApplyReload  = "reload"
+)
+
+// envProcessTimeout bounds the wait for env changes before services are restarted or reloaded
+const envProcessTimeout = 60 * time.Second
+
+// envKeyPattern matches valid environment variable names
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envScope is the owner of a set of environment variables: a service or a whole project
+type envScope struct {
+	Kind      string `json:"scope"` // "service" or "project"
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ProjectID string `json:"project_id"`
+}
+
+// String describes the scope for messages
+func (sc envScope) String() string {
+	return fmt.Sprintf("%s '%s'", sc.Kind, sc.Name)
+}
+
+// envVar is an environment variable of a service or a project
+type envVar struct {
+	ID        string `json:"id"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// envProcess summarizes a process started by an env change, restart or reload
+type envProcess struct {
+	ID      string `json:"process_id"`
+	Action  string `json:"action"`
+	Status  string `json:"status"`
+	Service string `json:"service,omitempty"`
+}
+
+// RegisterEnvTools registers tools that manage service and project environment variables
+func RegisterEnvTools(s *server.MCPServer, client api.ZeropsAPI) {
+	// env_list
+	envListTool := mcp.NewTool(
+		"env_list",
+		mcp.WithDescription("List the environment variables of a service or a project (pass service_id or project_id). Sensitive values are masked"),
+		envScopeOption("list environment variables of"),
+		mcp.WithBoolean("show_values",
+			mcp.Description("Show values of sensitive variables (default: false)"),
+		),
+	)
+
+	addTool(s, envListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		scope, errResult := resolveEnvScope(ctx, client, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		showValues := request.GetBool("show_values", false)
+
+		envs, err := listEnvs(ctx, client, scope)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+		masked := false
+		for i := range envs {
+			value := envDisplayValue(envs[i], showValues)
+			masked = masked || value != envs[i].Value
+			envs[i].Value = value
+		}
+
+		data := map[string]interface{}{
+			"scope": scope,
+			"envs":  envs,
+			"count": len(envs),
+		}
+		if len(envs) == 0 {
+			return StructuredResponse(fmt.Sprintf("The %s has no environment variables\n\nUse 'env_set' to add one", scope), data), nil
+		}
+
+		var response strings.Builder
+		response.WriteString(fmt.Sprintf("Environment variables of the %s (%d):\n\n", scope, len(envs)))
+		for _, env := range envs {
+			marker := ""
+			if env.Sensitive {
+				marker = " [sensitive]"
+			}
+			response.WriteString(fmt.Sprintf("  %s = %s%s\n", env.Key, env.Value, marker))
+		}
+		if masked {
+			response.WriteString("\nNote: Sensitive values are masked. Use show_values=true to reveal them.\n")
+		}
+		return StructuredResponse(response.String(), data), nil
+	})
+
+	// env_set
+	envSetTool := mcp.NewTool(
+		"env_set",
+		mcp.WithDescription("Create or update an environment variable of a service or a project (pass service_id or project_id). Running services only see the change after a restart or reload; use apply to trigger it"),
+		envScopeOption("set the environment variable on"),
+		mcp.WithString("key",
+			mcp.Required(),
+			mcp.Description("Variable name (letters, digits and underscores)"),
+		),
+		mcp.WithString("value",
+			mcp.Required(),
+			mcp.Description("Variable value; may reference other services, e.g. ${db_hostname}"),
+		),
+		mcp.WithBoolean("sensitive",
+			mcp.Description("Store the value as sensitive (default: keeps the current setting, or true for new keys that look like secrets)"),
+		),
+		envApplyOption(),
+	)
+
+	addTool(s, envSetTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key, errResult := requireEnvKey(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		value, err := request.RequireString("value")
+		if err != nil {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_ENV_VALUE",
+				"Value is required",
+				"Provide the value of the environment variable",
+			)), nil
+		}
+		apply, errResult := envApplyMode(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		scope, errResult := resolveEnvScope(ctx, client, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		envs, err := listEnvs(ctx, client, scope)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+		existing, exists := findEnv(envs, key)
+
+		sensitive := isSensitiveKey(key)
+		if exists {
+			sensitive = existing.Sensitive
+		}
+		if _, ok := request.GetArguments()["sensitive"]; ok {
+			sensitive = request.GetBool("sensitive", sensitive)
+		}
+
+		var process *api.Process
+		action := "created"
+		switch {
+		case exists && existing.Value == value && existing.Sensitive == sensitive:
+			action = "unchanged"
+		case exists:
+			action = "updated"
+			process, err = updateEnv(ctx, client, scope, existing, value, sensitive)
+		default:
+			process, err = createEnv(ctx, client, scope, key, value, sensitive)
+		}
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+
+		n := newNotifier(ctx, request, "env_set")
+		var changes []*api.Process
+		if process != nil {
+			changes = append(changes, process)
+		}
+		return envChangeResult(ctx, client, n, scope, apply, changes,
+			fmt.Sprintf("Environment variable %s %s on the %s", key, action, scope),
+			map[string]interface{}{
+				"key":       key,
+				"action":    action,
+				"sensitive": sensitive,
+			}), nil
+	})
+
+	// env_delete
+	envDeleteTool := mcp.NewTool(
+		"env_delete",
+		mcp.WithDescription("Delete an environment variable of a service or a project (pass service_id or project_id). Running services keep the old value until a restart or reload; use apply to trigger it"),
+		envScopeOption("delete the environment variable from"),
+		mcp.WithString("key",
+			mcp.Required(),
+			mcp.Description("Name of the variable to delete"),
+		),
+		envApplyOption(),
+	)
+
+	addTool(s, envDeleteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key, errResult := requireEnvKey(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		apply, errResult := envApplyMode(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		scope, errResult := resolveEnvScope(ctx, client, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		envs, err := listEnvs(ctx, client, scope)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+		existing, exists := findEnv(envs, key)
+		if !exists {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"ENV_NOT_FOUND",
+				fmt.Sprintf("The %s has no environment variable %s", scope, key),
+				"Use 'env_list' to see the variables that are set",
+			).WithNextTool("env_list")), nil
+		}
+
+		process, err := deleteEnv(ctx, client, scope, existing)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+
+		n := newNotifier(ctx, request, "env_delete")
+		return envChangeResult(ctx, client, n, scope, apply, []*api.Process{process},
+			fmt.Sprintf("Environment variable %s deleted from the %s", key, scope),
+			map[string]interface{}{
+				"key":    key,
+				"action": "deleted",
+			}), nil
+	})
+
+	// env_sync
+	envSyncTool := mcp.NewTool(
+		"env_sync",
+		mcp.WithDescription("Sync the environment variables of a service or a project (pass service_id or project_id) with a local .env file. The first call only previews the diff (added, changed and removed variables, secrets masked) and returns a plan_id; call again with confirm=true and that plan_id to apply it. Keys that look like secrets are stored as sensitive"),
+		envScopeOption("sync the environment variables of"),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Path to the .env file, e.g. .env.production"),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Apply the previewed changes (default: false, preview only)"),
+		),
+		mcp.WithString("plan_id",
+			mcp.Description("plan_id returned by the preview; required with confirm=true so only the reviewed changes are applied"),
+		),
+		mcp.WithBoolean("keep_missing",
+			mcp.Description("Keep variables that are not in the file instead of deleting them (default: false)"),
+		),
+		envApplyOption(),
+	)
+
+	addTool(s, envSyncTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		file, err := request.RequireString("file")
+		if err != nil {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_ENV_FILE",
+				"File is required",
+				"Provide the path to a .env file",
+			)), nil
+		}
+		confirm := request.GetBool("confirm", false)
+		planID := request.GetString("plan_id", "")
+		keepMissing := request.GetBool("keep_missing", false)
+		apply, errResult := envApplyMode(request)
+		if errResult != nil {
+			return errResult, nil
+		}
+		scope, errResult := resolveEnvScope(ctx, client, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		entries, err := dotenv.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return ToolErrorResponse(zerrors.NewValidationError(
+					"ENV_FILE_NOT_FOUND",
+					fmt.Sprintf("Env file not found: %s", file),
+					"Check the path; relative paths are resolved from the server's working directory",
+				)), nil
+			}
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"ENV_FILE_INVALID",
+				fmt.Sprintf("Failed to parse %s: %v", file, err),
+				"Fix the line and try again; each line must be KEY=value, a comment or blank",
+			)), nil
+		}
+
+		live, err := listEnvs(ctx, client, scope)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+		plan := diffEnvs(scope, entries, live, keepMissing)
+
+		data := map[string]interface{}{
+			"file":      file,
+			"scope":     scope,
+			"changes":   plan.Changes,
+			"added":     plan.count(envOpAdd),
+			"changed":   plan.count(envOpChange),
+			"removed":   plan.count(envOpRemove),
+			"unchanged": plan.Unchanged,
+		}
+		if len(plan.Changes) == 0 {
+			data["next_step"] = "Nothing to apply"
+			return StructuredResponse(fmt.Sprintf("The %s is already in sync with %s (%d variables)", scope, file, plan.Unchanged), data), nil
+		}
+
+		if !confirm {
+			data["plan_id"] = plan.ID
+			data["next_step"] = fmt.Sprintf("Review the changes, then call env_sync again with confirm=true and plan_id=%s", plan.ID)
+			return StructuredResponse(fmt.Sprintf("Preview of syncing %s to the %s: %s\n\n%s\nNothing was changed yet. %s\n",
+				file, scope, plan.Summary(), plan.Format(), data["next_step"]), data), nil
+		}
+
+		if planID != plan.ID {
+			message := "confirm=true needs the plan_id of a preview"
+			if planID != "" {
+				message = "The variables or the file changed since the preview"
+			}
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"ENV_SYNC_PLAN_CHANGED",
+				message,
+				fmt.Sprintf("Review the current changes and confirm them with plan_id=%s:\n%s", plan.ID, plan.Format()),
+			).WithMetadata("plan_id", plan.ID).WithMetadata("changes", plan.Changes)), nil
+		}
+
+		n := newNotifier(ctx, request, "env_sync")
+		var processes []*api.Process
+		for i, change := range plan.Changes {
+			n.Progress(fmt.Sprintf("Applying change %d of %d: %s %s", i+1, len(plan.Changes), change.Op, change.Key))
+			process, err := change.apply(ctx, client, scope)
+			if err != nil {
+				applied := []envProcess{}
+				for _, p := range processes {
+					applied = append(applied, newEnvProcess(p, ""))
+				}
+				return ToolErrorResponse(NewAPIToolError(err).
+					WithMetadata("failed_key", change.Key).
+					WithMetadata("applied", i).
+					WithMetadata("processes", applied)), nil
+			}
+			processes = append(processes, process)
+		}
+
+		return envChangeResult(ctx, client, n, scope, apply, processes,
+			fmt.Sprintf("Synced %s to the %s: %d added, %d changed, %d removed", file, scope,
+				plan.count(envOpAdd), plan.count(envOpChange), plan.count(envOpRemove)), data), nil
+	})
+}
+
+// envScopeOption adds the service_id and project_id parameters that select an env scope
+func envScopeOption(purpose string) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("service_id",
+			mcp.Description(fmt.Sprintf("Service to %s", purpose)),
+		)(t)
+		mcp.WithString("project_id",
+			mcp.Description(fmt.Sprintf("Project to %s; project variables are shared by all its services", purpose)),
+		)(t)
+	}
+}
+
+// envApplyOption adds the parameter that restarts or reloads services after a change
+func envApplyOption() mcp.ToolOption {
+	return mcp.WithString("apply",
+		mcp.Description("How running services pick up the change: 'none' (default, on the next deploy or restart), 'restart' (restart the containers) or 'reload' (reload the variables without a restart). For project variables every running runtime service of the project is restarted or reloaded"),
+		mcp.Enum(envApplyNone, envApplyRestart, envApplyReload),
+	)
+}
+
+// envApplyMode reads and validates the apply parameter
+func envApplyMode(request mcp.CallToolRequest) (string, *mcp.CallToolResult) {
+	apply := request.GetString("apply", envApplyNone)
+	switch apply {
+	case envApplyNone, envApplyRestart, envApplyReload:
+		return apply, nil
+	}
+	return "", ToolErrorResponse(zerrors.NewValidationError(
+		"INVALID_APPLY_MODE",
+		fmt.Sprintf("Unknown apply mode '%s'", apply),
+		"Use 'none', 'restart' or 'reload'",
+	))
+}
+
+// requireEnvKey reads and validates the key parameter
+func requireEnvKey(request mcp.CallToolRequest) (string, *mcp.CallToolResult) {
+	key, err := request.RequireString("key")
+	if err != nil || !envKeyPattern.MatchString(key) {
+		return "", ToolErrorResponse(zerrors.NewValidationError(
+			"INVALID_ENV_KEY",
+			fmt.Sprintf("Invalid environment variable name '%s'", key),
+			"Use letters, digits and underscores, not starting with a digit (e.g. DATABASE_URL)",
+		))
+	}
+	return key, nil
+}
+
+// resolveEnvScope finds the service or project whose variables a call manages
+func resolveEnvScope(ctx context.Context, client api.ZeropsAPI, request mcp.CallToolRequest) (envScope, *mcp.CallToolResult) {
+	serviceID := request.GetString("service_id", "")
+	projectID := request.GetString("project_id", "")
+
+	switch {
+	case serviceID != "" && projectID != "":
+		return envScope{}, ToolErrorResponse(zerrors.NewValidationError(
+			"AMBIGUOUS_ENV_SCOPE",
+			"Pass either service_id or project_id, not both",
+			"Use service_id for variables of one service and project_id for variables shared by the whole project",
+		))
+	case serviceID != "":
+		service, errResult := ValidateServiceAccess(ctx, client, "", serviceID)
+		if errResult != nil {
+			return envScope{}, errResult
+		}
+		return envScope{Kind: "service", ID: service.ID, Name: service.Name, ProjectID: service.ProjectID}, nil
+	case projectID != "":
+		project, errResult := ValidateProjectAccess(ctx, client, projectID)
+		if errResult != nil {
+			return envScope{}, errResult
+		}
+		return envScope{Kind: "project", ID: project.ID, Name: project.Name, ProjectID: project.ID}, nil
+	default:
+		return envScope{}, ToolErrorResponse(zerrors.NewValidationError(
+			"MISSING_ENV_SCOPE",
+			"service_id or project_id is required",
+			"Use 'service_list' or 'project_list' to find the ID",
+		))
+	}
+}
+
+// listEnvs returns the variables of a scope sorted by key
+func listEnvs(ctx context.Context, client api.ZeropsAPI, scope envScope) ([]envVar, error) {
+	envs := []envVar{}
+	if scope.Kind == "project" {
+		projectEnvs, err := client.ListProjectEnvs(ctx, scope.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, env := range projectEnvs {
+			envs = append(envs, envVar{ID: env.ID, Key: env.Key, Value: env.Content, Sensitive: env.Sensitive})
+		}
+	} else {
+		serviceEnvs, err := client.ListServiceEnvs(ctx, scope.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, env := range serviceEnvs {
+			envs = append(envs, envVar{ID: env.ID, Key: env.Key, Value: env.Content, Sensitive: env.Sensitive})
+		}
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Key < envs[j].Key })
+	return envs, nil
+}
+
+// findEnv looks up a variable by key
+func findEnv(envs []envVar, key string) (envVar, bool) {
+	for _, env := range envs {
+		if env.Key == key {
+			return env, true
+		}
+	}
+	return envVar{}, false
+}
+
+// createEnv adds a variable to a scope
+func createEnv(ctx context.Context, client api.ZeropsAPI, scope envScope, key, value string, sensitive bool) (*api.Process, error) {
+	if scope.Kind == "project" {
+		return client.CreateProjectEnv(ctx, scope.ID, key, value, sensitive)
+	}
+	return client.CreateServiceEnv(ctx, scope.ID, key, value, sensitive)
+}
+
+// updateEnv changes the value of a variable of a scope
+func updateEnv(ctx context.Context, client api.ZeropsAPI, scope envScope, env envVar, value string, sensitive bool) (*api.Process, error) {
+	if scope.Kind == "project" {
+		return client.UpdateProjectEnv(ctx, env.ID, value, sensitive)
+	}
+	return client.UpdateServiceEnv(ctx, env.ID, value, sensitive)
+}
+
+// deleteEnv removes a variable from a scope
+func deleteEnv(ctx context.Context, client api.ZeropsAPI, scope envScope, env envVar) (*api.Process, error) {
+	if scope.Kind == "project" {
+		return client.DeleteProjectEnv(ctx, env.ID)
+	}
+	return client.DeleteServiceEnv(ctx, env.ID)
+}
+
+// envDisplayValue masks the value of sensitive variables unless showValues is set
+func envDisplayValue(env envVar, showValues bool) string {
+	if showValues || !(env.Sensitive || isSensitiveKey(env.Key)) {
+		return env.Value
+	}
+	return strings.Repeat("*", 8)
+}
+
+// restartEnvServices restarts or reloads the running runtime services of a scope so
+// they pick up changed variables. It returns the started processes and notes about
+// services that were skipped.
+func restartEnvServices(ctx context.Context, client api.ZeropsAPI, n *notifier, scope envScope, apply string) ([]envProcess, []string, error) {
+	var targets []api.Service
+	if scope.Kind == "project" {
+		services, err := client.ListServices(ctx, scope.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, service := range services {
+			if service.ServiceStackTypeInfo.ServiceStackTypeCategory == "USER" {
+				targets = append(targets, service)
+			}
+		}
+	} else {
+		service, err := client.GetService(ctx, scope.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets = append(targets, service.Service)
+	}
+
+	restart := client.RestartService
+	if apply == envApplyReload {
+		restart = client.ReloadService
+	}
+
+	processes := []envProcess{}
+	var skipped []string
+	for _, service := range targets {
+		if service.Status != "RUNNING" && service.Status != "ACTIVE" {
+			skipped = append(skipped, fmt.Sprintf("%s is %s and picks up the change when it next starts", service.Name, service.Status))
+			continue
+		}
+		n.Progress(fmt.Sprintf("Running %s of %s", apply, service.Name))
+		process, err := restart(ctx, service.ID)
+		if err != nil {
+			return processes, skipped, err
+		}
+		processes = append(processes, newEnvProcess(process, service.Name))
+	}
+	return processes, skipped, nil
+}
+
+// envChangeResult reports an env change together with the processes it started.
+// When apply asks for it, the change is waited for and services are then
+// restarted or reloaded.
+func envChangeResult(ctx context.Context, client api.ZeropsAPI, n *notifier, scope envScope, apply string, changes []*api.Process, message string, data map[string]interface{}) *mcp.CallToolResult {
+	processes := []envProcess{}
+	for _, process := range changes {
+		processes = append(processes, newEnvProcess(process, ""))
+	}
+
+	var skipped []string
+	if apply != envApplyNone && len(changes) > 0 {
+		for _, process := range changes {
+			n.Progress(fmt.Sprintf("Waiting for %s to finish", process.ActionName))
+			if _, err := client.WaitForProcess(ctx, process.ID, envProcessTimeout); err != nil {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
+					"ENV_CHANGE_NOT_FINISHED",
+					fmt.Sprintf("%s did not finish, so no %s was started: %v", process.ActionName, apply, err),
+					fmt.Sprintf("Check the change with 'process_status' process_id=%s, then restart or reload the services", process.ID),
+				).WithMetadata("processes", processes).WithNextTool("process_status"))
+			}
+		}
+
+		restarted, notes, err := restartEnvServices(ctx, client, n, scope, apply)
+		processes = append(processes, restarted...)
+		if err != nil {
+			return ToolErrorResponse(NewAPIToolError(err).WithMetadata("processes", processes))
+		}
+		skipped = notes
+	}
+
+	data["message"] = message
+	data["scope"] = scope
+	data["apply"] = apply
+	data["processes"] = processes
+
+	var response strings.Builder
+	response.WriteString(message + "\n")
+	if len(processes) > 0 {
+		response.WriteString("\nProcesses:\n")
+		for _, p := range processes {
+			target := ""
+			if p.Service != "" {
+				target = " (" + p.Service + ")"
+			}
+			response.WriteString(fmt.Sprintf("- %s %s%s: %s\n", p.ID, p.Action, target, p.Status))
+		}
+	}
+	for _, note := range skipped {
+		response.WriteString(fmt.Sprintf("\nNote: %s\n", note))
+	}
+
+	switch {
+	case len(changes) == 0:
+		data["next_step"] = "Nothing changed"
+	case apply == envApplyNone:
+		data["next_step"] = "Running services see the change after their next deploy or restart; call again with apply='restart' or apply='reload', or use 'env_list' to review"
+	default:
+		data["next_step"] = "Use 'process_status' to follow the processes"
+	}
+	response.WriteString(fmt.Sprintf("\nNext step: %s\n", data["next_step"]))
+
+	return StructuredResponse(response.String(), data)
+}
+
+// newEnvProcess summarizes a process
+func newEnvProcess(process *api.Process, service string) envProcess {
+	return envProcess{ID: process.ID, Action: process.ActionName, Status: process.Status, Service: service}
+}
@@ -0,0 +1,189 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+// envData is the data of an env_set or env_delete result
+type envData struct {
+	Sensitive bool `json:"sensitive"`
+	Processes []struct {
+		Action  string `json:"action"`
+		Service string `json:"service"`
+	} `json:"processes"`
+}
+
+func TestEnvSetRestartsRunningService(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	sim.ProcessPolls = 0
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "env_set", map[string]interface{}{
+		"service_id": app.ID, "key": "API_TOKEN", "value": "s3cret", "apply": "restart",
+	})
+	if !out.OK {
+		t.Fatalf("env_set failed: %+v", out.Error)
+	}
+	var data struct {
+		Sensitive bool `json:"sensitive"`
+		Processes []struct {
+			Action string `json:"action"`
+		} `json:"processes"`
+	}
+	if err := json.Unmarshal(out.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if !data.Sensitive {
+		t.Error("API_TOKEN should be stored as sensitive")
+	}
+	if len(data.Processes) != 2 {
+		t.Errorf("expected the create and restart processes, got %+v", data.Processes)
+	}
+
+	var stored bool
+	for _, env := range sim.ServiceEnvs(app.ID) {
+		if env.Key == "API_TOKEN" && env.Content == "s3cret" {
+			stored = true
+		}
+	}
+	if !stored {
+		t.Errorf("variable not stored on the server: %+v", sim.ServiceEnvs(app.ID))
+	}
+
+	listed, _ := callText(t, c, "env_list", map[string]interface{}{"service_id": app.ID})
+	if strings.Contains(listed, "s3cret") {
+		t.Errorf("env_list must mask sensitive values:\n%s", listed)
+	}
+}
+
+func TestEnvSetKeepsSensitiveFlag(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "env_set", map[string]interface{}{"service_id": app.ID, "key": "GREETING", "value": "hello", "sensitive": true})
+	if !out.OK {
+		t.Fatalf("env_set failed: %+v", out.Error)
+	}
+
+	// Updating the value without the sensitive parameter keeps the flag
+	out = callJSON(t, c, "env_set", map[string]interface{}{"service_id": app.ID, "key": "GREETING", "value": "hi"})
+	var data envData
+	if !out.OK || json.Unmarshal(out.Data, &data) != nil {
+		t.Fatalf("env_set = %+v %s", out.Error, out.Data)
+	}
+	if !data.Sensitive {
+		t.Error("an update must keep the variable sensitive")
+	}
+	for _, env := range sim.ServiceEnvs(app.ID) {
+		if env.Key == "GREETING" && (!env.Sensitive || env.Content != "hi") {
+			t.Errorf("stored %+v, want the new value kept sensitive", env)
+		}
+	}
+}
+
+func TestEnvSetProjectScopeReloadsRunningServices(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	sim.ProcessPolls = 0
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	worker := sim.AddService(project.ID, "worker", "go@1")
+	sim.AddService(project.ID, "db", "postgresql@16")
+	sim.SimulateDeploy(app.ID, 3000)
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "env_set", map[string]interface{}{
+		"project_id": project.ID, "key": "LOG_LEVEL", "value": "debug", "apply": "reload",
+	})
+	var data envData
+	if !out.OK || json.Unmarshal(out.Data, &data) != nil {
+		t.Fatalf("env_set = %+v %s", out.Error, out.Data)
+	}
+	if len(sim.ProjectEnvs(project.ID)) != 1 {
+		t.Errorf("project envs = %+v, want LOG_LEVEL", sim.ProjectEnvs(project.ID))
+	}
+
+	// Only the running runtime service is reloaded; managed and undeployed services are left alone
+	if len(data.Processes) != 2 || data.Processes[1].Action != "serviceStack.reload" || data.Processes[1].Service != "app" {
+		t.Errorf("processes = %+v, want the create and a reload of app", data.Processes)
+	}
+	for _, request := range sim.Requests() {
+		if strings.Contains(request, "/restart") || strings.Contains(request, worker.ID+"/reload") {
+			t.Errorf("unexpected request %s", request)
+		}
+	}
+
+	text, _ := callText(t, c, "env_set", map[string]interface{}{
+		"project_id": project.ID, "key": "LOG_LEVEL", "value": "info", "apply": "reload",
+	})
+	if !strings.Contains(text, "worker is READY_TO_DEPLOY") {
+		t.Errorf("expected a note about the undeployed worker, got:\n%s", text)
+	}
+}
+
+func TestEnvDelete(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	sim.ProcessPolls = 0
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	c := startTools(t, sim)
+
+	if out := callJSON(t, c, "env_set", map[string]interface{}{"service_id": app.ID, "key": "GREETING", "value": "hello"}); !out.OK {
+		t.Fatalf("env_set failed: %+v", out.Error)
+	}
+
+	out := callJSON(t, c, "env_delete", map[string]interface{}{"service_id": app.ID, "key": "GREETING", "apply": "restart"})
+	var data envData
+	if !out.OK || json.Unmarshal(out.Data, &data) != nil {
+		t.Fatalf("env_delete = %+v %s", out.Error, out.Data)
+	}
+	if len(data.Processes) != 2 || data.Processes[0].Action != "userData.delete" || data.Processes[1].Action != "serviceStack.restart" {
+		t.Errorf("processes = %+v, want the delete and a restart", data.Processes)
+	}
+	for _, env := range sim.ServiceEnvs(app.ID) {
+		if env.Key == "GREETING" {
+			t.Errorf("GREETING still stored: %+v", env)
+		}
+	}
+
+	out = callJSON(t, c, "env_delete", map[string]interface{}{"service_id": app.ID, "key": "GREETING"})
+	if out.OK || out.Error == nil || out.Error.Code != "ENV_NOT_FOUND" {
+		t.Errorf("expected ENV_NOT_FOUND for a missing key, got %+v", out.Error)
+	}
+}
+
+func TestEnvChangeNotFinishedSkipsRestart(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	sim.ProcessPolls = 0
+	sim.FailProcesses("userData.create")
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "env_set", map[string]interface{}{
+		"service_id": app.ID, "key": "GREETING", "value": "hello", "apply": "restart",
+	})
+	if out.OK || out.Error == nil || out.Error.Code != "ENV_CHANGE_NOT_FINISHED" {
+		t.Fatalf("expected ENV_CHANGE_NOT_FINISHED, got %+v", out.Error)
+	}
+	for _, request := range sim.Requests() {
+		if strings.HasSuffix(request, "/restart") {
+			t.Errorf("the service must not be restarted after a failed change: %s", request)
+		}
+	}
+}
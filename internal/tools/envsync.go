@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/dotenv"
+)
+
+// Kinds of differences between a .env file and live variables
+const (
+	envOpAdd    = "add"
+	envOpChange = "change"
+	envOpRemove = "remove"
+)
+
+// envChange is a difference between a .env file and the live variables of a scope.
+// Old and New are masked for sensitive variables.
+type envChange struct {
+	Op        string `json:"op"`
+	Key       string `json:"key"`
+	Old       string `json:"old,omitempty"`
+	New       string `json:"new,omitempty"`
+	Sensitive bool   `json:"sensitive"`
+	Note      string `json:"note,omitempty"`
+
+	live  envVar // variable being changed or removed
+	value string // unmasked new value
+}
+
+// apply makes the change on the scope
+func (c envChange) apply(ctx context.Context, client api.ZeropsAPI, scope envScope) (*api.Process, error) {
+	switch c.Op {
+	case envOpAdd:
+		return createEnv(ctx, client, scope, c.Key, c.value, c.Sensitive)
+	case envOpChange:
+		return updateEnv(ctx, client, scope, c.live, c.value, c.Sensitive)
+	default:
+		return deleteEnv(ctx, client, scope, c.live)
+	}
+}
+
+// envSyncPlan is the change set that syncs a scope with a .env file
+type envSyncPlan struct {
+	ID        string
+	Changes   []envChange
+	Unchanged int
+}
+
+// diffEnvs compares the entries of a .env file with the live variables of a scope.
+// Keys that look like secrets, and keys already stored as sensitive, are synced as
+// sensitive. Live variables missing from the file are removed unless keepMissing is set.
+func diffEnvs(scope envScope, entries []dotenv.Entry, live []envVar, keepMissing bool) envSyncPlan {
+	liveByKey := make(map[string]envVar, len(live))
+	for _, env := range live {
+		liveByKey[env.Key] = env
+	}
+
+	plan := envSyncPlan{Changes: []envChange{}}
+	inFile := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		inFile[entry.Key] = true
+		sensitive := isSensitiveKey(entry.Key)
+		next := envVar{Key: entry.Key, Value: entry.Value, Sensitive: sensitive}
+
+		current, exists := liveByKey[entry.Key]
+		if !exists {
+			plan.Changes = append(plan.Changes, envChange{
+				Op: envOpAdd, Key: entry.Key, New: envDisplayValue(next, false), Sensitive: sensitive, value: entry.Value,
+			})
+			continue
+		}
+
+		next.Sensitive = sensitive || current.Sensitive
+		if current.Value == next.Value && current.Sensitive == next.Sensitive {
+			plan.Unchanged++
+			continue
+		}
+		change := envChange{
+			Op: envOpChange, Key: entry.Key, Sensitive: next.Sensitive, live: current, value: entry.Value,
+			Old: envDisplayValue(current, false), New: envDisplayValue(next, false),
+		}
+		if current.Value == next.Value {
+			change.Note = "becomes sensitive"
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if !keepMissing {
+		for _, env := range live {
+			if !inFile[env.Key] {
+				plan.Changes = append(plan.Changes, envChange{
+					Op: envOpRemove, Key: env.Key, Old: envDisplayValue(env, false), Sensitive: env.Sensitive, live: env,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return envOpOrder(plan.Changes[i].Op) < envOpOrder(plan.Changes[j].Op)
+	})
+	plan.ID = envPlanID(scope, plan.Changes)
+	return plan
+}
+
+// envOpOrder lists additions first, then changes, then removals
+func envOpOrder(op string) int {
+	switch op {
+	case envOpAdd:
+		return 0
+	case envOpChange:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// envPlanID fingerprints a change set, so a confirmation applies exactly the
+// changes that were previewed. Values are hashed rather than included.
+func envPlanID(scope envScope, changes []envChange) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%s\n", scope.Kind, scope.ID)
+	for _, c := range changes {
+		value := sha256.Sum256([]byte(c.value))
+		fmt.Fprintf(h, "%s %s %x %t %s\n", c.Op, c.Key, value, c.Sensitive, c.live.ID)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// count returns the number of changes of one kind
+func (p envSyncPlan) count(op string) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary counts the changes in one line
+func (p envSyncPlan) Summary() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to remove, %d unchanged",
+		p.count(envOpAdd), p.count(envOpChange), p.count(envOpRemove), p.Unchanged)
+}
+
+// Format lists the changes as a diff
+func (p envSyncPlan) Format() string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		marker := ""
+		if c.Sensitive {
+			marker = " [sensitive]"
+		}
+		switch c.Op {
+		case envOpAdd:
+			b.WriteString(fmt.Sprintf("  + %s = %s%s\n", c.Key, c.New, marker))
+		case envOpChange:
+			if c.Note != "" {
+				b.WriteString(fmt.Sprintf("  ~ %s%s (%s)\n", c.Key, marker, c.Note))
+			} else {
+				b.WriteString(fmt.Sprintf("  ~ %s: %s -> %s%s\n", c.Key, c.Old, c.New, marker))
+			}
+		default:
+			b.WriteString(fmt.Sprintf("  - %s = %s%s\n", c.Key, c.Old, marker))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/dotenv"
+)
+
+// changeKeys lists the operation and key of every change in order
+func changeKeys(plan envSyncPlan) []string {
+	keys := make([]string, len(plan.Changes))
+	for i, c := range plan.Changes {
+		keys[i] = c.Op + " " + c.Key
+	}
+	return keys
+}
+
+func TestDiffEnvs(t *testing.T) {
+	scope := envScope{Kind: "service", ID: "svc-1", Name: "app"}
+	entries := []dotenv.Entry{
+		{Key: "NODE_ENV", Value: "production"},
+		{Key: "DB_PASSWORD", Value: "new-secret"},
+		{Key: "PORT", Value: "3000"},
+		{Key: "API_URL", Value: "https://api.example.com"},
+		{Key: "MODE", Value: "fast"},
+	}
+	live := []envVar{
+		{ID: "1", Key: "STALE", Value: "old"},
+		{ID: "2", Key: "DB_PASSWORD", Value: "old-secret", Sensitive: true},
+		{ID: "3", Key: "PORT", Value: "3000"},
+		{ID: "4", Key: "MODE", Value: "fast", Sensitive: false},
+		{ID: "5", Key: "API_URL", Value: "http://localhost"},
+	}
+
+	plan := diffEnvs(scope, entries, live, false)
+
+	want := []string{"add NODE_ENV", "change DB_PASSWORD", "change API_URL", "remove STALE"}
+	if got := changeKeys(plan); !slices.Equal(got, want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	if plan.Unchanged != 2 {
+		t.Errorf("unchanged = %d, want 2", plan.Unchanged)
+	}
+
+	password := plan.Changes[1]
+	if !password.Sensitive || password.Old != "********" || password.New != "********" || password.value != "new-secret" {
+		t.Errorf("password change = %+v, want it masked and sensitive", password)
+	}
+	if url := plan.Changes[2]; url.Old != "http://localhost" || url.New != "https://api.example.com" || url.live.ID != "5" {
+		t.Errorf("API_URL change = %+v", url)
+	}
+	if plan.Summary() != "1 to add, 2 to change, 1 to remove, 2 unchanged" {
+		t.Errorf("summary = %q", plan.Summary())
+	}
+}
+
+func TestDiffEnvsKeepMissing(t *testing.T) {
+	scope := envScope{Kind: "project", ID: "proj-1"}
+	live := []envVar{{ID: "1", Key: "STALE", Value: "old"}}
+
+	plan := diffEnvs(scope, []dotenv.Entry{{Key: "NEW", Value: "1"}}, live, true)
+
+	if got := changeKeys(plan); !slices.Equal(got, []string{"add NEW"}) {
+		t.Errorf("changes = %v, want only the addition", got)
+	}
+}
+
+func TestDiffEnvsMarksSecretsSensitive(t *testing.T) {
+	scope := envScope{Kind: "service", ID: "svc-1"}
+	live := []envVar{{ID: "1", Key: "STRIPE_TOKEN", Value: "tok"}}
+
+	plan := diffEnvs(scope, []dotenv.Entry{{Key: "STRIPE_TOKEN", Value: "tok"}}, live, false)
+
+	if len(plan.Changes) != 1 || plan.Changes[0].Note != "becomes sensitive" || !plan.Changes[0].Sensitive {
+		t.Errorf("changes = %+v, want the unchanged secret to become sensitive", plan.Changes)
+	}
+}
+
+func TestEnvPlanID(t *testing.T) {
+	scope := envScope{Kind: "service", ID: "svc-1"}
+	entries := []dotenv.Entry{{Key: "PORT", Value: "3000"}}
+	live := []envVar{{ID: "1", Key: "PORT", Value: "8080"}}
+
+	id := diffEnvs(scope, entries, live, false).ID
+	if id == "" || id != diffEnvs(scope, entries, live, false).ID {
+		t.Fatal("the same diff should get the same plan ID")
+	}
+
+	changed := []dotenv.Entry{{Key: "PORT", Value: "3001"}}
+	otherScope := envScope{Kind: "service", ID: "svc-2"}
+	replaced := []envVar{{ID: "2", Key: "PORT", Value: "8080"}}
+	for name, other := range map[string]string{
+		"value":    diffEnvs(scope, changed, live, false).ID,
+		"scope":    diffEnvs(otherScope, entries, live, false).ID,
+		"variable": diffEnvs(scope, entries, replaced, false).ID,
+	} {
+		if other == id {
+			t.Errorf("plan ID did not change with the %s", name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zeropsio/zerops-mcp-v3/internal/config"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// structuredKey is the result metadata key carrying the machine-readable payload
+const structuredKey = "zerops/structured"
+
+// defaultOutputFormat is used when a call does not pass the format parameter
+var defaultOutputFormat = config.OutputFormatText
+
+// ToolOutput is the stable JSON envelope returned when format is json
+type ToolOutput struct {
+	Tool  string             `json:"tool"`
+	OK    bool               `json:"ok"`
+	Data  interface{}        `json:"data,omitempty"`
+	Error *zerrors.ToolError `json:"error,omitempty"`
+}
+
+// SetDefaultOutputFormat sets the format used when a call does not request one
+func SetDefaultOutputFormat(format string) {
+	if format != "" {
+		defaultOutputFormat = format
+	}
+}
+
+// addTool registers a tool with the shared format and profile parameters and output rendering
+func addTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	mcp.WithString("format",
+		mcp.Enum(config.OutputFormatText, config.OutputFormatJSON),
+		mcp.Description("Output format: 'text' for human-readable output, 'json' for a stable machine-readable envelope"),
+	)(&tool)
+	profileOption()(&tool)
+
+	s.AddTool(tool, formatHandler(tool.Name, profileHandler(handler)))
+}
+
+// formatHandler renders the handler result in the requested output format
+func formatHandler(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		format := request.GetString("format", defaultOutputFormat)
+		if format != config.OutputFormatText && format != config.OutputFormatJSON {
+			result := ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_FORMAT",
+				fmt.Sprintf("Unsupported output format '%s'", format),
+				"Use 'text' or 'json'",
+			))
+			takeStructured(result)
+			return result, nil
+		}
+
+		result, err := handler(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		data, hasData := takeStructured(result)
+		if format != config.OutputFormatJSON {
+			return result, nil
+		}
+		return renderJSON(name, result, data, hasData), nil
+	}
+}
+
+// renderJSON replaces the prose content with the JSON envelope.
+// mcp-go does not expose structuredContent yet, so the envelope is sent as text.
+func renderJSON(name string, result *mcp.CallToolResult, data interface{}, hasData bool) *mcp.CallToolResult {
+	output := ToolOutput{Tool: name, OK: !result.IsError}
+
+	switch {
+	case result.IsError:
+		toolErr, ok := data.(*zerrors.ToolError)
+		if !ok {
+			toolErr = zerrors.New(zerrors.CategoryInternal, "TOOL_ERROR", resultText(result), "")
+		}
+		output.Error = toolErr
+	case hasData:
+		output.Data = data
+	default:
+		output.Data = map[string]interface{}{"text": resultText(result)}
+	}
+
+	encoded, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return ToolErrorResponse(zerrors.NewInternalError(err))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{mcp.NewTextContent(string(encoded))},
+		IsError: result.IsError,
+	}
+}
+
+// StructuredResponse creates a text response that also carries a machine-readable payload
+func StructuredResponse(text string, data interface{}) *mcp.CallToolResult {
+	return withStructured(mcp.NewToolResultText(text), data)
+}
+
+// withStructured attaches the machine-readable payload to a result
+func withStructured(result *mcp.CallToolResult, data interface{}) *mcp.CallToolResult {
+	if result.Meta == nil {
+		result.Meta = make(map[string]any)
+	}
+	result.Meta[structuredKey] = data
+	return result
+}
+
+// takeStructured removes and returns the machine-readable payload of a result
+func takeStructured(result *mcp.CallToolResult) (interface{}, bool) {
+	data, ok := result.Meta[structuredKey]
+	delete(result.Meta, structuredKey)
+	if len(result.Meta) == 0 {
+		result.Meta = nil
+	}
+	return data, ok
+}
+
+// resultText joins the text content of a result
+func resultText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,59 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+func TestJSONFormatReturnsStructuredData(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	c := startTools(t, sim)
+
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "zerops.yml"), []byte("zerops:\n  - setup: app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []struct {
+		name string
+		args map[string]interface{}
+		keys []string
+	}{
+		{"region_list", nil, []string{"regions"}},
+		{"deploy_validate", map[string]interface{}{"working_dir": workDir}, []string{"valid", "file_count"}},
+		{"deploy_troubleshoot", map[string]interface{}{"service_id": app.ID, "error_message": "vpn connection lost"}, []string{"checks", "service", "detected_issues"}},
+		{"config_nginx", map[string]interface{}{"framework": "laravel"}, []string{"framework", "template"}},
+		{"knowledge_get_runtime", map[string]interface{}{"runtime": "nodejs"}, nil},
+		{"knowledge_search_patterns", map[string]interface{}{"tags": "laravel"}, []string{"count", "patterns"}},
+		{"knowledge_validate_config", map[string]interface{}{"config": "zerops:\n  - setup: app\n"}, nil},
+		{"knowledge_get_docs", map[string]interface{}{"section": "no such section"}, []string{"found", "content"}},
+	}
+
+	for _, call := range calls {
+		out := callJSON(t, c, call.name, call.args)
+		if !out.OK {
+			t.Errorf("%s failed: %+v", call.name, out.Error)
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(out.Data, &fields); err != nil || len(fields) == 0 {
+			t.Errorf("%s returned no structured data: %s", call.name, out.Data)
+			continue
+		}
+		if _, prose := fields["text"]; prose && len(fields) == 1 {
+			t.Errorf("%s returned prose instead of structured data", call.name)
+		}
+		for _, key := range call.keys {
+			if _, ok := fields[key]; !ok {
+				t.Errorf("%s data has no %q field: %s", call.name, key, out.Data)
+			}
+		}
+	}
+}
@@ -8,11 +8,12 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
 )
 
 // RegisterKnowledgeTools registers knowledge retrieval tools
-func RegisterKnowledgeTools(s *server.MCPServer) {
+func RegisterKnowledgeTools(s *server.MCPServer, logRules *knowledge.LogRuleSet) {
 	// Runtime tool
 	runtimeTool := mcp.NewTool(
 		"knowledge_get_runtime",
@@ -23,24 +24,32 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		),
 	)
 
-	s.AddTool(runtimeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, runtimeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		runtimeName, err := request.RequireString("runtime")
 		if err != nil {
-			return mcp.NewToolResultError("runtime parameter required"), nil
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_RUNTIME",
+				"Runtime name is required",
+				"Provide a runtime name such as 'nodejs', 'python' or 'php'",
+			)), nil
 		}
 
 		runtime, err := knowledge.GetRuntime(runtimeName)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"RUNTIME_NOT_FOUND",
+				err.Error(),
+				"Use 'knowledge_search_patterns' to find supported runtimes",
+			)), nil
 		}
 
 		// Return as formatted JSON
 		data, err := json.MarshalIndent(runtime, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError("failed to format response"), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
-		return mcp.NewToolResultText(string(data)), nil
+		return StructuredResponse(string(data), runtime), nil
 	})
 
 	// Pattern search tool
@@ -52,7 +61,7 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		),
 	)
 
-	s.AddTool(searchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, searchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Parse tags parameter
 		requirements := []string{}
 		tagsParam := request.GetString("tags", "")
@@ -69,12 +78,16 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 
 		patterns, err := knowledge.SearchPatterns(requirements)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
 		// Format response for better LLM consumption
 		if len(patterns) == 0 {
-			return mcp.NewToolResultText("No deployment patterns found. Try different search terms or use 'knowledge_list_services' to see available services."), nil
+			return StructuredResponse("No deployment patterns found. Try different search terms or use 'knowledge_list_services' to see available services.", map[string]interface{}{
+				"count":    0,
+				"patterns": []interface{}{},
+				"tags":     requirements,
+			}), nil
 		}
 
 		var response strings.Builder
@@ -133,7 +146,11 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		response.WriteString("- Extract the services array from the JSON above\n")
 		response.WriteString("- Convert to YAML for project_import\n")
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"count":    len(patterns),
+			"patterns": patterns,
+			"tags":     requirements,
+		}), nil
 	})
 
 	// Validation tool
@@ -146,20 +163,24 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		),
 	)
 
-	s.AddTool(validateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, validateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		config, err := request.RequireString("config")
 		if err != nil {
-			return mcp.NewToolResultError("config parameter required"), nil
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_CONFIG",
+				"Configuration content is required",
+				"Provide the zerops.yml content to validate",
+			)), nil
 		}
 
 		result := knowledge.ValidateConfig(config)
 
 		data, err := json.MarshalIndent(result, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError("failed to format response"), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
-		return mcp.NewToolResultText(string(data)), nil
+		return StructuredResponse(string(data), result), nil
 	})
 
 	// Dependency resolution tool
@@ -172,7 +193,7 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		),
 	)
 
-	s.AddTool(resolveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, resolveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// For now, create example services
 		services := []map[string]interface{}{
 			{"name": "api", "type": "nodejs@20"},
@@ -183,10 +204,10 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 
 		data, err := json.MarshalIndent(resolution, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError("failed to format response"), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
-		return mcp.NewToolResultText(string(data)), nil
+		return StructuredResponse(string(data), resolution), nil
 	})
 	
 	// Service knowledge tool
@@ -199,23 +220,31 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		),
 	)
 
-	s.AddTool(serviceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceType, err := request.RequireString("service")
 		if err != nil {
-			return mcp.NewToolResultError("service parameter required"), nil
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_SERVICE_TYPE",
+				"Service type is required",
+				"Provide a service type such as 'nodejs@20' or 'postgresql@16'",
+			)), nil
 		}
 
 		service, err := knowledge.GetService(serviceType)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"SERVICE_TYPE_NOT_FOUND",
+				err.Error(),
+				"Use 'knowledge_list_services' to see available service types",
+			).WithNextTool("knowledge_list_services")), nil
 		}
 
 		data, err := json.MarshalIndent(service, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError("failed to format response"), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
-		return mcp.NewToolResultText(string(data)), nil
+		return StructuredResponse(string(data), service), nil
 	})
 	
 	// Service list tool
@@ -224,20 +253,61 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		mcp.WithDescription("List all available Zerops services"),
 	)
 
-	s.AddTool(serviceListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		services, err := knowledge.GetAllServices()
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
 		data, err := json.MarshalIndent(services, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError("failed to format response"), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 
-		return mcp.NewToolResultText(string(data)), nil
+		return StructuredResponse(string(data), services), nil
 	})
 	
+	// Log rules tool
+	logRulesTool := mcp.NewTool(
+		"knowledge_log_rules",
+		mcp.WithDescription("List the log analysis rules used to detect known issues in runtime and build logs, including custom rule files"),
+		mcp.WithString("service",
+			mcp.Description("Only rules for this service type (e.g., 'nodejs@20'); common rules are always included"),
+		),
+		mcp.WithString("source",
+			mcp.Enum(knowledge.LogSourceRuntime, knowledge.LogSourceBuild),
+			mcp.Description("Only rules for runtime or build logs"),
+		),
+	)
+
+	addTool(s, logRulesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		serviceType := request.GetString("service", "")
+		source := request.GetString("source", "")
+
+		rules := logRules.Rules(serviceType, source)
+
+		var response strings.Builder
+		response.WriteString(fmt.Sprintf("Log analysis rules (%d):\n", len(rules)))
+		scope := ""
+		for _, rule := range rules {
+			if rule.Scope != scope {
+				scope = rule.Scope
+				response.WriteString(fmt.Sprintf("\n%s:\n", scope))
+			}
+			sources := "runtime, build"
+			if len(rule.Sources) > 0 {
+				sources = strings.Join(rule.Sources, ", ")
+			}
+			response.WriteString(fmt.Sprintf("- %s [%s] %s (%s)\n", rule.ID, rule.Severity, rule.Title, sources))
+		}
+		response.WriteString("\nAdd team rules with ZEROPS_MCP_LOG_RULES (rule files or directories, JSON or YAML)\n")
+
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"count": len(rules),
+			"rules": rules,
+		}), nil
+	})
+
 	// Knowledge base tool
 	kbTool := mcp.NewTool(
 		"knowledge_get_docs",
@@ -247,12 +317,12 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 		),
 	)
 
-	s.AddTool(kbTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, kbTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		section := request.GetString("section", "")
 		
 		kb, err := knowledge.GetKnowledgeBase()
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return ToolErrorResponse(zerrors.NewInternalError(err)), nil
 		}
 		
 		// If section requested, try to extract it
@@ -277,10 +347,19 @@ func RegisterKnowledgeTools(s *server.MCPServer) {
 			}
 			
 			if extractedSection.Len() > 0 {
-				return mcp.NewToolResultText(extractedSection.String()), nil
+				return StructuredResponse(extractedSection.String(), map[string]interface{}{
+					"section": section,
+					"found":   true,
+					"content": extractedSection.String(),
+				}), nil
 			}
 		}
 
-		return mcp.NewToolResultText(kb), nil
+		// Without a matching section the whole knowledge base is returned
+		return StructuredResponse(kb, map[string]interface{}{
+			"section": section,
+			"found":   section == "",
+			"content": kb,
+		}), nil
 	})
 }
\ No newline at end of file
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+	cursorPrefix     = "offset:"
+)
+
+// listQuery holds the filter, sort and pagination parameters shared by list tools
+type listQuery struct {
+	Name       string
+	Status     string
+	Tag        string
+	Type       string
+	Sort       string
+	Descending bool
+	Limit      int
+	Offset     int
+}
+
+// listOptions returns the tool parameters shared by list tools
+func listOptions(sortFields []string, defaultSort string) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("name",
+			mcp.Description("Only include items whose name contains this text (case-insensitive)"),
+		),
+		mcp.WithString("status",
+			mcp.Description("Only include items with this status (e.g., 'ACTIVE', 'RUNNING', 'STOPPED')"),
+		),
+		mcp.WithString("sort",
+			mcp.Enum(sortFields...),
+			mcp.Description(fmt.Sprintf("Sort field (default: %s)", defaultSort)),
+		),
+		mcp.WithString("order",
+			mcp.Enum("asc", "desc"),
+			mcp.Description("Sort order (default: desc for created, asc otherwise)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of items to return (default: %d, max: %d)", defaultListLimit, maxListLimit)),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Cursor from a previous response to fetch the next page"),
+		),
+	}
+}
+
+// parseListQuery reads and validates list parameters from a request
+func parseListQuery(request mcp.CallToolRequest, sortFields []string, defaultSort string) (listQuery, *mcp.CallToolResult) {
+	q := listQuery{
+		Name:   strings.TrimSpace(request.GetString("name", "")),
+		Status: strings.TrimSpace(request.GetString("status", "")),
+		Tag:    strings.TrimSpace(request.GetString("tag", "")),
+		Type:   strings.TrimSpace(request.GetString("type", "")),
+		Sort:   request.GetString("sort", defaultSort),
+		Limit:  request.GetInt("limit", defaultListLimit),
+	}
+
+	validSort := false
+	for _, field := range sortFields {
+		if q.Sort == field {
+			validSort = true
+			break
+		}
+	}
+	if !validSort {
+		return q, ToolErrorResponse(zerrors.NewValidationError(
+			"INVALID_SORT",
+			fmt.Sprintf("Cannot sort by '%s'", q.Sort),
+			fmt.Sprintf("Use one of: %s", strings.Join(sortFields, ", ")),
+		))
+	}
+
+	order := request.GetString("order", "")
+	switch order {
+	case "":
+		q.Descending = q.Sort == "created"
+	case "asc", "desc":
+		q.Descending = order == "desc"
+	default:
+		return q, ToolErrorResponse(zerrors.NewValidationError(
+			"INVALID_ORDER",
+			fmt.Sprintf("Invalid sort order '%s'", order),
+			"Use 'asc' or 'desc'",
+		))
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultListLimit
+	}
+	if q.Limit > maxListLimit {
+		q.Limit = maxListLimit
+	}
+
+	if cursor := request.GetString("cursor", ""); cursor != "" {
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return q, ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_CURSOR",
+				"The cursor is not valid",
+				"Pass the cursor exactly as returned by the previous call, or omit it to start from the beginning",
+			))
+		}
+		q.Offset = offset
+	}
+
+	return q, nil
+}
+
+// matchesText reports whether value contains the filter text, ignoring case
+func matchesText(value, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(value), strings.ToLower(filter))
+}
+
+// matchesExact reports whether value equals the filter, ignoring case
+func matchesExact(value, filter string) bool {
+	return filter == "" || strings.EqualFold(value, filter)
+}
+
+// sortItems orders items by the query's sort field using the given comparators
+func sortItems[T any](items []T, q listQuery, less map[string]func(a, b T) bool) {
+	cmp, ok := less[q.Sort]
+	if !ok {
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if q.Descending {
+			return cmp(items[j], items[i])
+		}
+		return cmp(items[i], items[j])
+	})
+}
+
+// pageItems returns the page selected by the query and the cursor for the next one
+func pageItems[T any](items []T, q listQuery) ([]T, string) {
+	start := q.Offset
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + q.Limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	nextCursor := ""
+	if end < len(items) {
+		nextCursor = encodeCursor(end)
+	}
+	return items[start:end], nextCursor
+}
+
+// encodeCursor builds an opaque cursor for the given offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := strings.CutPrefix(string(data), cursorPrefix)
+	if !ok {
+		return 0, fmt.Errorf("unknown cursor format")
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor offset")
+	}
+	return offset, nil
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
+)
+
+// AnalyzeLogs matches the log rules for a service type and log source against log entries.
+// An empty service type applies the rules of every runtime.
+func AnalyzeLogs(logRules *knowledge.LogRuleSet, entries []api.LogEntry, serviceType, source string) []knowledge.LogRuleMatch {
+	if logRules == nil || len(entries) == 0 {
+		return nil
+	}
+	lines := make([]knowledge.LogLine, 0, len(entries))
+	for _, entry := range entries {
+		text := entry.Message
+		if text == "" {
+			text = entry.Content
+		}
+		lines = append(lines, knowledge.LogLine{Time: entry.Timestamp, Text: text})
+	}
+	return logRules.Match(lines, serviceType, source)
+}
+
+// FormatLogAnalysis renders matched log rules as a detected issues section
+func FormatLogAnalysis(matches []knowledge.LogRuleMatch) string {
+	if len(matches) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nDetected Issues:\n")
+	for _, m := range matches {
+		b.WriteString(fmt.Sprintf("- [%s] %s (%d×", strings.ToUpper(m.Rule.Severity), m.Rule.Title, m.Count))
+		if !m.First.IsZero() {
+			if m.Count == 1 {
+				b.WriteString(fmt.Sprintf(", at %s", m.First.Format("2006-01-02 15:04:05")))
+			} else {
+				b.WriteString(fmt.Sprintf(", first %s, last %s", m.First.Format("2006-01-02 15:04:05"), m.Last.Format("2006-01-02 15:04:05")))
+			}
+		}
+		b.WriteString(")\n")
+		b.WriteString(fmt.Sprintf("  Example: %s\n", m.Example))
+		b.WriteString(fmt.Sprintf("  Resolution: %s\n", m.Rule.Resolution))
+		if m.Rule.Docs != "" {
+			b.WriteString(fmt.Sprintf("  Docs: %s\n", m.Rule.Docs))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// lookupServiceType returns the type of a service (e.g. "nodejs@20"), or "" if it cannot be read
+func lookupServiceType(ctx context.Context, client api.ZeropsAPI, serviceID string) string {
+	service, err := client.GetService(ctx, serviceID)
+	if err != nil {
+		return ""
+	}
+	return service.ServiceStackTypeInfo.ServiceStackTypeVersionName
+}
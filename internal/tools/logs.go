@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// parseLogQuery reads and validates the log filter parameters shared by log tools
+func parseLogQuery(request mcp.CallToolRequest, defaultLimit int) (api.LogQuery, *mcp.CallToolResult) {
+	query := api.LogQuery{
+		Container: strings.TrimSpace(request.GetString("container", "")),
+		Text:      strings.TrimSpace(request.GetString("search", "")),
+		Pattern:   request.GetString("pattern", ""),
+		Cursor:    strings.TrimSpace(request.GetString("cursor", "")),
+		Limit:     request.GetInt("limit", defaultLimit),
+	}
+	if query.Cursor != "" {
+		if _, _, err := api.ParseLogCursor(query.Cursor); err != nil {
+			return query, ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_CURSOR",
+				fmt.Sprintf("The cursor is not valid: %v", err),
+				"Pass the next_cursor exactly as returned by the previous call, or omit it to start from the recent lines",
+			))
+		}
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Limit > api.MaxLogLimit {
+		query.Limit = api.MaxLogLimit
+	}
+
+	if severity := strings.TrimSpace(request.GetString("severity", "")); severity != "" {
+		level, err := api.ParseSeverity(severity)
+		if err != nil {
+			return query, ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_SEVERITY",
+				err.Error(),
+				fmt.Sprintf("Use one of: %s", strings.Join(api.SeverityNames(), ", ")),
+			))
+		}
+		query.MinSeverity = api.SeverityName(level)
+	}
+
+	if query.Pattern != "" {
+		if _, err := regexp.Compile(query.Pattern); err != nil {
+			return query, ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_PATTERN",
+				fmt.Sprintf("Invalid regular expression: %v", err),
+				"Use Go regular expression syntax (e.g., 'timeout|refused', '(?i)panic')",
+			))
+		}
+	}
+
+	now := time.Now()
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{
+		{"since", &query.Since},
+		{"until", &query.Until},
+	} {
+		t, err := api.ParseLogTime(request.GetString(bound.name, ""), now)
+		if err != nil {
+			return query, ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_TIME",
+				fmt.Sprintf("Invalid '%s': %v", bound.name, err),
+				"Use an RFC3339 timestamp (e.g., '2024-01-02T15:04:05Z') or a duration (e.g., '15m', '2h')",
+			))
+		}
+		*bound.target = t
+	}
+
+	if !query.Since.IsZero() && !query.Until.IsZero() && query.Until.Before(query.Since) {
+		return query, ToolErrorResponse(zerrors.NewValidationError(
+			"INVALID_TIME_RANGE",
+			"'until' is before 'since'",
+			"Swap the values or widen the time range",
+		))
+	}
+
+	return query, nil
+}
+
+// describeLogQuery summarizes the active filters of a log query
+func describeLogQuery(query api.LogQuery) string {
+	var parts []string
+	if query.Container != "" {
+		parts = append(parts, fmt.Sprintf("container: %s", query.Container))
+	}
+	if query.MinSeverity != "" {
+		parts = append(parts, fmt.Sprintf("severity: %s or worse", query.MinSeverity))
+	}
+	if !query.Since.IsZero() {
+		parts = append(parts, fmt.Sprintf("since: %s", query.Since.Format(time.RFC3339)))
+	}
+	if !query.Until.IsZero() {
+		parts = append(parts, fmt.Sprintf("until: %s", query.Until.Format(time.RFC3339)))
+	}
+	if query.Text != "" {
+		parts = append(parts, fmt.Sprintf("search: %q", query.Text))
+	}
+	if query.Pattern != "" {
+		parts = append(parts, fmt.Sprintf("pattern: %s", query.Pattern))
+	}
+	return strings.Join(parts, ", ")
+}
+
+const (
+	defaultFollowDuration = 30 * time.Second
+	maxFollowDuration     = 5 * time.Minute
+	defaultFollowInterval = 2 * time.Second
+	minFollowInterval     = 500 * time.Millisecond
+	maxFollowErrors       = 3
+	maxFollowLines        = 1000
+)
+
+// logFollowResult summarizes a follow session
+type logFollowResult struct {
+	Entries    []api.LogEntry
+	Streamed   int
+	NextCursor string
+	StopReason string
+	LastError  error
+}
+
+// followLogs polls the log proxy for entries newer than the query cursor until
+// the duration elapses or ctx is cancelled, streaming each new line to the client
+func followLogs(ctx context.Context, client api.ZeropsAPI, query api.LogQuery, duration, interval time.Duration, n *notifier) logFollowResult {
+	result := logFollowResult{NextCursor: query.Cursor, StopReason: "duration elapsed"}
+	seen := make(map[string]bool)
+	if _, id, err := api.ParseLogCursor(query.Cursor); err == nil {
+		// The cursor entry was already delivered by the call that returned the cursor
+		seen[id] = true
+	}
+
+	// Take the oldest lines after the cursor, so a burst longer than one poll is
+	// streamed over the next polls instead of skipped
+	query.Oldest = true
+
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			result.StopReason = "cancelled"
+			return result
+		case <-deadline.C:
+			return result
+		case <-ticker.C:
+		}
+
+		query.Cursor = result.NextCursor
+		entries, err := client.GetServiceLogs(ctx, query)
+		if err != nil {
+			if ctx.Err() != nil {
+				result.StopReason = "cancelled"
+				return result
+			}
+			failures++
+			result.LastError = err
+			if failures >= maxFollowErrors {
+				result.StopReason = fmt.Sprintf("stopped after %d failed polls", failures)
+				return result
+			}
+			continue
+		}
+		failures = 0
+
+		for _, entry := range entries {
+			// The cursor may fall out of the proxy window, so skip lines already streamed
+			if seen[entry.ID] {
+				continue
+			}
+			seen[entry.ID] = true
+			result.NextCursor = api.LogCursor(entry)
+			result.Streamed++
+
+			line := entry.String()
+			n.Progress(line)
+			n.Log(logLevel(entry.Severity), map[string]any{
+				"id":       entry.ID,
+				"hostname": entry.Hostname,
+				"tag":      entry.Tag,
+				"severity": entry.SeverityName(),
+				"message":  entry.Message,
+			})
+
+			result.Entries = append(result.Entries, entry)
+			if len(result.Entries) > maxFollowLines {
+				result.Entries = result.Entries[len(result.Entries)-maxFollowLines:]
+			}
+		}
+	}
+}
+
+// logLevels maps syslog severities to MCP logging levels
+var logLevels = map[int]mcp.LoggingLevel{
+	0: mcp.LoggingLevelEmergency,
+	1: mcp.LoggingLevelAlert,
+	2: mcp.LoggingLevelCritical,
+	3: mcp.LoggingLevelError,
+	4: mcp.LoggingLevelWarning,
+	5: mcp.LoggingLevelNotice,
+	6: mcp.LoggingLevelInfo,
+	7: mcp.LoggingLevelDebug,
+}
+
+// logLevel returns the MCP logging level of a syslog severity; unknown severities are info
+func logLevel(severity int) mcp.LoggingLevel {
+	if level, ok := logLevels[severity]; ok {
+		return level
+	}
+	return mcp.LoggingLevelInfo
+}
+
+// followDuration reads a duration in seconds and clamps it to the given bounds
+func followDuration(request mcp.CallToolRequest, key string, def, lo, hi time.Duration) time.Duration {
+	seconds := request.GetFloat(key, def.Seconds())
+	d := time.Duration(seconds * float64(time.Second))
+	if d <= 0 {
+		d = def
+	}
+	if d < lo {
+		d = lo
+	}
+	if d > hi {
+		d = hi
+	}
+	return d
+}
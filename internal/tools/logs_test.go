@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		severity int
+		want     mcp.LoggingLevel
+	}{
+		{0, mcp.LoggingLevelEmergency},
+		{3, mcp.LoggingLevelError},
+		{4, mcp.LoggingLevelWarning},
+		{7, mcp.LoggingLevelDebug},
+		{-1, mcp.LoggingLevelInfo},
+		{8, mcp.LoggingLevelInfo},
+		{42, mcp.LoggingLevelInfo},
+	}
+	for _, tt := range tests {
+		if got := logLevel(tt.severity); got != tt.want {
+			t.Errorf("logLevel(%d) = %s, want %s", tt.severity, got, tt.want)
+		}
+	}
+}
+
+// followSetup starts a fake API with a deployed service that logged a line, and
+// returns a client and a query resuming after that line
+func followSetup(t *testing.T) (*apitest.Server, api.ZeropsAPI, api.LogQuery) {
+	t.Helper()
+	sim := apitest.NewServer()
+	t.Cleanup(sim.Close)
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "already seen"})
+
+	client := api.NewClient(api.ClientOptions{BaseURL: sim.URL, APIKey: sim.APIKey, Timeout: 5 * time.Second})
+	query := api.LogQuery{ServiceID: app.ID, Limit: 2}
+	seen, err := client.GetServiceLogs(context.Background(), query)
+	if err != nil || len(seen) == 0 {
+		t.Fatalf("initial logs = %v, %v", seen, err)
+	}
+	query.Cursor = api.LogCursor(seen[len(seen)-1])
+	return sim, client, query
+}
+
+func TestFollowLogsStreamsEveryNewLine(t *testing.T) {
+	sim, client, query := followSetup(t)
+	// More new lines than fit in one poll
+	for _, message := range []string{"one", "two", "three", "four", "five"} {
+		sim.AddLogs(query.ServiceID, apitest.LogItem{Message: message})
+	}
+
+	result := followLogs(context.Background(), client, query, 300*time.Millisecond, 20*time.Millisecond, &notifier{})
+	var messages []string
+	for _, entry := range result.Entries {
+		messages = append(messages, entry.Message)
+	}
+	if got := strings.Join(messages, ", "); got != "one, two, three, four, five" {
+		t.Errorf("followed lines = %s, want every new line once and in order", got)
+	}
+	if result.Streamed != 5 || result.StopReason != "duration elapsed" {
+		t.Errorf("streamed %d lines, stopped on %q", result.Streamed, result.StopReason)
+	}
+	if _, id, err := api.ParseLogCursor(result.NextCursor); err != nil || id != result.Entries[4].ID {
+		t.Errorf("next cursor points at %q (%v), want the last line", id, err)
+	}
+}
+
+func TestFollowLogsStopsAfterFailedPolls(t *testing.T) {
+	sim, client, query := followSetup(t)
+	sim.InjectError(apitest.InjectedError{Method: "GET", Path: "/api/rest/log", StatusCode: 500, Code: "injected", Message: "log proxy down"})
+
+	result := followLogs(context.Background(), client, query, 5*time.Second, 10*time.Millisecond, &notifier{})
+	if result.StopReason != "stopped after 3 failed polls" || result.LastError == nil {
+		t.Errorf("stop reason = %q, last error %v", result.StopReason, result.LastError)
+	}
+	if result.NextCursor != query.Cursor {
+		t.Error("a failed follow must keep the cursor it started from")
+	}
+}
+
+func TestFollowLogsStopsWhenCancelled(t *testing.T) {
+	_, client, query := followSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	result := followLogs(ctx, client, query, 5*time.Second, 10*time.Millisecond, &notifier{})
+	if result.StopReason != "cancelled" {
+		t.Errorf("stop reason = %q, want cancelled", result.StopReason)
+	}
+}
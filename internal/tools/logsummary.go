@@ -0,0 +1,317 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
+)
+
+const (
+	defaultMaxClusters = 20
+	maxSignatureLength = 200
+	maxExemplarLines   = 15
+	maxStackTraceGap   = 2 * time.Second
+)
+
+// Normalizers replace the variable parts of a message, in order
+var logNormalizers = []struct {
+	re          *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<ts>"},
+	{regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2}( [+-]\d{4})?`), "<ts>"},
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`), "<time>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<hex>"},
+}
+
+var (
+	// idToken matches long tokens that mix letters and digits, such as request IDs and hashes
+	idToken      = regexp.MustCompile(`\b[\w-]{8,}\b`)
+	numberToken  = regexp.MustCompile(`\d+(\.\d+)?`)
+	spaceRun     = regexp.MustCompile(`\s+`)
+	continuation = regexp.MustCompile(`^(\s+\S|\s*at \S|\s*\.\.\. \d+ more|Caused by: |goroutine \d+ \[)`)
+	stackFrame   = regexp.MustCompile(`^(\s*at \S|\s*File "|\s*\.\.\. \d+ more|Caused by: |\t|goroutine \d+ \[)`)
+	stackStart   = regexp.MustCompile(`^(Traceback \(most recent call last\)|panic: |Exception in thread |goroutine \d+ \[)`)
+	stackChained = regexp.MustCompile(`^(During handling of the above exception|The above exception was the direct cause)`)
+	stackCall    = regexp.MustCompile(`^[\w./*()\[\]-]+\(.*\)$`)
+	exceptionEnd = regexp.MustCompile(`^[\w.]+(Error|Exception|Exit|Interrupt|Warning)\b`)
+)
+
+// normalizeLogMessage strips timestamps, IDs and numbers so similar messages compare equal
+func normalizeLogMessage(message string) string {
+	for _, n := range logNormalizers {
+		message = n.re.ReplaceAllString(message, n.placeholder)
+	}
+	message = idToken.ReplaceAllStringFunc(message, func(token string) string {
+		digits, letters := 0, 0
+		for _, r := range token {
+			switch {
+			case r >= '0' && r <= '9':
+				digits++
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+				letters++
+			}
+		}
+		if digits >= 2 && letters > 0 {
+			return "<id>"
+		}
+		return token
+	})
+	message = numberToken.ReplaceAllString(message, "<n>")
+	message = strings.TrimSpace(spaceRun.ReplaceAllString(message, " "))
+	// Cut on a rune boundary so multi-byte characters stay valid UTF-8
+	if runes := []rune(message); len(runes) > maxSignatureLength {
+		message = string(runes[:maxSignatureLength]) + "…"
+	}
+	return message
+}
+
+// logUnit is one log message, or a multi-line message such as a stack trace
+// spread over consecutive entries
+type logUnit struct {
+	Entries []api.LogEntry
+	Stack   bool
+}
+
+// title returns the line that identifies the unit. Python tracebacks name the
+// exception on their last line; other traces and messages on their first.
+func (u logUnit) title() string {
+	head := u.Entries[0].Message
+	if u.Stack && strings.HasPrefix(head, "Traceback") {
+		for i := len(u.Entries) - 1; i > 0; i-- {
+			if msg := u.Entries[i].Message; exceptionEnd.MatchString(msg) {
+				return msg
+			}
+		}
+	}
+	return head
+}
+
+// continues reports whether an entry continues the message of the unit
+func (u logUnit) continues(entry api.LogEntry) bool {
+	last := u.Entries[len(u.Entries)-1]
+	if entry.Hostname != last.Hostname || entry.Timestamp.Sub(last.Timestamp) > maxStackTraceGap {
+		return false
+	}
+	msg := entry.Message
+	if continuation.MatchString(msg) {
+		return true
+	}
+	if !u.Stack && !stackStart.MatchString(u.Entries[0].Message) {
+		return false
+	}
+	// Inside a trace: blank separators, chained exceptions, Go call lines and the final exception line
+	return strings.TrimSpace(msg) == "" || stackChained.MatchString(msg) ||
+		stackCall.MatchString(msg) || (strings.HasPrefix(u.Entries[0].Message, "Traceback") && exceptionEnd.MatchString(msg))
+}
+
+// joinStackTraces groups the entries of each multi-line message, such as a stack trace, into one unit
+func joinStackTraces(entries []api.LogEntry) []logUnit {
+	var units []logUnit
+	for _, entry := range entries {
+		if n := len(units); n > 0 && units[n-1].continues(entry) {
+			units[n-1].Entries = append(units[n-1].Entries, entry)
+			units[n-1].Stack = units[n-1].Stack || stackFrame.MatchString(entry.Message)
+			continue
+		}
+		units = append(units, logUnit{Entries: []api.LogEntry{entry}, Stack: stackStart.MatchString(entry.Message)})
+	}
+	return units
+}
+
+// logCluster groups messages that differ only in timestamps, IDs and numbers
+type logCluster struct {
+	Signature  string    `json:"signature"`
+	Count      int       `json:"count"`
+	Lines      int       `json:"lines"`
+	Severity   string    `json:"severity"`
+	First      time.Time `json:"first"`
+	Last       time.Time `json:"last"`
+	FirstID    string    `json:"first_id"`
+	LastID     string    `json:"last_id"`
+	Hostnames  []string  `json:"hostnames"`
+	StackTrace bool      `json:"stack_trace"`
+	Exemplar   string    `json:"exemplar"`
+
+	level int
+}
+
+// logSummary is a compact description of a window of log entries
+type logSummary struct {
+	Lines       int            `json:"lines"`
+	Messages    int            `json:"messages"`
+	StackTraces int            `json:"stack_traces"`
+	First       time.Time      `json:"first,omitempty"`
+	Last        time.Time      `json:"last,omitempty"`
+	BySeverity  map[string]int `json:"by_severity"`
+	Clusters    []logCluster   `json:"clusters"`
+	Omitted     int            `json:"omitted_clusters"`
+}
+
+// summarizeLogs clusters log entries by their normalized message, most severe and
+// most frequent first, and keeps at most maxClusters clusters
+func summarizeLogs(entries []api.LogEntry, maxClusters int) logSummary {
+	summary := logSummary{Lines: len(entries), BySeverity: make(map[string]int)}
+	if len(entries) > 0 {
+		summary.First = entries[0].Timestamp
+		summary.Last = entries[len(entries)-1].Timestamp
+	}
+	for _, entry := range entries {
+		summary.BySeverity[entry.SeverityName()]++
+	}
+
+	units := joinStackTraces(entries)
+	summary.Messages = len(units)
+
+	byKey := make(map[string]*logCluster)
+	var clusters []*logCluster
+	for _, unit := range units {
+		head := unit.Entries[0]
+		signature := normalizeLogMessage(unit.title())
+		key := fmt.Sprintf("%t|%s", unit.Stack, signature)
+
+		c, ok := byKey[key]
+		if !ok {
+			c = &logCluster{
+				Signature:  signature,
+				StackTrace: unit.Stack,
+				Exemplar:   unitText(unit),
+				First:      head.Timestamp,
+				FirstID:    head.ID,
+				level:      head.Severity,
+			}
+			byKey[key] = c
+			clusters = append(clusters, c)
+		}
+		if unit.Stack {
+			summary.StackTraces++
+		}
+		c.Count++
+		c.Lines += len(unit.Entries)
+		c.Last = head.Timestamp
+		c.LastID = head.ID
+		for _, entry := range unit.Entries {
+			if entry.Severity < c.level {
+				c.level = entry.Severity
+			}
+		}
+		if !containsString(c.Hostnames, head.Hostname) {
+			c.Hostnames = append(c.Hostnames, head.Hostname)
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].level != clusters[j].level {
+			return clusters[i].level < clusters[j].level
+		}
+		return clusters[i].Count > clusters[j].Count
+	})
+
+	if maxClusters <= 0 {
+		maxClusters = defaultMaxClusters
+	}
+	for i, c := range clusters {
+		if i >= maxClusters {
+			summary.Omitted++
+			continue
+		}
+		c.Severity = api.SeverityName(c.level)
+		summary.Clusters = append(summary.Clusters, *c)
+	}
+	return summary
+}
+
+// unitText joins the messages of a unit, shortening long stack traces
+func unitText(unit logUnit) string {
+	lines := make([]string, 0, len(unit.Entries))
+	for i, entry := range unit.Entries {
+		if i == maxExemplarLines {
+			lines = append(lines, fmt.Sprintf("... %d more lines", len(unit.Entries)-i))
+			break
+		}
+		lines = append(lines, entry.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// logSummaryResponse renders a log summary with the cursors to the raw lines
+func logSummaryResponse(title string, query api.LogQuery, summary logSummary, issues []knowledge.LogRuleMatch, rawCursor, nextCursor string) *mcp.CallToolResult {
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("Log summary for %s", title))
+	if filters := describeLogQuery(query); filters != "" {
+		response.WriteString(fmt.Sprintf(" (%s)", filters))
+	}
+	response.WriteString("\n\n")
+
+	if summary.Lines == 0 {
+		response.WriteString("No logs found.\n")
+	} else {
+		response.WriteString(fmt.Sprintf("%d lines, %d messages, %d clusters, %d stack traces\n",
+			summary.Lines, summary.Messages, len(summary.Clusters)+summary.Omitted, summary.StackTraces))
+		response.WriteString(fmt.Sprintf("Time range: %s - %s\n",
+			summary.First.Format("2006-01-02 15:04:05"), summary.Last.Format("2006-01-02 15:04:05")))
+
+		var severities []string
+		for _, name := range api.SeverityNames() {
+			if count := summary.BySeverity[name]; count > 0 {
+				severities = append(severities, fmt.Sprintf("%s %d", name, count))
+			}
+		}
+		response.WriteString(fmt.Sprintf("Severity: %s\n", strings.Join(severities, ", ")))
+
+		response.WriteString("\nClusters (most severe first):\n")
+		for i, c := range summary.Clusters {
+			kind := ""
+			if c.StackTrace {
+				kind = " stack trace"
+			}
+			response.WriteString(fmt.Sprintf("%d. [%s] %d×%s, %s - %s (%s)\n", i+1, strings.ToUpper(c.Severity), c.Count, kind,
+				c.First.Format("15:04:05"), c.Last.Format("15:04:05"), strings.Join(c.Hostnames, ", ")))
+			response.WriteString(fmt.Sprintf("   Pattern: %s\n", c.Signature))
+			response.WriteString("   Example: " + strings.ReplaceAll(c.Exemplar, "\n", "\n     ") + "\n")
+		}
+		if summary.Omitted > 0 {
+			response.WriteString(fmt.Sprintf("(%d less severe or less frequent clusters omitted; raise 'max_clusters' to see them)\n", summary.Omitted))
+		}
+	}
+
+	if analysis := FormatLogAnalysis(issues); analysis != "" {
+		response.WriteString(analysis)
+		response.WriteString("\n")
+	}
+
+	response.WriteString("\nRaw lines:\n")
+	if rawCursor != "" {
+		response.WriteString(fmt.Sprintf("- Use 'service_logs' with cursor='%s' and forward=true (same filters) to page through the summarized lines\n", rawCursor))
+	} else {
+		response.WriteString("- Use 'service_logs' with forward=true (same filters) to page through the summarized lines\n")
+	}
+	if nextCursor != "" {
+		response.WriteString(fmt.Sprintf("- Use cursor='%s' to get only newer lines\n", nextCursor))
+	}
+
+	return StructuredResponse(response.String(), map[string]interface{}{
+		"summary":     summary,
+		"issues":      issues,
+		"raw_cursor":  rawCursor,
+		"next_cursor": nextCursor,
+	})
+}
@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+)
+
+// hostLines builds entries from one host, a millisecond apart, with the given messages
+func hostLines(base time.Time, hostname string, severity int, messages ...string) []api.LogEntry {
+	entries := make([]api.LogEntry, len(messages))
+	for i, message := range messages {
+		entries[i] = api.LogEntry{
+			ID:        fmt.Sprintf("%s-%d", hostname, i),
+			Timestamp: base.Add(time.Duration(i) * time.Millisecond),
+			Hostname:  hostname,
+			Message:   message,
+			Severity:  severity,
+		}
+	}
+	return entries
+}
+
+func TestNormalizeLogMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"2024-05-01T12:00:00.123Z GET /users/42 200 in 13ms", "<ts> GET /users/<n> <n> in <n>ms"},
+		{`10.0.0.7 - - [01/May/2024:12:00:00 +0000] "GET / HTTP/1.1"`, `<ip> - - [<ts>] "GET / HTTP/<n>"`},
+		{"request 3f2b1c9e-8d4a-4b6f-9e1a-2c3d4e5f6a7b failed", "request <uuid> failed"},
+		{"job a1b2c3d4e5 finished at 12:00:01", "job <id> finished at <time>"},
+		{"segfault at 0x7ffd5e8c", "segfault at <hex>"},
+		{"connection   refused\tby peer", "connection refused by peer"},
+		{"password_reset mail sent", "password_reset mail sent"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeLogMessage(tt.message); got != tt.want {
+			t.Errorf("normalizeLogMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLogMessageTruncatesOnRunes(t *testing.T) {
+	message := "x" + strings.Repeat("ž", maxSignatureLength)
+	got := normalizeLogMessage(message)
+	if !utf8.ValidString(got) {
+		t.Fatalf("signature is not valid UTF-8: %q", got)
+	}
+	if want := "x" + strings.Repeat("ž", maxSignatureLength-1) + "…"; got != want {
+		t.Errorf("signature = %q, want %d runes and an ellipsis", got, maxSignatureLength)
+	}
+}
+
+func TestJoinStackTraces(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		entries   []api.LogEntry
+		wantUnits []int
+		wantTitle string
+	}{
+		{
+			name: "java exception",
+			entries: hostLines(base, "app", 3,
+				"Exception in thread \"main\" java.lang.NullPointerException",
+				"\tat com.example.App.run(App.java:12)",
+				"\tat com.example.App.main(App.java:5)",
+				"server stopped",
+			),
+			wantUnits: []int{3, 1},
+			wantTitle: "Exception in thread \"main\" java.lang.NullPointerException",
+		},
+		{
+			name: "python traceback titled by its exception",
+			entries: hostLines(base, "app", 3,
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"    main()",
+				"KeyError: 'user'",
+			),
+			wantUnits: []int{4},
+			wantTitle: "KeyError: 'user'",
+		},
+		{
+			name: "go panic",
+			entries: hostLines(base, "app", 2,
+				"panic: runtime error: index out of range",
+				"",
+				"goroutine 1 [running]:",
+				"main.handler(0xc000010000)",
+				"\t/app/main.go:42 +0x1d",
+			),
+			wantUnits: []int{5},
+			wantTitle: "panic: runtime error: index out of range",
+		},
+		{
+			name:      "plain messages stay separate",
+			entries:   hostLines(base, "app", 6, "listening on :3000", "GET / 200"),
+			wantUnits: []int{1, 1},
+			wantTitle: "listening on :3000",
+		},
+		{
+			name: "another host does not continue a trace",
+			entries: append(
+				hostLines(base, "app", 3, "panic: boom", "goroutine 1 [running]:"),
+				hostLines(base, "worker", 3, "\tat com.example.Job.run(Job.java:7)")...,
+			),
+			wantUnits: []int{2, 1},
+			wantTitle: "panic: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units := joinStackTraces(tt.entries)
+			sizes := make([]int, len(units))
+			for i, unit := range units {
+				sizes[i] = len(unit.Entries)
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.wantUnits) {
+				t.Fatalf("unit sizes = %v, want %v", sizes, tt.wantUnits)
+			}
+			if got := units[0].title(); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestJoinStackTracesSplitsOnTimeGap(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := hostLines(base, "app", 3, "panic: boom")
+	entries = append(entries, hostLines(base.Add(maxStackTraceGap+time.Second), "app", 3, "goroutine 1 [running]:")...)
+
+	if units := joinStackTraces(entries); len(units) != 2 {
+		t.Errorf("got %d units, want the late frame in a unit of its own", len(units))
+	}
+}
+
+func TestSummarizeLogs(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var entries []api.LogEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, hostLines(base.Add(time.Duration(i)*time.Minute), "app", 6, fmt.Sprintf("GET /items/%d 200", i))...)
+	}
+	entries = append(entries, hostLines(base.Add(10*time.Minute), "app", 4, "slow query took 1200ms")...)
+	entries = append(entries, hostLines(base.Add(11*time.Minute), "app", 3,
+		"Exception in thread \"main\" java.lang.IllegalStateException",
+		"\tat com.example.App.run(App.java:12)",
+	)...)
+	entries = append(entries, hostLines(base.Add(12*time.Minute), "worker", 6, "GET /items/99 200")...)
+
+	summary := summarizeLogs(entries, 0)
+
+	if summary.Lines != 9 || summary.Messages != 8 || summary.StackTraces != 1 {
+		t.Errorf("lines %d, messages %d, stack traces %d; want 9, 8, 1", summary.Lines, summary.Messages, summary.StackTraces)
+	}
+	if summary.BySeverity["info"] != 6 || summary.BySeverity["error"] != 2 {
+		t.Errorf("by severity = %v", summary.BySeverity)
+	}
+	if !summary.First.Equal(base) || summary.Last.Before(base.Add(12*time.Minute)) {
+		t.Errorf("time range = %v - %v", summary.First, summary.Last)
+	}
+
+	want := []struct {
+		severity string
+		count    int
+	}{{"error", 1}, {"warning", 1}, {"info", 6}}
+	if len(summary.Clusters) != len(want) {
+		t.Fatalf("got %d clusters, want %d: %+v", len(summary.Clusters), len(want), summary.Clusters)
+	}
+	for i, w := range want {
+		if c := summary.Clusters[i]; c.Severity != w.severity || c.Count != w.count {
+			t.Errorf("cluster %d = %s ×%d, want %s ×%d", i, c.Severity, c.Count, w.severity, w.count)
+		}
+	}
+
+	requests := summary.Clusters[2]
+	if requests.Signature != "GET /items/<n> <n>" || requests.FirstID != "app-0" || requests.LastID != "worker-0" {
+		t.Errorf("request cluster = %+v", requests)
+	}
+	if fmt.Sprint(requests.Hostnames) != "[app worker]" {
+		t.Errorf("hostnames = %v, want [app worker]", requests.Hostnames)
+	}
+	if !summary.Clusters[0].StackTrace || summary.Clusters[0].Lines != 2 {
+		t.Errorf("exception cluster = %+v, want a 2-line stack trace", summary.Clusters[0])
+	}
+}
+
+func TestSummarizeLogsOmitsClustersOverTheLimit(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := hostLines(base, "app", 6, "alpha started", "beta started", "gamma started")
+
+	summary := summarizeLogs(entries, 2)
+
+	if len(summary.Clusters) != 2 || summary.Omitted != 1 {
+		t.Errorf("got %d clusters and %d omitted, want 2 and 1", len(summary.Clusters), summary.Omitted)
+	}
+}
+
+func TestUnitTextShortensLongTraces(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	messages := []string{"panic: boom"}
+	for i := 0; i < maxExemplarLines+5; i++ {
+		messages = append(messages, "\tat frame")
+	}
+
+	units := joinStackTraces(hostLines(base, "app", 2, messages...))
+	if len(units) != 1 {
+		t.Fatalf("got %d units, want 1", len(units))
+	}
+	if text := unitText(units[0]); !strings.HasSuffix(text, "\n... 6 more lines") {
+		t.Errorf("exemplar = %q, want it cut after %d lines", text, maxExemplarLines)
+	}
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// notifier streams progress and log notifications to the client that made a tool call.
+// Notifications are best effort: clients without a session or progress token get none.
+type notifier struct {
+	ctx      context.Context
+	server   *server.MCPServer
+	token    mcp.ProgressToken
+	logger   string
+	progress float64
+}
+
+// newNotifier creates a notifier for the given tool call
+func newNotifier(ctx context.Context, request mcp.CallToolRequest, logger string) *notifier {
+	n := &notifier{
+		ctx:    ctx,
+		server: server.ServerFromContext(ctx),
+		logger: logger,
+	}
+	if request.Params.Meta != nil {
+		n.token = request.Params.Meta.ProgressToken
+	}
+	return n
+}
+
+// Progress sends a progress notification with a message
+func (n *notifier) Progress(message string) {
+	if n.server == nil || n.token == nil {
+		return
+	}
+	n.progress++
+	_ = n.server.SendNotificationToClient(n.ctx, "notifications/progress", map[string]any{
+		"progressToken": n.token,
+		"progress":      n.progress,
+		"message":       message,
+	})
+}
+
+// Log sends a logging notification at the given level
+func (n *notifier) Log(level mcp.LoggingLevel, data any) {
+	if n.server == nil {
+		return
+	}
+	_ = n.server.SendLogMessageToClient(n.ctx, mcp.NewLoggingMessageNotification(level, n.logger, data))
+}
@@ -7,10 +7,11 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
 // RegisterProcessTools registers process-related tools
-func RegisterProcessTools(s *server.MCPServer, client *api.Client) {
+func RegisterProcessTools(s *server.MCPServer, client api.ZeropsAPI) {
 	// Create process_status tool
 	processStatusTool := mcp.NewTool(
 		"process_status",
@@ -21,14 +22,14 @@ func RegisterProcessTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(processStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, processStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		processID, err := request.RequireString("process_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROCESS_ID",
 				"Process ID is required",
 				"Provide a valid process ID from a previous operation",
-			), nil
+			)), nil
 		}
 
 		// Get process details
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// profileOption returns the shared per-call profile parameter
+func profileOption() mcp.ToolOption {
+	return mcp.WithString("profile",
+		mcp.Description("API profile to use for this call only (from 'profile_list'); defaults to the active profile"),
+	)
+}
+
+// profileHandler routes the API calls of a handler to the requested profile
+func profileHandler(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if profile := strings.TrimSpace(request.GetString("profile", "")); profile != "" {
+			ctx = api.WithProfile(ctx, profile)
+		}
+		return handler(ctx, request)
+	}
+}
+
+// RegisterProfileTools registers the profile management tools. The active profile is
+// process-wide, so profile_use is refused when the server is shared by several clients.
+func RegisterProfileTools(s *server.MCPServer, profiles *api.ProfileSet, shared bool) {
+	// Register profile_list tool
+	profileListTool := mcp.NewTool(
+		"profile_list",
+		mcp.WithDescription("List configured API profiles (accounts/regions) and show which one is active"),
+	)
+
+	addTool(s, profileListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		active := profiles.Active()
+		names := profiles.Names()
+
+		response := fmt.Sprintf("API profiles (%d total):\n\n", len(names))
+		items := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			client, err := profiles.Profile(name)
+			if err != nil {
+				return HandleAPIError(err), nil
+			}
+
+			response += fmt.Sprintf("• %s", name)
+			if name == active {
+				response += " (active)"
+			}
+			response += fmt.Sprintf("\n  API URL: %s\n", client.GetBaseURL(ctx))
+
+			items = append(items, map[string]interface{}{
+				"name":    name,
+				"api_url": client.GetBaseURL(ctx),
+				"active":  name == active,
+			})
+		}
+
+		response += "\nNext step: Use 'profile_use' to switch profiles, or pass 'profile' to any tool for a single call"
+
+		return StructuredResponse(response, map[string]interface{}{
+			"active":   active,
+			"profiles": items,
+		}), nil
+	})
+
+	// Register profile_use tool
+	profileUseTool := mcp.NewTool(
+		"profile_use",
+		mcp.WithDescription("Switch the active API profile used by all subsequent calls (stdio transport only; shared servers take 'profile' per call)"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Profile name from 'profile_list'"),
+		),
+	)
+
+	addTool(s, profileUseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := strings.TrimSpace(request.GetString("name", ""))
+		if name == "" {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"MISSING_PROFILE",
+				"Profile name is required",
+				"Use 'profile_list' to see configured profiles",
+			).WithNextTool("profile_list")), nil
+		}
+
+		if shared {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"PROFILE_SWITCH_UNSUPPORTED",
+				"The active profile cannot be switched on a server shared by several clients",
+				fmt.Sprintf("Pass profile='%s' to each tool call, or start the server with -profile %s", name, name),
+			)), nil
+		}
+
+		previous := profiles.Active()
+		if err := profiles.Use(name); err != nil {
+			return HandleAPIError(err), nil
+		}
+
+		return SuccessResponse(map[string]interface{}{
+			"message":  fmt.Sprintf("Switched to profile '%s'", name),
+			"profile":  name,
+			"previous": previous,
+			"nextStep": "Use 'auth_validate' to confirm the credentials of this profile",
+		}), nil
+	})
+}
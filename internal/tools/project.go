@@ -8,42 +8,61 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
 	"gopkg.in/yaml.v3"
 )
 
 // RegisterProjectTools registers all project-related tools
-func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
+func RegisterProjectTools(s *server.MCPServer, client api.ZeropsAPI, logRules *knowledge.LogRuleSet) {
 	// Register project_list tool
+	projectSortFields := []string{"created", "name", "status"}
 	projectListTool := mcp.NewTool(
 		"project_list",
-		mcp.WithDescription("List all projects in your Zerops account"),
+		append([]mcp.ToolOption{
+			mcp.WithDescription("List projects in your Zerops account with optional filtering, sorting and pagination"),
+			mcp.WithString("tag",
+				mcp.Description("Only include projects with this tag"),
+			),
+			orgOption(),
+		}, listOptions(projectSortFields, "created")...)...,
 	)
 
-	s.AddTool(projectListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Get current user to determine client ID
-		user, err := client.GetCurrentUser(ctx)
-		if err != nil {
-			return HandleAPIError(err), nil
+	addTool(s, projectListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, errResult := parseListQuery(request, projectSortFields, "created")
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		if len(user.ClientUserList) == 0 {
-			return ErrorResponse(
-				"NO_CLIENT",
-				"No client associations found for user",
-				"Contact Zerops support to resolve account issues",
-			), nil
+		// Resolve the organization to work in
+		clientID, errResult := GetClientID(ctx, client, request.GetString("client_id", ""))
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		// Use the first client ID
-		clientID := user.ClientUserList[0].ClientID
-
-		// List projects
-		projects, err := client.ListProjects(ctx, clientID)
+		// List all projects across every search page
+		allProjects, err := client.ListProjects(ctx, clientID)
 		if err != nil {
 			return HandleAPIError(err), nil
 		}
 
+		projects := make([]api.Project, 0, len(allProjects))
+		for _, project := range allProjects {
+			if !matchesText(project.Name, query.Name) || !matchesExact(project.Status, query.Status) || !hasTag(project.TagList, query.Tag) {
+				continue
+			}
+			projects = append(projects, project)
+		}
+
 		if len(projects) == 0 {
+			if len(allProjects) > 0 {
+				return SuccessResponse(map[string]interface{}{
+					"message":  "No projects match the given filters",
+					"count":    0,
+					"total":    len(allProjects),
+					"nextStep": "Remove or adjust the name, status or tag filters",
+				}), nil
+			}
 			return SuccessResponse(map[string]interface{}{
 				"message":  "No projects found",
 				"count":    0,
@@ -51,23 +70,59 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 			}), nil
 		}
 
+		sortItems(projects, query, map[string]func(a, b api.Project) bool{
+			"created": func(a, b api.Project) bool { return a.Created.Before(b.Created) },
+			"name":    func(a, b api.Project) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) },
+			"status":  func(a, b api.Project) bool { return a.Status < b.Status },
+		})
+		page, nextCursor := pageItems(projects, query)
+
 		// Format project list
-		response := fmt.Sprintf("Found %d project(s):\n\n", len(projects))
-		
-		for i, project := range projects {
-			response += fmt.Sprintf("%d. %s\n", i+1, project.Name)
+		response := fmt.Sprintf("Found %d project(s)", len(projects))
+		if len(projects) != len(allProjects) {
+			response += fmt.Sprintf(" matching filters (of %d total)", len(allProjects))
+		}
+		if len(page) < len(projects) {
+			response += fmt.Sprintf(", showing %d-%d", query.Offset+1, query.Offset+len(page))
+		}
+		response += ":\n\n"
+
+		items := make([]map[string]interface{}, 0, len(page))
+		for i, project := range page {
+			response += fmt.Sprintf("%d. %s\n", query.Offset+i+1, project.Name)
 			response += fmt.Sprintf("   ID: %s\n", project.ID)
 			response += fmt.Sprintf("   Status: %s\n", project.Status)
 			if project.Description != "" {
 				response += fmt.Sprintf("   Description: %s\n", project.Description)
 			}
+			if len(project.TagList) > 0 {
+				response += fmt.Sprintf("   Tags: %s\n", strings.Join(project.TagList, ", "))
+			}
 			response += fmt.Sprintf("   Created: %s\n", project.Created.Format("2006-01-02 15:04:05"))
 			response += "\n"
+
+			items = append(items, map[string]interface{}{
+				"id":          project.ID,
+				"name":        project.Name,
+				"status":      project.Status,
+				"description": project.Description,
+				"tags":        project.TagList,
+				"created":     project.Created,
+			})
 		}
 
+		if nextCursor != "" {
+			response += fmt.Sprintf("More projects available: call 'project_list' with cursor='%s'\n\n", nextCursor)
+		}
 		response += "Next step: Use 'project_info' for details or 'service_list' to see services"
 
-		return mcp.NewToolResultText(response), nil
+		return StructuredResponse(response, map[string]interface{}{
+			"total":       len(projects),
+			"count":       len(page),
+			"offset":      query.Offset,
+			"next_cursor": nextCursor,
+			"items":       items,
+		}), nil
 	})
 
 	// Register project_create tool
@@ -85,49 +140,39 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		mcp.WithString("description",
 			mcp.Description("Optional project description"),
 		),
+		orgOption(),
 	)
 
-	s.AddTool(projectCreateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, projectCreateTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract parameters
 		name, err := request.RequireString("name")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_NAME",
 				"Project name is required",
 				"Provide a project name",
-			), nil
+			)), nil
 		}
 
 		region, err := request.RequireString("region")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_REGION",
 				"Region is required",
 				"Use 'region_list' tool to see available regions",
-			), nil
+			)), nil
 		}
 
 		description := request.GetString("description", "")
 
 		// No validation needed for project names - Zerops accepts any characters
 
-		// Get current user to determine client ID
-		user, err := client.GetCurrentUser(ctx)
-		if err != nil {
-			return HandleAPIError(err), nil
+		// Resolve the organization to work in
+		clientID, errResult := GetClientID(ctx, client, request.GetString("client_id", ""))
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		if len(user.ClientUserList) == 0 {
-			return ErrorResponse(
-				"NO_CLIENT",
-				"No client associations found for user",
-				"Contact Zerops support to resolve account issues",
-			), nil
-		}
-
-		// Use the first client ID
-		clientID := user.ClientUserList[0].ClientID
-
 		// Create project
 		project, err := client.CreateProject(ctx, api.CreateProjectRequest{
 			Name:        name,
@@ -139,19 +184,18 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		if err != nil {
 			// Handle specific errors
 			if strings.Contains(err.Error(), "already exists") {
-				return ErrorResponse(
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"PROJECT_EXISTS",
 					fmt.Sprintf("Project with name '%s' already exists", name),
 					"Choose a different project name",
-				), nil
+				)), nil
 			}
 			if strings.Contains(err.Error(), "invalid region") {
-				return ErrorResponseWithNext(
+				return ToolErrorResponse(zerrors.NewValidationError(
 					"INVALID_REGION",
 					fmt.Sprintf("Region '%s' is not valid", region),
 					"Use a valid region ID from the list",
-					"region_list",
-				), nil
+				).WithNextTool("region_list")), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -176,26 +220,25 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(projectInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, projectInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectID, err := request.RequireString("project_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_ID",
 				"Project ID is required",
 				"Provide a valid project ID from 'project_list'",
-			), nil
+			)), nil
 		}
 
 		// Get project details
 		project, err := client.GetProject(ctx, projectID)
 		if err != nil {
-			if strings.Contains(err.Error(), "404") {
-				return ErrorResponseWithNext(
+			if zerrors.IsNotFound(err) {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"PROJECT_NOT_FOUND",
 					fmt.Sprintf("Project with ID '%s' not found", projectID),
 					"Check the project ID or use 'project_list' to find valid projects",
-					"project_list",
-				), nil
+				).WithNextTool("project_list")), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -207,12 +250,19 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 			serviceCount = len(services)
 		}
 
+		// Region lookup is informational only
+		regionName := ""
+		if region, err := api.ProjectRegion(ctx, client, project); err == nil {
+			regionName = region.Name
+		}
+
 		return SuccessResponse(map[string]interface{}{
 			"projectId":    project.ID,
 			"name":         project.Name,
 			"description":  project.Description,
 			"status":       project.Status,
 			"mode":         project.Mode,
+			"region":       regionName,
 			"created":      project.Created.Format("2006-01-02 15:04:05"),
 			"lastUpdate":   project.LastUpdate.Format("2006-01-02 15:04:05"),
 			"serviceCount": serviceCount,
@@ -221,6 +271,123 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		}), nil
 	})
 
+	// Register project_logs_search tool
+	projectLogsSearchTool := mcp.NewTool(
+		"project_logs_search",
+		mcp.WithDescription("Search logs of all services in a project at once. Lines are merged by timestamp and labeled with the service hostname"),
+		mcp.WithString("project_id",
+			mcp.Required(),
+			mcp.Description("Project ID to search logs in"),
+		),
+		mcp.WithString("search",
+			mcp.Description("Only include lines whose message contains this text (case-insensitive)"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Only include lines whose message matches this regular expression (e.g., 'timeout|refused')"),
+		),
+		mcp.WithString("severity",
+			mcp.Enum(api.SeverityNames()...),
+			mcp.Description("Minimum severity to include (e.g., 'error')"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Show logs since timestamp (RFC3339 format) or duration (e.g., '15m', '1h')"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Show logs until timestamp (RFC3339 format) or duration ago (e.g., '5m')"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Container name (runtime, prepare, init) - defaults to all"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of merged lines to return (default: 200, max: 1000)"),
+		),
+	)
+
+	addTool(s, projectLogsSearchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		projectID, err := request.RequireString("project_id")
+		if err != nil {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_PROJECT_ID",
+				"Project ID is required",
+				"Provide a valid project ID from 'project_list'",
+			)), nil
+		}
+
+		query, errResult := parseLogQuery(request, 200)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		project, errResult := ValidateProjectAccess(ctx, client, projectID)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		logs, err := client.GetProjectLogs(ctx, projectID, query)
+		if err != nil {
+			return HandleAPIError(err), nil
+		}
+		entries := logs.Entries
+		lines := api.LogLines(entries)
+		// Services of different runtimes are merged, so every rule applies
+		issues := AnalyzeLogs(logRules, entries, "", knowledge.LogSourceRuntime)
+
+		// Count matches per service
+		counts := make(map[string]int)
+		var hostnames []string
+		for _, entry := range entries {
+			if counts[entry.Hostname] == 0 {
+				hostnames = append(hostnames, entry.Hostname)
+			}
+			counts[entry.Hostname]++
+		}
+
+		var response strings.Builder
+		response.WriteString(fmt.Sprintf("Logs for project '%s'", project.Name))
+		if filters := describeLogQuery(query); filters != "" {
+			response.WriteString(fmt.Sprintf(" (%s)", filters))
+		}
+		response.WriteString("\n\n")
+
+		if len(entries) == 0 {
+			response.WriteString("No matching log lines found in any service.\n")
+		} else {
+			for _, line := range lines {
+				response.WriteString(line + "\n")
+			}
+
+			response.WriteString(fmt.Sprintf("\nMatches by service (%d lines):\n", len(entries)))
+			for _, hostname := range hostnames {
+				response.WriteString(fmt.Sprintf("- %s: %d\n", hostname, counts[hostname]))
+			}
+
+			if analysis := FormatLogAnalysis(issues); analysis != "" {
+				response.WriteString(analysis)
+				response.WriteString("\n")
+			}
+		}
+
+		if len(logs.Skipped) > 0 {
+			response.WriteString(fmt.Sprintf("\n⚠️ %d service(s) not searched:\n", len(logs.Skipped)))
+			for _, skipped := range logs.Skipped {
+				response.WriteString(fmt.Sprintf("- %s: %s\n", skipped.Hostname, skipped.Reason))
+			}
+		}
+
+		response.WriteString("\nNext step: Use 'service_logs' with a service ID for more lines from a single service")
+
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"project_id": projectID,
+			"count":      len(entries),
+			"by_service": counts,
+			"entries":    entries,
+			"issues":     issues,
+			"filters":    describeLogQuery(query),
+			"searched":   logs.Searched,
+			"skipped":    logs.Skipped,
+		}), nil
+	})
+
 	// Register project_import tool
 	projectImportTool := mcp.NewTool(
 		"project_import",
@@ -233,25 +400,28 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 			mcp.Required(),
 			mcp.Description("YAML configuration defining services to import. Supports preprocessing functions in envSecrets"),
 		),
+		mcp.WithString("client_id",
+			mcp.Description("Organization to import as: client ID or account name (default: the organization that owns the project)"),
+		),
 	)
 
-	s.AddTool(projectImportTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, projectImportTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectID, err := request.RequireString("project_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_ID",
 				"Project ID is required",
 				"Provide a valid project ID from 'project_list'",
-			), nil
+			)), nil
 		}
 
 		yamlConfig, err := request.RequireString("yaml")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_YAML",
 				"YAML configuration is required",
 				"Provide a valid YAML configuration for services",
-			), nil
+			)), nil
 		}
 		
 		// Debug: Log received YAML
@@ -292,11 +462,11 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		
 		// Validate YAML has required structure
 		if !strings.Contains(servicesYAML, "services:") {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_YAML_STRUCTURE",
 				"YAML must contain 'services:' section",
 				"Ensure your YAML starts with 'services:' and defines service configurations",
-			), nil
+			)), nil
 		}
 
 		// Check if YAML contains preprocessing functions
@@ -310,48 +480,46 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		// Debug: Check if preprocessing was added
 		preprocessingAdded := !strings.Contains(originalYAML, "#yamlPreprocessor=on") && strings.Contains(servicesYAML, "#yamlPreprocessor=on")
 
-		// Get current user to determine client ID
-		user, err := client.GetCurrentUser(ctx)
-		if err != nil {
-			return HandleAPIError(err), nil
-		}
-
-		if len(user.ClientUserList) == 0 {
-			return ErrorResponse(
-				"NO_CLIENT",
-				"No client associations found for user",
-				"Contact Zerops support to resolve account issues",
-			), nil
+		// Import into the organization that owns the project unless one is given
+		var clientID string
+		if org := request.GetString("client_id", ""); org != "" {
+			var errResult *mcp.CallToolResult
+			clientID, errResult = GetClientID(ctx, client, org)
+			if errResult != nil {
+				return errResult, nil
+			}
+		} else {
+			project, errResult := ValidateProjectAccess(ctx, client, projectID)
+			if errResult != nil {
+				return errResult, nil
+			}
+			clientID = project.ClientID
 		}
 
-		// Use the first client ID
-		clientID := user.ClientUserList[0].ClientID
-
 		// Import services
 		err = client.ImportProjectServices(ctx, projectID, clientID, servicesYAML)
 		if err != nil {
 			// Handle specific errors
 			if strings.Contains(err.Error(), "invalid service name") {
-				return ErrorResponse(
+				return ToolErrorResponse(zerrors.NewValidationError(
 					"INVALID_SERVICE_NAME",
 					"Service name contains invalid characters",
 					"Use only lowercase letters and numbers (no hyphens) for service names",
-				), nil
+				)), nil
 			}
 			if strings.Contains(err.Error(), "unknown type") {
-				return ErrorResponse(
+				return ToolErrorResponse(zerrors.NewValidationError(
 					"INVALID_SERVICE_TYPE",
 					"Unknown service type specified",
 					"Use valid service types like nodejs@20, postgresql@16, etc.",
-				), nil
+				)), nil
 			}
-			if strings.Contains(err.Error(), "404") {
-				return ErrorResponseWithNext(
+			if zerrors.IsNotFound(err) {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"PROJECT_NOT_FOUND",
 					fmt.Sprintf("Project with ID '%s' not found", projectID),
 					"Check the project ID or use 'project_list' to find valid projects",
-					"project_list",
-				), nil
+				).WithNextTool("project_list")), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -426,34 +594,34 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(projectDeleteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, projectDeleteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectID, err := request.RequireString("project_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_ID",
 				"Project ID is required",
 				"Provide a valid project ID from 'project_list'",
-			), nil
+			)), nil
 		}
 
 		confirm := request.GetBool("confirm", false)
 		if !confirm {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIRMATION_REQUIRED",
 				"Project deletion requires confirmation",
 				"Set 'confirm' parameter to true to delete the project. WARNING: This action cannot be undone!",
-			), nil
+			)), nil
 		}
 
 		// Get project info first to show what's being deleted
 		project, err := client.GetProject(ctx, projectID)
 		if err != nil {
-			if strings.Contains(err.Error(), "404") {
-				return ErrorResponse(
+			if zerrors.IsNotFound(err) {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"PROJECT_NOT_FOUND",
 					fmt.Sprintf("Project with ID '%s' not found", projectID),
 					"The project may have already been deleted",
-				), nil
+				)), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -463,12 +631,12 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 		// Delete project
 		_, err = client.DeleteProject(ctx, projectID)
 		if err != nil {
-			if strings.Contains(err.Error(), "404") {
-				return ErrorResponse(
+			if zerrors.IsNotFound(err) {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"PROJECT_NOT_FOUND",
 					"Project not found",
 					"The project may have already been deleted",
-				), nil
+				)), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -480,4 +648,17 @@ func RegisterProjectTools(s *server.MCPServer, client *api.Client) {
 			"nextStep":  "Use 'project_list' to see remaining projects or 'project_create' to create a new one",
 		}), nil
 	})
-}
\ No newline at end of file
+}
+
+// hasTag reports whether tags contains the tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	if tag == "" {
+		return true
+	}
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+func TestProjectLogsSearchReportsSkippedServices(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.AddService(project.ID, "worker", "go@1")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "Error: Cannot find module 'express'", Severity: 3})
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "project_logs_search", map[string]interface{}{"project_id": project.ID})
+	if !out.OK {
+		t.Fatalf("project_logs_search failed: %+v", out.Error)
+	}
+	var data struct {
+		Count    int `json:"count"`
+		Searched int `json:"searched"`
+		Skipped  []struct {
+			Hostname string `json:"hostname"`
+			Reason   string `json:"reason"`
+		} `json:"skipped"`
+	}
+	if err := json.Unmarshal(out.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Searched != 1 || data.Count == 0 {
+		t.Errorf("searched %d services with %d lines, want the deployed app's lines", data.Searched, data.Count)
+	}
+	if len(data.Skipped) != 1 || data.Skipped[0].Hostname != "worker" {
+		t.Errorf("skipped = %+v, want the undeployed worker", data.Skipped)
+	}
+
+	text, isError := callText(t, c, "project_logs_search", map[string]interface{}{"project_id": project.ID})
+	if isError || !strings.Contains(text, "Cannot find module 'express'") || !strings.Contains(text, "- worker: not deployed yet") {
+		t.Errorf("expected the app's lines and a warning for the worker, got:\n%s", text)
+	}
+
+	// A failing log proxy for every service is still reported as an error
+	sim.InjectError(apitest.InjectedError{Method: "GET", Path: "/api/rest/log", StatusCode: 500, Code: "injected", Message: "log proxy down"})
+	if out := callJSON(t, c, "project_logs_search", map[string]interface{}{"project_id": project.ID}); out.OK {
+		t.Error("expected an error when no service could be searched")
+	}
+}
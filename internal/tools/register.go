@@ -1,37 +1,111 @@
 package tools
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
 	"github.com/zeropsio/zerops-mcp-v3/internal/config"
+	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
+	"github.com/zeropsio/zerops-mcp-v3/internal/vpnhelper"
 	"github.com/zeropsio/zerops-mcp-v3/internal/zcli"
 )
 
 // RegisterAll registers all tools with the MCP server
-func RegisterAll(s *server.MCPServer, cfg *config.Config) {
-	// Create API client
-	apiClient := api.NewClient(api.ClientOptions{
-		BaseURL: cfg.ZeropsAPIURL,
-		APIKey:  cfg.ZeropsAPIKey,
-		Timeout: cfg.APITimeout,
-		Debug:   cfg.Debug,
-	})
+func RegisterAll(s *server.MCPServer, cfg *config.Config) error {
+	profiles, active := resolveProfiles(cfg)
+
+	// Create one API client per profile
+	clients := make(map[string]api.ZeropsAPI, len(profiles))
+	for _, profile := range profiles {
+		clients[profile.Name] = api.NewClient(api.ClientOptions{
+			BaseURL:    profile.APIURL,
+			APIKey:     profile.APIKey,
+			Timeout:    cfg.APITimeout,
+			Debug:      cfg.Debug,
+			DefaultOrg: profile.Org,
+		})
+	}
+
+	profileSet, _ := api.NewProfileSet(clients, active)
+	return RegisterAllWithAPI(s, cfg, profileSet)
+}
+
+// resolveProfiles returns the configured profiles and the active one. Without a
+// profiles file the API key from the environment becomes the only profile.
+func resolveProfiles(cfg *config.Config) ([]config.Profile, string) {
+	profiles := cfg.Profiles
+	active := cfg.Profile
+	if len(profiles) == 0 {
+		profiles = []config.Profile{{
+			Name:   config.DefaultProfileName,
+			APIKey: cfg.ZeropsAPIKey,
+			APIURL: cfg.ZeropsAPIURL,
+			Org:    cfg.DefaultOrg,
+		}}
+		active = config.DefaultProfileName
+	} else if cfg.FindProfile(active) == nil {
+		// Validate rejects unknown profiles; fall back for configs built in code
+		active = profiles[0].Name
+	}
+	return profiles, active
+}
+
+// RegisterAllWithAPI registers all tools using the given API implementation
+func RegisterAllWithAPI(s *server.MCPServer, cfg *config.Config, apiClient api.ZeropsAPI) error {
+	SetDefaultOutputFormat(cfg.OutputFormat)
+	logRules, err := knowledge.LoadLogRules(cfg.LogRulePaths...)
+	if err != nil {
+		return fmt.Errorf("invalid ZEROPS_MCP_LOG_RULES: %w", err)
+	}
+
+	// A single implementation becomes the only profile
+	profileSet, ok := apiClient.(*api.ProfileSet)
+	if !ok {
+		profileSet, _ = api.NewProfileSet(map[string]api.ZeropsAPI{config.DefaultProfileName: apiClient}, config.DefaultProfileName)
+		apiClient = profileSet
+	}
 
 	// Create zcli wrapper
 	zcliWrapper := zcli.NewWithConfig(cfg.Debug, cfg.VPNWaitTime)
+	helperSocket := cfg.VPNHelperSocket
+	if helperSocket == "" && !cfg.VPNHelperOff {
+		helperSocket = vpnhelper.DefaultSocketPath
+	}
+	zcliWrapper.SetVPNHelper(helperSocket)
+	zcliWrapper.SetTimeouts(cfg.ZCLITimeouts)
+
+	// zcli runs logged in with the API key of the profile a call uses
+	profiles, _ := resolveProfiles(cfg)
+	accounts := make([]zcli.Account, 0, len(profiles))
+	for _, profile := range profiles {
+		accounts = append(accounts, zcli.Account{Profile: profile.Name, Token: profile.APIKey, APIURL: profile.APIURL})
+	}
+	zcliWrapper.SetAccounts(accounts, func(ctx context.Context) string {
+		if name := api.ProfileFromContext(ctx); name != "" {
+			return name
+		}
+		return profileSet.Active()
+	})
 
 	// Register all tool categories
 	RegisterAuthTools(s, apiClient)
-	RegisterProjectTools(s, apiClient)
-	RegisterServiceTools(s, apiClient)
-	RegisterDeployTools(s, apiClient, zcliWrapper)
+	// HTTP transports serve several clients that must not switch each other's account
+	shared := cfg.Transport == config.TransportSSE || cfg.Transport == config.TransportStreamableHTTP
+	RegisterProfileTools(s, profileSet, shared)
+	RegisterProjectTools(s, apiClient, logRules)
+	RegisterServiceTools(s, apiClient, logRules)
+	RegisterDeployTools(s, apiClient, zcliWrapper, cfg.DeployBackend, logRules)
 	RegisterConfigTools(s, apiClient)
+	RegisterEnvTools(s, apiClient)
 	RegisterWorkflowTools(s, apiClient, zcliWrapper)
 	RegisterSubdomainTools(s, apiClient)
 	RegisterProcessTools(s, apiClient)
 	
 	// Register knowledge tools
-	RegisterKnowledgeTools(s)
+	RegisterKnowledgeTools(s, logRules)
+	return nil
 }
 
 // GetServerInstructions returns instructions for LLMs
@@ -101,21 +175,23 @@ This prevents configuration errors and ensures compatibility with Zerops platfor
 5. 'project_import' with the modified YAML
 6. Use pattern's zerops.yml for deployment
 
-## Available Tools (41 total)
-- **Authentication** (3): auth_validate, platform_info, region_list
-- **Projects** (5): project_create, project_list, project_info, project_import, project_delete
-- **Services** (6): service_list, service_info, service_logs, service_start, service_stop, service_delete
-- **Deployment** (8): vpn_status, vpn_connect, vpn_disconnect, deploy_validate, deploy_push, deploy_status, deploy_logs, deploy_troubleshoot
-- **Configuration** (5): config_templates, config_generate, config_validate, env_vars_show, config_nginx
+## Available Tools (51 total)
+- **Authentication** (4): auth_validate, platform_info, region_list, org_list
+- **Profiles** (2): profile_list, profile_use
+- **Projects** (6): project_create, project_list, project_info, project_logs_search, project_import, project_delete
+- **Services** (7): service_list, service_info, service_logs, service_logs_follow, service_start, service_stop, service_delete
+- **Deployment** (9): vpn_status, vpn_connect, vpn_disconnect, deploy_validate, deploy_push, deploy_status, deploy_logs, deploy_troubleshoot, deploy_queue
+- **Configuration** (4): config_templates, config_validate, env_vars_show, config_nginx
+- **Environment** (4): env_list, env_set, env_delete, env_sync
 - **Workflows** (3): workflow_create_app, workflow_clone, workflow_diagnose
 - **Subdomain** (3): subdomain_enable, subdomain_disable, subdomain_status
 - **Process** (1): process_status
-- **Knowledge** (7): knowledge_get_runtime, knowledge_search_patterns, knowledge_validate_config, knowledge_resolve_dependencies, knowledge_get_service, knowledge_list_services, knowledge_get_docs
+- **Knowledge** (8): knowledge_get_runtime, knowledge_search_patterns, knowledge_validate_config, knowledge_resolve_dependencies, knowledge_get_service, knowledge_list_services, knowledge_log_rules, knowledge_get_docs
 
 ## Key Concepts
 - **Projects**: Isolated environments containing multiple services
 - **Services**: Individual applications, databases, or utilities
-- **Deployment**: Code is deployed via zcli push (requires VPN)
+- **Deployment**: deploy_push packages the working directory and uploads it through the API (no VPN or zcli needed; set ZEROPS_MCP_DEPLOY_BACKEND=zcli to use zcli push instead). Parallel deploys of one service and VPN changes are queued; deploy_queue shows what is waiting
 - **Configuration**: Services configured via YAML import
 
 ## Important Rules
@@ -134,7 +210,8 @@ This prevents configuration errors and ensures compatibility with Zerops platfor
 3. **Environment Variables**: 
    - Cross-service references: ${servicename_variablename}
    - Database passwords are auto-generated
-   - Can only be set during service creation
+   - Set at import, or later with env_set / env_delete for a service (service_id) or a whole project (project_id)
+   - Running services see changes after a restart or reload: pass apply="restart" or apply="reload"
    - Use envSecrets for sensitive data with preprocessing: <@generateRandomString(<32>)>
 
 4. **Database Services**:
@@ -148,14 +225,12 @@ This prevents configuration errors and ensures compatibility with Zerops platfor
    - Database name env var is 'dbName' (e.g., ${db_dbName})
 
 5. **Deployment Requirements**:
-   - VPN must be connected (use vpn_connect)
-   - Code must be in git repository with at least one commit
+   - No VPN needed: deploy_push uploads the working directory, leaving out .git and paths in .gitignore/.deployignore
+   - With the zcli backend, the VPN must be connected and the code must be in a git repository with at least one commit
    - Requires zerops.yml configuration file in project root
    - **SERVICE NAME IS REQUIRED**: Must specify service_name parameter matching hostname in zerops.yml
    - Files are deployed to /var/www in runtime containers
    - Use addToRunPrepare for files needed during prepareCommands
-   - Git repository needs commits: git add . && git commit -m "Initial commit"
-   - **CRITICAL**: Without commits, deploy will fail with "exit status 128"
    - **CRITICAL**: Without service_name, deploy will fail with "Please, select a service"
 
 6. **Subdomain Access**:
@@ -171,7 +246,7 @@ This prevents configuration errors and ensures compatibility with Zerops platfor
 7. **Special Notes**:
    - buildFromGit is only for Zerops recipes, not user code
    - PHP: Use php-apache@8.3 for Laravel
-   - Always check error messages for resolution steps
+   - Always check error messages for resolution steps; each error ends with a JSON block (category, code, retryable, next_tool, metadata)
    - **PHP Nginx Configuration**: For Laravel/Symfony/WordPress using php-nginx:
      - Use 'config_nginx' tool to get the proper nginx template
      - Save as site.conf.tmpl in project root
@@ -239,7 +314,7 @@ When pattern doesn't match project:
 6. Import services: project_import yaml="<pattern's services section>"
 7. **WAIT for services**: Services take 10-30 seconds to initialize. Check with service_list
 8. **CREATE zerops.yml**: Extract pattern's zeropsYml field and save as zerops.yml
-9. Connect VPN: vpn_connect project_id="..."
+9. Validate: deploy_validate working_dir="./"
 10. Deploy: deploy_push project_id="..." service_name="app" working_dir="./"
     **CRITICAL**: service_name parameter is REQUIRED! Must match hostname from zerops.yml
 11. Enable access: subdomain_enable (if needed)
@@ -248,10 +323,11 @@ When pattern doesn't match project:
 
 **NEVER create .env.production or similar files!** Zerops handles environment variables differently:
 
-1. **Service Creation**: Environment variables are set during service import via YAML
+1. **Service Creation**: Environment variables are set during service import via YAML, and changed later with env_set / env_delete
 2. **Runtime Injection**: Zerops automatically injects these into your application
 3. **Cross-Service References**: Use ${servicename_variable} syntax in YAML
 4. **No .env Files**: Do NOT create .env.production, .env.staging, etc.
+5. **Existing .env Files**: If the repository already keeps one, env_sync previews its diff against the service or project and applies it after confirm=true with the returned plan_id
 
 **Example**: When importing services, environment variables are set like this:
 
@@ -315,8 +391,8 @@ Here's the CORRECT workflow for deploying a Laravel app:
 7. project_import with the PATTERN'S services YAML (modified for MariaDB)
 8. Extract zerops.yml from pattern's zeropsYml field
 9. Save as zerops.yml in project root
-10. vpn_connect project_id="..."
-11. deploy_push work_dir="./"
+10. deploy_validate working_dir="./"
+11. deploy_push project_id="..." service_name="app" working_dir="./"
 
 **NEVER manually create service configurations - always use the pattern's services section!**
 
@@ -335,13 +411,13 @@ Here's the CORRECT workflow for deploying a Laravel app:
 
 ## Common Issues
 - "Service name invalid": Use only lowercase letters and numbers
-- "VPN connection failed": Ensure zcli is installed and sudo access
+- "VPN connection failed": Ensure zcli is installed and zerops-vpn-helper runs or sudo works without a password (see vpn_status)
 - "Project not found": Verify project ID with project_list
-- "Deploy failed": Check zerops.yml exists and VPN is connected
+- "Deploy failed": Check zerops.yml exists and its setup matches the service hostname
 - "Service stack is not http": Add ports with httpSupport: true to enable subdomain
 - "Could not open requirements file": Use addToRunPrepare in zerops.yml
 - "Subdomain not working": Service must be deployed and in ACTIVE state
-- "exit status 128": Git needs at least one commit (git add . && git commit -m "msg")
+- "exit status 128" (zcli backend): Git needs at least one commit (git add . && git commit -m "msg")
 - "websocket: bad handshake": Log streaming error, deployment may still succeed
 - "No action allowed, project will be deleted": Wait for services to initialize after import
 
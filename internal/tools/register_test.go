@@ -0,0 +1,245 @@
+package tools_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+	"github.com/zeropsio/zerops-mcp-v3/internal/tools"
+)
+
+// toolFixture is the fake API state a registered tool is called against
+type toolFixture struct {
+	sim       *apitest.Server
+	project   *api.Project
+	app       *api.ServiceDetails
+	db        *api.ServiceDetails
+	stopped   *api.ServiceDetails
+	version   *api.AppVersion
+	processID string
+	dir       string
+}
+
+// newToolFixture seeds a project with a deployed runtime service, a stopped one,
+// a database, a finished deployment and a process, and a working directory with
+// a zerops.yml and a .env file
+func newToolFixture(t *testing.T) toolFixture {
+	t.Helper()
+	sim := apitest.NewServer()
+	t.Cleanup(sim.Close)
+	sim.ProcessPolls = 0
+
+	f := toolFixture{sim: sim, dir: t.TempDir()}
+	f.project = sim.AddProject("demo")
+	f.app = sim.AddService(f.project.ID, "app", "nodejs@20")
+	f.db = sim.AddService(f.project.ID, "db", "postgresql@16")
+	f.stopped = sim.AddService(f.project.ID, "worker", "go@1")
+	sim.SimulateDeploy(f.app.ID, 3000)
+	sim.SetServiceStatus(f.stopped.ID, "STOPPED")
+	sim.AddLogs(f.app.ID, apitest.LogItem{Message: "Error: Cannot find module 'express'", Severity: 3})
+	f.version = sim.AddAppVersion(f.app.ID, "ACTIVE", apitest.LogItem{Message: "Running build commands"})
+
+	client := api.NewClient(api.ClientOptions{BaseURL: sim.URL, APIKey: sim.APIKey, Timeout: 5 * time.Second})
+	process, err := client.CreateProjectEnv(context.Background(), f.project.ID, "SEED", "1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.processID = process.ID
+
+	for name, content := range map[string]string{
+		"zerops.yml": "zerops:\n  - setup: app\n    run:\n      start: node index.js\n",
+		"index.js":   "console.log('hi')",
+		".env":       "GREETING=hello\n",
+		"broken.yml": "zerops: []\n",
+	} {
+		if err := os.WriteFile(filepath.Join(f.dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func TestRegisteredTools(t *testing.T) {
+	// Keep zcli and the VPN session of the machine running the tests out of reach
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		tool string
+		args func(f toolFixture) map[string]interface{}
+		// wantCode is the expected error code; empty means the call succeeds
+		wantCode string
+	}{
+		{"auth_validate", nil, ""},
+		{"config_nginx", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"framework": "laravel"} }, ""},
+		{"config_templates", nil, ""},
+		{"config_validate", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"config_path": filepath.Join(f.dir, "broken.yml")}
+		}, "CONFIG_INVALID"},
+		{"deploy_logs", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"deploy_push", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"project_id": f.project.ID, "service_name": "app", "working_dir": f.dir}
+		}, ""},
+		{"deploy_queue", nil, ""},
+		{"deploy_status", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"deploy_troubleshoot", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"deploy_validate", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"working_dir": f.dir} }, ""},
+		{"env_delete", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"project_id": f.project.ID, "key": "SEED"}
+		}, ""},
+		{"env_list", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"env_set", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"service_id": f.app.ID, "key": "GREETING", "value": "hello"}
+		}, ""},
+		{"env_sync", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"service_id": f.app.ID, "file": filepath.Join(f.dir, ".env")}
+		}, ""},
+		{"env_vars_show", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"knowledge_get_docs", nil, ""},
+		{"knowledge_get_runtime", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"runtime": "nodejs"} }, ""},
+		{"knowledge_get_service", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service": "postgresql"} }, ""},
+		{"knowledge_list_services", nil, ""},
+		{"knowledge_log_rules", nil, ""},
+		{"knowledge_resolve_dependencies", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"services": []interface{}{"nodejs", "postgresql"}}
+		}, ""},
+		{"knowledge_search_patterns", nil, ""},
+		{"knowledge_validate_config", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"config": "zerops:\n  - setup: app\n    run:\n      start: node index.js\n"}
+		}, ""},
+		{"org_list", nil, ""},
+		{"platform_info", nil, ""},
+		{"process_status", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"process_id": f.processID} }, ""},
+		{"profile_list", nil, ""},
+		{"profile_use", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"name": "default"} }, ""},
+		{"project_create", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"name": "second", "region": apitest.DefaultRegion}
+		}, ""},
+		{"project_delete", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"project_id": f.project.ID, "confirm": true}
+		}, ""},
+		{"project_import", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"project_id": f.project.ID, "yaml": "services:\n  - hostname: cache\n    type: valkey@7.2\n"}
+		}, ""},
+		{"project_info", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"project_id": f.project.ID} }, ""},
+		{"project_list", nil, ""},
+		{"project_logs_search", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"project_id": f.project.ID}
+		}, ""},
+		{"region_list", nil, ""},
+		{"service_delete", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"service_id": f.db.ID, "confirm": true}
+		}, ""},
+		{"service_info", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"service_list", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"project_id": f.project.ID} }, ""},
+		{"service_logs", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"service_logs_follow", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"service_id": f.app.ID, "duration": 1, "interval": 1}
+		}, ""},
+		{"service_start", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.stopped.ID} }, ""},
+		{"service_stop", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"subdomain_disable", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"subdomain_enable", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		{"subdomain_status", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"service_id": f.app.ID} }, ""},
+		// The VPN runs through zcli, which is kept off PATH
+		{"vpn_connect", func(f toolFixture) map[string]interface{} { return map[string]interface{}{"project_id": f.project.ID} }, "ZCLI_NOT_INSTALLED"},
+		{"vpn_disconnect", nil, "ZCLI_NOT_INSTALLED"},
+		{"vpn_status", nil, "ZCLI_NOT_INSTALLED"},
+		{"workflow_clone", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"source_project_id": f.project.ID, "new_project_name": "copy"}
+		}, ""},
+		{"workflow_create_app", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"project_name": "shop", "app_type": "nodejs", "app_hostname": "web"}
+		}, ""},
+		{"workflow_diagnose", func(f toolFixture) map[string]interface{} {
+			return map[string]interface{}{"issue_type": "deployment", "service_id": f.app.ID}
+		}, ""},
+	}
+
+	sim := apitest.NewServer()
+	defer sim.Close()
+	listed, err := startTools(t, sim).ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registered, covered []string
+	for _, tool := range listed.Tools {
+		registered = append(registered, tool.Name)
+	}
+	for _, tt := range tests {
+		covered = append(covered, tt.tool)
+	}
+	sort.Strings(registered)
+	sort.Strings(covered)
+	if len(registered) != 51 || len(covered) != len(registered) {
+		t.Fatalf("registered tools %v\ncovered tools %v", registered, covered)
+	}
+	for i := range registered {
+		if registered[i] != covered[i] {
+			t.Fatalf("registered tools %v\ncovered tools %v", registered, covered)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			f := newToolFixture(t)
+			c := startTools(t, f.sim)
+			var args map[string]interface{}
+			if tt.args != nil {
+				args = tt.args(f)
+			}
+
+			out := callJSON(t, c, tt.tool, args)
+			switch {
+			case tt.wantCode == "" && !out.OK:
+				t.Errorf("%s failed: %+v", tt.tool, out.Error)
+			case tt.wantCode != "" && (out.OK || out.Error == nil || out.Error.Code != tt.wantCode):
+				t.Errorf("%s: error = %+v, want %s", tt.tool, out.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServerInstructionsListRegisteredTools(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	listed, err := startTools(t, sim).ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instructions := tools.GetServerInstructions()
+	if want := fmt.Sprintf("## Available Tools (%d total)", len(listed.Tools)); !strings.Contains(instructions, want) {
+		t.Errorf("instructions do not contain %q", want)
+	}
+
+	// Every tool named in the tool list is registered, and every registered tool is listed
+	registered := make(map[string]bool)
+	for _, tool := range listed.Tools {
+		registered[tool.Name] = true
+	}
+	_, list, _ := strings.Cut(instructions, "## Available Tools")
+	list, _, _ = strings.Cut(list, "\n## ")
+	named := make(map[string]bool)
+	for _, name := range regexp.MustCompile(`\b[a-z]+(?:_[a-z]+)+\b`).FindAllString(list, -1) {
+		named[name] = true
+		if !registered[name] {
+			t.Errorf("instructions list %s, which is not registered", name)
+		}
+	}
+	for name := range registered {
+		if !named[name] {
+			t.Errorf("instructions do not list %s", name)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tools_test
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+// record rewrites the fixtures from the fake API instead of replaying them
+var record = flag.Bool("record", false, "record fixtures in testdata from the fake API")
+
+// sessionFixture is a recorded session of the calls made by replaySession
+var sessionFixture = filepath.Join("testdata", "session.json")
+
+// replaySession drives the tools through a typical inspection of a project
+func replaySession(t *testing.T, apiClient api.ZeropsAPI) map[string]string {
+	t.Helper()
+	c := connectTools(t, apiClient)
+
+	calls := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"auth_validate", nil},
+		{"project_list", nil},
+		{"service_list", map[string]interface{}{"project_id": "project-1"}},
+		{"service_info", map[string]interface{}{"service_id": "service-2"}},
+		{"env_set", map[string]interface{}{"service_id": "service-2", "key": "API_URL", "value": "https://shop.example.com"}},
+		{"env_list", map[string]interface{}{"service_id": "service-2"}},
+		{"project_info", map[string]interface{}{"project_id": "missing"}},
+	}
+
+	outputs := make(map[string]string, len(calls))
+	for _, call := range calls {
+		text, _ := callText(t, c, call.name, call.args)
+		outputs[call.name] = text
+	}
+	return outputs
+}
+
+// recordSession records replaySession against a seeded fake API
+func recordSession(t *testing.T) {
+	t.Helper()
+
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("shop")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.AddService(project.ID, "db", "postgresql@16")
+
+	recorder := apitest.NewRecorder(api.NewClient(api.ClientOptions{BaseURL: sim.URL, APIKey: sim.APIKey, Timeout: 5 * time.Second}))
+	replaySession(t, recorder)
+	if err := recorder.Save(sessionFixture); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplayRecordedSession(t *testing.T) {
+	if *record {
+		recordSession(t)
+	}
+
+	replayer, err := apitest.LoadReplayer(sessionFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := replaySession(t, replayer)
+
+	expect := map[string][]string{
+		"auth_validate": {"Authentication successful", "dev@example.com"},
+		"project_list":  {"shop", "project-1"},
+		"service_list":  {"Service: app", "Service: db", "Total: 2 services"},
+		"service_info":  {"Service Details: app", "Status: RUNNING", "3000/tcp"},
+		"env_set":       {"API_URL"},
+		"env_list":      {"API_URL", "https://shop.example.com"},
+		"project_info":  {"PROJECT_NOT_FOUND"},
+	}
+	for name, wants := range expect {
+		for _, want := range wants {
+			if !strings.Contains(outputs[name], want) {
+				t.Errorf("%s output does not contain %q:\n%s", name, want, outputs[name])
+			}
+		}
+	}
+
+	if remaining := replayer.Remaining(); len(remaining) > 0 {
+		t.Errorf("%d recorded calls were not replayed, first: %s", len(remaining), remaining[0].Method)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
 // ResponseBuilder helps construct structured responses
@@ -128,7 +129,7 @@ type ProcessWaitConfig struct {
 }
 
 // HandleAsyncProcess manages async operations with optional waiting
-func HandleAsyncProcess(ctx context.Context, client *api.Client, process *api.Process, config ProcessWaitConfig) *mcp.CallToolResult {
+func HandleAsyncProcess(ctx context.Context, client api.ZeropsAPI, process *api.Process, config ProcessWaitConfig) *mcp.CallToolResult {
 	if !config.Wait {
 		return SuccessResponse(map[string]interface{}{
 			"message":    fmt.Sprintf("%s initiated for %s", config.OperationName, config.EntityName),
@@ -150,7 +151,7 @@ func HandleAsyncProcess(ctx context.Context, client *api.Client, process *api.Pr
 }
 
 // HandleProcessTimeout handles process timeout scenarios
-func HandleProcessTimeout(ctx context.Context, client *api.Client, process *api.Process, config ProcessWaitConfig) *mcp.CallToolResult {
+func HandleProcessTimeout(ctx context.Context, client api.ZeropsAPI, process *api.Process, config ProcessWaitConfig) *mcp.CallToolResult {
 	// Try to get current status
 	currentProcess, _ := client.GetProcess(ctx, process.ID)
 	
@@ -169,11 +170,11 @@ func HandleProcessTimeout(ctx context.Context, client *api.Client, process *api.
 		"The operation may still complete successfully",
 	)
 	
-	return ErrorResponse(
+	return ToolErrorResponse(zerrors.New(zerrors.CategoryTimeout,
 		"OPERATION_TIMEOUT",
 		rb.Build(),
 		"Check process status or wait longer",
-	)
+	))
 }
 
 // HandleProcessCompletion handles completed process results
@@ -187,9 +188,9 @@ func HandleProcessCompletion(process *api.Process, config ProcessWaitConfig) *mc
 		})
 	}
 	
-	return ErrorResponse(
+	return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 		"OPERATION_FAILED",
 		fmt.Sprintf("%s failed for %s: %s", config.OperationName, config.EntityName, process.Status),
 		"Check logs for more details about the failure",
-	)
+	))
 }
\ No newline at end of file
@@ -9,53 +9,81 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+	"github.com/zeropsio/zerops-mcp-v3/internal/knowledge"
 )
 
 // RegisterServiceTools registers all service management tools
-func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
+func RegisterServiceTools(s *server.MCPServer, client api.ZeropsAPI, logRules *knowledge.LogRuleSet) {
 	// service_list
+	serviceSortFields := []string{"created", "name", "status", "type"}
 	serviceListTool := mcp.NewTool(
 		"service_list",
-		mcp.WithDescription("List all services in a project"),
-		mcp.WithString("project_id",
-			mcp.Required(),
-			mcp.Description("Project ID to list services from"),
-		),
-		mcp.WithBoolean("include_system",
-			mcp.Description("Include system services (default: false)"),
-		),
+		append([]mcp.ToolOption{
+			mcp.WithDescription("List services in a project with optional filtering, sorting and pagination"),
+			mcp.WithString("project_id",
+				mcp.Required(),
+				mcp.Description("Project ID to list services from"),
+			),
+			mcp.WithBoolean("include_system",
+				mcp.Description("Include system services (default: false)"),
+			),
+			mcp.WithString("type",
+				mcp.Description("Only include services whose type contains this text (e.g., 'nodejs', 'postgresql@16')"),
+			),
+		}, listOptions(serviceSortFields, "created")...)...,
 	)
 
-	s.AddTool(serviceListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceListTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectID, err := request.RequireString("project_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_ID",
 				"Project ID is required",
 				"Provide a valid project ID from 'project_list' tool",
-			), nil
+			)), nil
 		}
 
 		includeSystem := request.GetBool("include_system", false)
+		query, errResult := parseListQuery(request, serviceSortFields, "created")
+		if errResult != nil {
+			return errResult, nil
+		}
 
-		// List services
-		services, err := client.ListServices(ctx, projectID)
+		// List all services across every search page
+		allServices, err := client.ListServices(ctx, projectID)
 		if err != nil {
 			return HandleAPIError(err), nil
 		}
 
-		// Build response
-		var response strings.Builder
-		response.WriteString(fmt.Sprintf("Services in project %s:\n\n", projectID))
-
-		filteredCount := 0
-		for _, svc := range services {
+		services := make([]api.Service, 0, len(allServices))
+		for _, svc := range allServices {
 			// Skip system services if not requested
 			if svc.ServiceStackTypeInfo.ServiceStackTypeCategory == "system" && !includeSystem {
 				continue
 			}
-			filteredCount++
+			if !matchesText(svc.Name, query.Name) || !matchesExact(svc.Status, query.Status) || !matchesText(svc.ServiceStackTypeInfo.ServiceStackTypeVersionName, query.Type) {
+				continue
+			}
+			services = append(services, svc)
+		}
+
+		sortItems(services, query, map[string]func(a, b api.Service) bool{
+			"created": func(a, b api.Service) bool { return a.Created.Before(b.Created) },
+			"name":    func(a, b api.Service) bool { return a.Name < b.Name },
+			"status":  func(a, b api.Service) bool { return a.Status < b.Status },
+			"type": func(a, b api.Service) bool {
+				return a.ServiceStackTypeInfo.ServiceStackTypeVersionName < b.ServiceStackTypeInfo.ServiceStackTypeVersionName
+			},
+		})
+		page, nextCursor := pageItems(services, query)
 
+		// Build response
+		var response strings.Builder
+		response.WriteString(fmt.Sprintf("Services in project %s:\n\n", projectID))
+
+		items := make([]map[string]interface{}, 0, len(page))
+		for _, svc := range page {
 			response.WriteString(fmt.Sprintf("Service: %s\n", svc.Name))
 			response.WriteString(fmt.Sprintf("  ID: %s\n", svc.ID))
 			response.WriteString(fmt.Sprintf("  Type: %s\n", svc.ServiceStackTypeInfo.ServiceStackTypeVersionName))
@@ -74,20 +102,43 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 				}
 			}
 			// Show subdomain if available
+			subdomainURL := ""
 			if svc.SubdomainAccess && svc.ZeropsSubdomainHost != nil && *svc.ZeropsSubdomainHost != "" {
-				response.WriteString(fmt.Sprintf("  Subdomain: https://%s\n", *svc.ZeropsSubdomainHost))
+				subdomainURL = "https://" + *svc.ZeropsSubdomainHost
+				response.WriteString(fmt.Sprintf("  Subdomain: %s\n", subdomainURL))
 			}
 			response.WriteString("\n")
+
+			items = append(items, map[string]interface{}{
+				"id":             svc.ID,
+				"name":           svc.Name,
+				"type":           svc.ServiceStackTypeInfo.ServiceStackTypeVersionName,
+				"category":       svc.ServiceStackTypeInfo.ServiceStackTypeCategory,
+				"status":         svc.Status,
+				"mode":           svc.Mode,
+				"min_containers": svc.MinContainers,
+				"max_containers": svc.MaxContainers,
+				"subdomain_url":  subdomainURL,
+				"created":        svc.Created,
+			})
 		}
 
-		if filteredCount == 0 {
-			if includeSystem {
+		if len(services) == 0 {
+			switch {
+			case query.Name != "" || query.Status != "" || query.Type != "":
+				response.WriteString("No services match the given filters.\n")
+			case includeSystem:
 				response.WriteString("No services found in this project.\n")
-			} else {
+			default:
 				response.WriteString("No user services found. Use include_system=true to see system services.\n")
 			}
+		} else if len(page) < len(services) {
+			response.WriteString(fmt.Sprintf("Showing %d-%d of %d services\n", query.Offset+1, query.Offset+len(page), len(services)))
 		} else {
-			response.WriteString(fmt.Sprintf("Total: %d services\n", filteredCount))
+			response.WriteString(fmt.Sprintf("Total: %d services\n", len(services)))
+		}
+		if nextCursor != "" {
+			response.WriteString(fmt.Sprintf("More services available: call 'service_list' with cursor='%s'\n", nextCursor))
 		}
 
 		response.WriteString("\nNext steps:\n")
@@ -95,7 +146,14 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		response.WriteString("- Use 'service_logs' to view service logs\n")
 		response.WriteString("- Use 'project_import' to add more services\n")
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"project_id":  projectID,
+			"total":       len(services),
+			"count":       len(page),
+			"offset":      query.Offset,
+			"next_cursor": nextCursor,
+			"items":       items,
+		}), nil
 	})
 
 	// service_info
@@ -111,14 +169,14 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(serviceInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		includeEnv := request.GetBool("include_env", true)
@@ -193,6 +251,7 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		}
 
 		// Environment Variables
+		envVariables := map[string]string{}
 		if includeEnv && len(service.EnvVariables) > 0 {
 			response.WriteString("\nEnvironment Variables:\n")
 			for key, value := range service.EnvVariables {
@@ -201,6 +260,7 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 				if isSensitiveKey(key) {
 					displayValue = "***masked***"
 				}
+				envVariables[key] = displayValue
 				response.WriteString(fmt.Sprintf("  %s: %s\n", key, displayValue))
 			}
 		}
@@ -218,13 +278,46 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		}
 		response.WriteString("- Use 'deploy_push' to deploy code\n")
 
-		return mcp.NewToolResultText(response.String()), nil
+		ports := make([]map[string]interface{}, 0, len(service.Ports))
+		for _, port := range service.Ports {
+			ports = append(ports, map[string]interface{}{
+				"port":     port.Port,
+				"protocol": port.Protocol,
+				"public":   port.Public,
+				"http":     port.HTTPRouting,
+			})
+		}
+		subdomainURL := ""
+		if service.SubdomainAccess && service.ZeropsSubdomainHost != nil && *service.ZeropsSubdomainHost != "" {
+			subdomainURL = "https://" + *service.ZeropsSubdomainHost
+		}
+
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"id":                service.ID,
+			"name":              service.Name,
+			"project_id":        service.ProjectID,
+			"type":              service.ServiceStackTypeInfo.ServiceStackTypeVersionName,
+			"category":          service.ServiceStackTypeInfo.ServiceStackTypeCategory,
+			"status":            service.Status,
+			"mode":              service.Mode,
+			"created":           service.Created,
+			"last_update":       service.LastUpdate,
+			"min_containers":    service.MinContainers,
+			"max_containers":    service.MaxContainers,
+			"vertical_scaling":  service.VerticalScaling,
+			"auto_scaling":      service.AutoScaling != nil && service.AutoScaling.Enabled,
+			"ports":             ports,
+			"subdomain_access":  service.SubdomainAccess,
+			"subdomain_url":     subdomainURL,
+			"env_variables":     envVariables,
+			"internal_hostname": service.Name,
+		}), nil
 	})
 
 	// service_logs
 	serviceLogsTool := mcp.NewTool(
 		"service_logs",
-		mcp.WithDescription("Get logs from a service"),
+		mcp.WithDescription("Get logs from a service, optionally filtered by container, time range, severity and text"),
 		mcp.WithString("service_id",
 			mcp.Required(),
 			mcp.Description("Service ID to get logs from"),
@@ -238,36 +331,91 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		mcp.WithString("since",
 			mcp.Description("Show logs since timestamp (RFC3339 format) or duration (e.g., '5m', '1h')"),
 		),
+		mcp.WithString("until",
+			mcp.Description("Show logs until timestamp (RFC3339 format) or duration ago (e.g., '5m')"),
+		),
+		mcp.WithString("severity",
+			mcp.Enum(api.SeverityNames()...),
+			mcp.Description("Minimum severity to include (e.g., 'error' returns error, critical, alert and emergency lines)"),
+		),
+		mcp.WithString("search",
+			mcp.Description("Only include lines whose message contains this text (case-insensitive)"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Only include lines whose message matches this regular expression"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Return only lines after this cursor (the next_cursor of a previous call), oldest first so no line is skipped"),
+		),
+		mcp.WithBoolean("forward",
+			mcp.Description("Return the oldest lines instead of the newest, to page through raw lines from the start of the log window (default: false; lines after a cursor are always oldest first)"),
+		),
+		mcp.WithBoolean("summarize",
+			mcp.Description("Return a summary instead of raw lines: similar lines clustered with counts and examples, stack traces kept together (default limit: 1000)"),
+		),
+		mcp.WithNumber("max_clusters",
+			mcp.Description("Maximum number of clusters in a summary (default: 20)"),
+		),
 	)
 
-	s.AddTool(serviceLogsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceLogsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
-		container := request.GetString("container", "")
-		limit := request.GetInt("limit", 100)
-		if limit > 1000 {
-			limit = 1000
+		query, errResult := parseLogQuery(request, 100)
+		if errResult != nil {
+			return errResult, nil
+		}
+		query.ServiceID = serviceID
+		// Lines after a cursor are paged forward; keeping the newest would skip the
+		// lines between the cursor and them
+		query.Oldest = request.GetBool("forward", false) || query.Cursor != ""
+		summarize := request.GetBool("summarize", false)
+		if summarize && request.GetInt("limit", 0) <= 0 {
+			query.Limit = api.MaxLogLimit
+		}
+
+		fetch := query
+		if summarize && !query.Oldest && fetch.Limit < api.MaxLogLimit {
+			// One extra line anchors the raw-line cursor just before the summarized window
+			fetch.Limit++
 		}
-		since := request.GetString("since", "")
 
 		// Get logs
-		logs, err := client.GetServiceLogs(ctx, serviceID, container, limit, since)
+		entries, err := client.GetServiceLogs(ctx, fetch)
 		if err != nil {
 			return HandleAPIError(err), nil
 		}
+		rawCursor := query.Cursor
+		if len(entries) > query.Limit {
+			rawCursor = api.LogCursor(entries[0])
+			entries = entries[1:]
+		}
+		logs := api.LogLines(entries)
+		serviceType := lookupServiceType(ctx, client, serviceID)
+		issues := AnalyzeLogs(logRules, entries, serviceType, knowledge.LogSourceRuntime)
+
+		nextCursor := query.Cursor
+		if len(entries) > 0 {
+			nextCursor = api.LogCursor(entries[len(entries)-1])
+		}
+
+		if summarize {
+			summary := summarizeLogs(entries, request.GetInt("max_clusters", defaultMaxClusters))
+			return logSummaryResponse(fmt.Sprintf("service %s", serviceID), query, summary, issues, rawCursor, nextCursor), nil
+		}
 
 		// Build response
 		var response strings.Builder
 		response.WriteString(fmt.Sprintf("Logs for service %s", serviceID))
-		if container != "" {
-			response.WriteString(fmt.Sprintf(" (container: %s)", container))
+		if filters := describeLogQuery(query); filters != "" {
+			response.WriteString(fmt.Sprintf(" (%s)", filters))
 		}
 		response.WriteString("\n\n")
 
@@ -277,6 +425,7 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 			response.WriteString("- Service has not started yet\n")
 			response.WriteString("- Service crashed before producing logs\n")
 			response.WriteString("- Logs may have been rotated\n")
+			response.WriteString("- The filters exclude all lines\n")
 			response.WriteString("\nNext steps:\n")
 			response.WriteString("- Use 'service_info' to check service status\n")
 			response.WriteString("- Use 'deploy_status' to check deployment status\n")
@@ -286,30 +435,209 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 				response.WriteString("\n")
 			}
 			
-			// Analyze logs for common runtime errors
-			errorAnalysis := AnalyzeRuntimeError(logs)
-			if errorAnalysis != "" {
+			// Analyze logs with the rules for the service's runtime
+			if errorAnalysis := FormatLogAnalysis(issues); errorAnalysis != "" {
 				response.WriteString(errorAnalysis)
+				response.WriteString("\n")
 			}
 		}
 
-		response.WriteString(fmt.Sprintf("\nShowing last %d lines\n", len(logs)))
+		switch {
+		case query.Oldest && query.Cursor != "":
+			response.WriteString(fmt.Sprintf("\nShowing %d lines after the cursor, oldest first\n", len(logs)))
+		case query.Oldest:
+			response.WriteString(fmt.Sprintf("\nShowing the oldest %d lines\n", len(logs)))
+		default:
+			response.WriteString(fmt.Sprintf("\nShowing last %d lines\n", len(logs)))
+		}
+		if nextCursor != "" {
+			response.WriteString(fmt.Sprintf("Next cursor: %s\n", nextCursor))
+		}
+		if query.Oldest && len(entries) == query.Limit {
+			response.WriteString("More lines may follow: call again with the next cursor\n")
+		}
 		response.WriteString("\nTips:\n")
 		response.WriteString("- Use 'container' parameter to filter by container type (runtime, prepare, init)\n")
 		response.WriteString("- Use 'since' parameter to get recent logs (e.g., since='5m')\n")
+		response.WriteString("- Use 'severity' parameter to see only problems (e.g., severity='error')\n")
+		response.WriteString("- Use 'cursor' with the next cursor to get only newer lines\n")
 		response.WriteString("- Use 'limit' parameter to get more logs (max 1000)\n")
+		response.WriteString("- Use 'summarize' to cluster large outputs into counts and examples\n")
 		
 		// Add specific tips based on service type
-		if service, err := client.GetService(ctx, serviceID); err == nil {
-			serviceType := service.ServiceStackTypeInfo.ServiceStackTypeVersionName
+		var runtimeTips []string
+		if serviceType != "" {
 			if strings.Contains(serviceType, "python") || strings.Contains(serviceType, "nodejs") {
+				runtimeTips = []string{
+					"Ensure dependencies are installed with 'prepareCommands' in zerops.yml",
+					"Check that your start command matches your application entry point",
+				}
 				response.WriteString("\nRuntime Tips:\n")
-				response.WriteString("- Ensure dependencies are installed with 'prepareCommands' in zerops.yml\n")
-				response.WriteString("- Check that your start command matches your application entry point\n")
+				for _, tip := range runtimeTips {
+					response.WriteString("- " + tip + "\n")
+				}
 			}
 		}
 
-		return mcp.NewToolResultText(response.String()), nil
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"service_id":     serviceID,
+			"container":      query.Container,
+			"limit":          query.Limit,
+			"since":          request.GetString("since", ""),
+			"until":          request.GetString("until", ""),
+			"severity":       query.MinSeverity,
+			"search":         query.Text,
+			"pattern":        query.Pattern,
+			"count":          len(entries),
+			"entries":        entries,
+			"next_cursor":    nextCursor,
+			"forward":        query.Oldest,
+			"error_analysis": FormatLogAnalysis(issues),
+			"issues":         issues,
+			"runtime_tips":   runtimeTips,
+		}), nil
+	})
+
+	// service_logs_follow
+	serviceLogsFollowTool := mcp.NewTool(
+		"service_logs_follow",
+		mcp.WithDescription("Follow (tail) service logs for a bounded time. New lines are streamed as progress and log notifications; the result summarizes everything seen and detected issues"),
+		mcp.WithString("service_id",
+			mcp.Required(),
+			mcp.Description("Service ID to follow logs from"),
+		),
+		mcp.WithNumber("duration",
+			mcp.Description(fmt.Sprintf("How long to follow in seconds (default: %d, max: %d)", int(defaultFollowDuration.Seconds()), int(maxFollowDuration.Seconds()))),
+		),
+		mcp.WithNumber("interval",
+			mcp.Description(fmt.Sprintf("Polling interval in seconds (default: %d)", int(defaultFollowInterval.Seconds()))),
+		),
+		mcp.WithNumber("tail",
+			mcp.Description("Number of recent lines to include before following (default: 10)"),
+		),
+		mcp.WithString("container",
+			mcp.Description("Container name (runtime, prepare, init) - defaults to all"),
+		),
+		mcp.WithString("severity",
+			mcp.Enum(api.SeverityNames()...),
+			mcp.Description("Minimum severity to include (e.g., 'warning')"),
+		),
+		mcp.WithString("search",
+			mcp.Description("Only include lines whose message contains this text (case-insensitive)"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Start after this cursor (the next_cursor of a previous call) instead of the recent tail"),
+		),
+	)
+
+	addTool(s, serviceLogsFollowTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		serviceID, err := request.RequireString("service_id")
+		if err != nil {
+			return ToolErrorResponse(zerrors.NewValidationError(
+				"INVALID_SERVICE_ID",
+				"Service ID is required",
+				"Provide a valid service ID from 'service_list' tool",
+			)), nil
+		}
+
+		query, errResult := parseLogQuery(request, api.MaxLogLimit)
+		if errResult != nil {
+			return errResult, nil
+		}
+		query.ServiceID = serviceID
+		duration := followDuration(request, "duration", defaultFollowDuration, minFollowInterval, maxFollowDuration)
+		interval := followDuration(request, "interval", defaultFollowInterval, minFollowInterval, maxFollowDuration)
+		tail := request.GetInt("tail", 10)
+
+		n := newNotifier(ctx, request, "service_logs_follow")
+
+		// Start from the recent tail unless the caller resumes from a cursor
+		var recent []api.LogEntry
+		if query.Cursor == "" {
+			tailQuery := query
+			tailQuery.Limit = tail
+			if tail <= 0 {
+				tailQuery.Limit = 1
+			}
+			recent, err = client.GetServiceLogs(ctx, tailQuery)
+			if err != nil {
+				return HandleAPIError(err), nil
+			}
+			if len(recent) > 0 {
+				query.Cursor = api.LogCursor(recent[len(recent)-1])
+			}
+			if tail <= 0 {
+				recent = nil
+			}
+			for _, entry := range recent {
+				n.Progress(entry.String())
+			}
+		}
+
+		started := time.Now()
+		followed := followLogs(ctx, client, query, duration, interval, n)
+		elapsed := time.Since(started).Round(time.Second)
+
+		seen := append(recent, followed.Entries...)
+		lines := api.LogLines(seen)
+		issues := AnalyzeLogs(logRules, seen, lookupServiceType(ctx, client, serviceID), knowledge.LogSourceRuntime)
+		analysis := FormatLogAnalysis(issues)
+
+		var response strings.Builder
+		response.WriteString(fmt.Sprintf("Followed logs for service %s for %s (%s)", serviceID, elapsed, followed.StopReason))
+		if filters := describeLogQuery(query); filters != "" {
+			response.WriteString(fmt.Sprintf("\nFilters: %s", filters))
+		}
+		response.WriteString("\n\n")
+
+		if len(lines) == 0 {
+			response.WriteString("No log lines received.\n")
+		} else {
+			if len(recent) > 0 {
+				response.WriteString(fmt.Sprintf("Recent lines (%d):\n", len(recent)))
+				for _, line := range api.LogLines(recent) {
+					response.WriteString(line + "\n")
+				}
+				response.WriteString("\n")
+			}
+			response.WriteString(fmt.Sprintf("New lines while following (%d):\n", followed.Streamed))
+			for _, line := range api.LogLines(followed.Entries) {
+				response.WriteString(line + "\n")
+			}
+			if followed.Streamed > len(followed.Entries) {
+				response.WriteString(fmt.Sprintf("(%d earlier lines were streamed as notifications only)\n", followed.Streamed-len(followed.Entries)))
+			}
+		}
+
+		if analysis != "" {
+			response.WriteString(analysis)
+			response.WriteString("\n")
+		}
+		if followed.LastError != nil {
+			response.WriteString(fmt.Sprintf("\nLast poll error: %v\n", followed.LastError))
+		}
+		if followed.NextCursor != "" {
+			response.WriteString(fmt.Sprintf("\nNext cursor: %s\n", followed.NextCursor))
+		}
+		response.WriteString("\nNext step: Call 'service_logs_follow' again with the next cursor to continue without duplicate lines")
+
+		lastError := ""
+		if followed.LastError != nil {
+			lastError = followed.LastError.Error()
+		}
+
+		return StructuredResponse(response.String(), map[string]interface{}{
+			"service_id":     serviceID,
+			"duration":       elapsed.Seconds(),
+			"stop_reason":    followed.StopReason,
+			"recent":         recent,
+			"entries":        followed.Entries,
+			"streamed":       followed.Streamed,
+			"next_cursor":    followed.NextCursor,
+			"error_analysis": analysis,
+			"issues":         issues,
+			"last_error":     lastError,
+		}), nil
 	})
 
 	// service_start
@@ -322,26 +650,26 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(serviceStartTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceStartTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		// Start the service
 		process, err := client.StartService(ctx, serviceID)
 		if err != nil {
 			// Check if service is already running
-			if strings.Contains(err.Error(), "already running") || strings.Contains(err.Error(), "invalidServiceStackStatus") {
-				return ErrorResponse(
+			if strings.Contains(err.Error(), "already running") || zerrors.HasCode(err, "invalidServiceStackStatus") {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"SERVICE_ALREADY_RUNNING",
 					"Service is already running",
 					"The service is already in a running state",
-				), nil
+				)), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -364,26 +692,26 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(serviceStopTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceStopTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		// Stop the service
 		process, err := client.StopService(ctx, serviceID)
 		if err != nil {
 			// Check if service is already stopped
-			if strings.Contains(err.Error(), "already stopped") || strings.Contains(err.Error(), "invalidServiceStackStatus") {
-				return ErrorResponse(
+			if strings.Contains(err.Error(), "already stopped") || zerrors.HasCode(err, "invalidServiceStackStatus") {
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 					"SERVICE_ALREADY_STOPPED",
 					"Service is already stopped",
 					"The service is already in a stopped state",
-				), nil
+				)), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -410,23 +738,23 @@ func RegisterServiceTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(serviceDeleteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, serviceDeleteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		confirm := request.GetBool("confirm", false)
 		if !confirm {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"CONFIRMATION_REQUIRED",
 				"Service deletion requires confirmation",
 				"Set confirm=true to delete the service",
-			), nil
+			)), nil
 		}
 
 		// Delete the service
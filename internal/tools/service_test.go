@@ -0,0 +1,128 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+func TestServiceLogsDetectsIssues(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "Error: Cannot find module 'express'", Severity: 3})
+	c := startTools(t, sim)
+
+	text, isError := callText(t, c, "service_logs", map[string]interface{}{"service_id": app.ID})
+	if isError {
+		t.Fatalf("service_logs failed:\n%s", text)
+	}
+	if !strings.Contains(text, "Cannot find module 'express'") || !strings.Contains(text, "Detected Issues") {
+		t.Errorf("expected the log line and a detected issue, got:\n%s", text)
+	}
+}
+
+func TestServiceLogsCursorReturnsOnlyNewerLines(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "first line"}, apitest.LogItem{Message: "second line"})
+	c := startTools(t, sim)
+
+	var data struct {
+		NextCursor string `json:"next_cursor"`
+		Count      int    `json:"count"`
+	}
+	out := callJSON(t, c, "service_logs", map[string]interface{}{"service_id": app.ID})
+	if err := json.Unmarshal(out.Data, &data); err != nil || data.Count == 0 || data.NextCursor == "" {
+		t.Fatalf("service_logs = %s, %v", out.Data, err)
+	}
+
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "third line"})
+	text, isError := callText(t, c, "service_logs", map[string]interface{}{"service_id": app.ID, "cursor": data.NextCursor})
+	if isError || !strings.Contains(text, "third line") || strings.Contains(text, "second line") {
+		t.Errorf("expected only the line after the cursor, got:\n%s", text)
+	}
+
+	// More new lines than the limit are paged from the cursor, oldest first
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "fourth line"}, apitest.LogItem{Message: "fifth line"}, apitest.LogItem{Message: "sixth line"})
+	var page struct {
+		NextCursor string `json:"next_cursor"`
+		Entries    []struct {
+			Message string `json:"message"`
+		} `json:"entries"`
+	}
+	cursor := data.NextCursor
+	var messages []string
+	for i := 0; i < 3; i++ {
+		out := callJSON(t, c, "service_logs", map[string]interface{}{"service_id": app.ID, "cursor": cursor, "limit": 2})
+		if err := json.Unmarshal(out.Data, &page); err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range page.Entries {
+			messages = append(messages, entry.Message)
+		}
+		cursor = page.NextCursor
+	}
+	if got := strings.Join(messages, ", "); got != "third line, fourth line, fifth line, sixth line" {
+		t.Errorf("paged lines = %s, want every line after the cursor in order", got)
+	}
+
+	out = callJSON(t, c, "service_logs", map[string]interface{}{"service_id": app.ID, "cursor": "log-000001"})
+	if out.OK || out.Error == nil || out.Error.Code != "INVALID_CURSOR" {
+		t.Errorf("expected INVALID_CURSOR for a raw entry ID, got %+v", out.Error)
+	}
+}
+
+func TestServiceLogsFollowStreamsNewLines(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.AddLogs(app.ID, apitest.LogItem{Message: "before following"})
+	c := startTools(t, sim)
+
+	time.AfterFunc(200*time.Millisecond, func() {
+		sim.AddLogs(app.ID, apitest.LogItem{Message: "Error: Cannot find module 'express'", Severity: 3})
+	})
+	out := callJSON(t, c, "service_logs_follow", map[string]interface{}{"service_id": app.ID, "duration": 1, "interval": 0.5, "tail": 1})
+	if !out.OK {
+		t.Fatalf("service_logs_follow failed: %+v", out.Error)
+	}
+	var data struct {
+		Recent []struct {
+			Message string `json:"message"`
+		} `json:"recent"`
+		Entries []struct {
+			Message string `json:"message"`
+		} `json:"entries"`
+		Issues     []json.RawMessage `json:"issues"`
+		NextCursor string            `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(out.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Recent) != 1 || data.Recent[0].Message != "before following" {
+		t.Errorf("recent = %+v, want the last line before following", data.Recent)
+	}
+	if len(data.Entries) != 1 || len(data.Issues) == 0 {
+		t.Errorf("entries = %+v, issues = %d, want the new line and its issue", data.Entries, len(data.Issues))
+	}
+
+	// Resuming from the cursor returns neither the tail nor lines already followed
+	out = callJSON(t, c, "service_logs_follow", map[string]interface{}{"service_id": app.ID, "duration": 0.5, "cursor": data.NextCursor})
+	if err := json.Unmarshal(out.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Recent) != 0 || len(data.Entries) != 0 {
+		t.Errorf("resumed follow returned %+v and %+v, want no lines", data.Recent, data.Entries)
+	}
+}
@@ -9,10 +9,11 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
 // RegisterSubdomainTools registers all subdomain-related tools
-func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
+func RegisterSubdomainTools(s *server.MCPServer, client api.ZeropsAPI) {
 	// Create subdomain_enable tool
 	subdomainEnableTool := mcp.NewTool(
 		"subdomain_enable",
@@ -23,14 +24,14 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(subdomainEnableTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, subdomainEnableTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		wait := request.GetBool("wait", true)
@@ -49,15 +50,9 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 				return HandleAPIError(err), nil
 			}
 
-			var url string
-			if project.ZeropsSubdomainHost != nil && *project.ZeropsSubdomainHost != "" {
-				// Find the HTTP port
-				for _, port := range service.Ports {
-					if port.HTTPRouting || port.Scheme == "http" || port.Scheme == "https" {
-						url = GenerateSubdomainURL(service.Name, *project.ZeropsSubdomainHost, port.Port)
-						break
-					}
-				}
+			url, err := serviceSubdomainURL(ctx, client, service, project)
+			if err != nil {
+				return HandleAPIError(err), nil
 			}
 			return SuccessResponse(map[string]interface{}{
 				"message":         "Subdomain access is already enabled",
@@ -78,32 +73,31 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 		}
 
 		if !hasHTTPPort {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"NO_HTTP_PORTS",
 				fmt.Sprintf("Service '%s' does not have HTTP/HTTPS ports configured", service.Name),
 				"Only services with HTTP/HTTPS ports can have subdomain access. Add ports with httpSupport: true in your service configuration.",
-			), nil
+			)), nil
 		}
 
 		// Check service status
 		if service.Status != "ACTIVE" && service.Status != "RUNNING" {
-			return ErrorResponseWithNext(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SERVICE_NOT_ACTIVE",
 				fmt.Sprintf("Service '%s' is in %s state", service.Name, service.Status),
 				"Service must be in ACTIVE or RUNNING state. Deploy your application or start the service first.",
-				"service_start",
-			), nil
+			).WithNextTool("service_start")), nil
 		}
 
 		// Enable subdomain access
 		process, err := client.EnableSubdomainAccess(ctx, serviceID)
 		if err != nil {
-			if strings.Contains(err.Error(), "serviceStackIsNotHttp") {
-				return ErrorResponse(
+			if zerrors.HasCode(err, "serviceStackIsNotHttp") {
+				return ToolErrorResponse(zerrors.NewValidationError(
 					"NOT_HTTP_SERVICE",
 					"Service is not configured as HTTP/HTTPS service",
 					"Ensure your service has ports configured with 'httpSupport: true' in the import YAML",
-				), nil
+				)), nil
 			}
 			return HandleAPIError(err), nil
 		}
@@ -143,62 +137,55 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 					// Continue to get service details below
 					completedProcess = currentProcess
 				} else {
-					return ErrorResponse(
+					return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 						"SUBDOMAIN_ENABLE_FAILED",
 						fmt.Sprintf("Subdomain enable process failed with status: %s", currentProcess.Status),
 						"Check service logs for more details or try again",
-					), nil
+					)), nil
 				}
 			} else {
 				// Couldn't get process status, return original error
-				return ErrorResponse(
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryTimeout,
 					"PROCESS_WAIT_TIMEOUT",
 					fmt.Sprintf("Timed out waiting for subdomain enable process after 30 seconds"),
 					fmt.Sprintf("Use 'process_status' with process_id=%s to check current status, or 'subdomain_status' to verify if subdomain was enabled", process.ID),
-				), nil
+				)), nil
 			}
 		}
 
 		// Check if process succeeded (if we didn't already handle it above)
 		if completedProcess != nil && completedProcess.Status != "SUCCESS" && completedProcess.Status != "FINISHED" {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SUBDOMAIN_ENABLE_FAILED",
 				fmt.Sprintf("Failed to enable subdomain access: process status %s", completedProcess.Status),
 				"Check service configuration and ensure it has HTTP ports configured",
-			), nil
-		}
-
-		// Get updated service details
-		updatedService, err := client.GetService(ctx, serviceID)
-		if err != nil {
-			return HandleAPIError(err), nil
-		}
-
-		// Get project details for zeropsSubdomainHost
-		project, err := client.GetProject(ctx, updatedService.ProjectID)
-		if err != nil {
-			return HandleAPIError(err), nil
+			)), nil
 		}
 
+		// Access is enabled at this point, so failing to look up the URL is only a warning
 		var subdomainURL string
-		if updatedService.SubdomainAccess && project.ZeropsSubdomainHost != nil && *project.ZeropsSubdomainHost != "" {
-			// Find the HTTP port
-			for _, port := range updatedService.Ports {
-				if port.HTTPRouting || port.Scheme == "http" || port.Scheme == "https" {
-					subdomainURL = GenerateSubdomainURL(updatedService.Name, *project.ZeropsSubdomainHost, port.Port)
-					break
-				}
+		updatedService, err := client.GetService(ctx, serviceID)
+		if err == nil {
+			var project *api.Project
+			project, err = client.GetProject(ctx, updatedService.ProjectID)
+			if err == nil && updatedService.SubdomainAccess {
+				subdomainURL, err = serviceSubdomainURL(ctx, client, updatedService, project)
 			}
 		}
 
-		return SuccessResponse(map[string]interface{}{
+		result := map[string]interface{}{
 			"message":       fmt.Sprintf("Successfully enabled subdomain access for service '%s'", service.Name),
 			"service_id":    serviceID,
 			"service_name":  service.Name,
 			"subdomain_url": subdomainURL,
 			"process_id":    process.ID,
 			"next_step":     "Your service is now accessible via the subdomain URL",
-		}), nil
+		}
+		if err != nil {
+			result["warning"] = fmt.Sprintf("The subdomain URL could not be determined: %v", err)
+			result["next_step"] = "Use 'subdomain_status' to get the subdomain URL"
+		}
+		return SuccessResponse(result), nil
 	})
 
 	// Create subdomain_disable tool
@@ -211,14 +198,14 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(subdomainDisableTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, subdomainDisableTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		wait := request.GetBool("wait", true)
@@ -280,29 +267,29 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 					// Continue to return success below
 					completedProcess = currentProcess
 				} else {
-					return ErrorResponse(
+					return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 						"SUBDOMAIN_DISABLE_FAILED",
 						fmt.Sprintf("Subdomain disable process failed with status: %s", currentProcess.Status),
 						"Check service logs for more details or try again",
-					), nil
+					)), nil
 				}
 			} else {
 				// Couldn't get process status, return timeout error
-				return ErrorResponse(
+				return ToolErrorResponse(zerrors.New(zerrors.CategoryTimeout,
 					"PROCESS_WAIT_TIMEOUT",
 					fmt.Sprintf("Timed out waiting for subdomain disable process after 20 seconds"),
 					fmt.Sprintf("Use 'process_status' with process_id=%s to check current status", process.ID),
-				), nil
+				)), nil
 			}
 		}
 
 		// Check if process succeeded (if we didn't already handle it above)
 		if completedProcess != nil && completedProcess.Status != "SUCCESS" && completedProcess.Status != "FINISHED" {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SUBDOMAIN_DISABLE_FAILED",
 				fmt.Sprintf("Failed to disable subdomain access: process status %s", completedProcess.Status),
 				"Try again or contact support if the issue persists",
-			), nil
+			)), nil
 		}
 
 		return SuccessResponse(map[string]interface{}{
@@ -323,14 +310,14 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 		),
 	)
 
-	s.AddTool(subdomainStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, subdomainStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		serviceID, err := request.RequireString("service_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_ID",
 				"Service ID is required",
 				"Provide a valid service ID from 'service_list' tool",
-			), nil
+			)), nil
 		}
 
 		// Get service details
@@ -368,14 +355,12 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 			return HandleAPIError(err), nil
 		}
 
-		var subdomainURL string
-		if service.SubdomainAccess && project.ZeropsSubdomainHost != nil && *project.ZeropsSubdomainHost != "" {
-			// Find the HTTP port
-			for _, port := range service.Ports {
-				if port.HTTPRouting || port.Scheme == "http" || port.Scheme == "https" {
-					subdomainURL = GenerateSubdomainURL(service.Name, *project.ZeropsSubdomainHost, port.Port)
-					break
-				}
+		// The URL is informational; a failed region lookup leaves it empty
+		var subdomainURL, urlWarning string
+		if service.SubdomainAccess {
+			subdomainURL, err = serviceSubdomainURL(ctx, client, service, project)
+			if err != nil {
+				urlWarning = fmt.Sprintf("The subdomain URL could not be determined: %v", err)
 			}
 		}
 
@@ -391,6 +376,10 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 			"ports":               portInfo,
 		}
 
+		if urlWarning != "" {
+			response["warning"] = urlWarning
+		}
+
 		if !canHaveSubdomain {
 			reasons := []string{}
 			if serviceCategory != "USER" {
@@ -416,4 +405,24 @@ func RegisterSubdomainTools(s *server.MCPServer, client *api.Client) {
 
 		return SuccessResponse(response), nil
 	})
-}
\ No newline at end of file
+}
+
+// serviceSubdomainURL returns the public subdomain URL of a service's first HTTP port
+// in the project's region, or an empty string when it has none
+func serviceSubdomainURL(ctx context.Context, client api.ZeropsAPI, service *api.ServiceDetails, project *api.Project) (string, error) {
+	if project.ZeropsSubdomainHost == nil || *project.ZeropsSubdomainHost == "" {
+		return "", nil
+	}
+
+	// Find the HTTP port
+	for _, port := range service.Ports {
+		if port.HTTPRouting || port.Scheme == "http" || port.Scheme == "https" {
+			region, err := api.ProjectRegion(ctx, client, project)
+			if err != nil {
+				return "", err
+			}
+			return GenerateSubdomainURL(service.Name, *project.ZeropsSubdomainHost, region.SubdomainDomain(), port.Port), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,46 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+)
+
+func TestSubdomainEnableSurvivesRegionLookupFailure(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	sim.ProcessPolls = 0
+	project := sim.AddProject("demo")
+	app := sim.AddService(project.ID, "app", "nodejs@20")
+	sim.SimulateDeploy(app.ID, 3000)
+	sim.InjectError(apitest.InjectedError{
+		Method:     "GET",
+		Path:       "/api/rest/public/region",
+		StatusCode: 400,
+		Code:       "regionLookupFailed",
+		Message:    "Region lookup failed",
+	})
+	c := startTools(t, sim)
+
+	for _, tool := range []string{"subdomain_enable", "subdomain_status"} {
+		out := callJSON(t, c, tool, map[string]interface{}{"service_id": app.ID})
+		if !out.OK {
+			t.Fatalf("%s failed although subdomain access is enabled: %+v", tool, out.Error)
+		}
+		var data struct {
+			SubdomainURL string `json:"subdomain_url"`
+			Warning      string `json:"warning"`
+		}
+		if err := json.Unmarshal(out.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if data.SubdomainURL != "" || data.Warning == "" {
+			t.Errorf("%s: expected an empty URL with a warning, got %+v", tool, data)
+		}
+	}
+
+	if service, _ := sim.Service(app.ID); !service.SubdomainAccess {
+		t.Error("subdomain access was not enabled on the server")
+	}
+}
@@ -0,0 +1,269 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	"github.com/zeropsio/zerops-mcp-v3/internal/api/apitest"
+	"github.com/zeropsio/zerops-mcp-v3/internal/config"
+	"github.com/zeropsio/zerops-mcp-v3/internal/tools"
+)
+
+// envelope is the JSON output of a tool called with format=json
+type envelope struct {
+	Tool  string          `json:"tool"`
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error *struct {
+		Code     string `json:"code"`
+		Category string `json:"category"`
+	} `json:"error"`
+}
+
+// startTools registers all tools against the fake API and connects an in-process client
+func startTools(t *testing.T, sim *apitest.Server) *client.Client {
+	t.Helper()
+	return connectTools(t, api.NewClient(api.ClientOptions{BaseURL: sim.URL, APIKey: sim.APIKey, Timeout: 5 * time.Second}))
+}
+
+// connectTools registers all tools using apiClient and connects an in-process client
+func connectTools(t *testing.T, apiClient api.ZeropsAPI) *client.Client {
+	t.Helper()
+
+	cfg := &config.Config{
+		ZeropsAPIKey:  apitest.DefaultAPIKey,
+		APITimeout:    5 * time.Second,
+		VPNWaitTime:   time.Millisecond,
+		OutputFormat:  config.OutputFormatText,
+		DeployBackend: config.DeployBackendAPI,
+		VPNHelperOff:  true,
+	}
+	s := server.NewMCPServer("zerops-test", "test", server.WithToolCapabilities(false))
+	if err := tools.RegisterAllWithAPI(s, cfg, apiClient); err != nil {
+		t.Fatalf("failed to register tools: %v", err)
+	}
+
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("failed to start client: %v", err)
+	}
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(ctx, init); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// callText calls a tool and returns its text output
+func callText(t *testing.T, c *client.Client, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	res, err := c.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	var parts []string
+	for _, content := range res.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n"), res.IsError
+}
+
+// callJSON calls a tool with format=json and decodes the envelope
+func callJSON(t *testing.T, c *client.Client, name string, args map[string]interface{}) envelope {
+	t.Helper()
+
+	withFormat := map[string]interface{}{"format": "json"}
+	for k, v := range args {
+		withFormat[k] = v
+	}
+	text, _ := callText(t, c, name, withFormat)
+
+	var out envelope
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatalf("%s: output is not a JSON envelope: %v\n%s", name, err, text)
+	}
+	if out.Tool != name {
+		t.Fatalf("%s: envelope names tool %q", name, out.Tool)
+	}
+	return out
+}
+
+func TestRegisterAllWithAPIAddsSharedParameters(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	c := startTools(t, sim)
+
+	res, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]bool)
+	for _, tool := range res.Tools {
+		names[tool.Name] = true
+		for _, param := range []string{"format", "profile"} {
+			if _, ok := tool.InputSchema.Properties[param]; !ok {
+				t.Errorf("%s has no %s parameter", tool.Name, param)
+			}
+		}
+	}
+	for _, name := range []string{"auth_validate", "project_import", "service_logs", "deploy_push", "env_sync", "knowledge_log_rules"} {
+		if !names[name] {
+			t.Errorf("tool %s is not registered", name)
+		}
+	}
+}
+
+func TestRegisterAllWithAPIRejectsInvalidLogRules(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules: [unclosed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{OutputFormat: config.OutputFormatText, DeployBackend: config.DeployBackendAPI, LogRulePaths: []string{rules}}
+
+	err := tools.RegisterAllWithAPI(server.NewMCPServer("zerops-test", "test"), cfg, apitest.NewReplayer(nil))
+	if err == nil || !strings.Contains(err.Error(), "ZEROPS_MCP_LOG_RULES") {
+		t.Errorf("RegisterAllWithAPI error = %v, want an invalid ZEROPS_MCP_LOG_RULES error", err)
+	}
+}
+
+func TestAuthValidate(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "auth_validate", nil)
+	if !out.OK {
+		t.Fatalf("auth_validate failed: %+v", out.Error)
+	}
+	var data struct {
+		Email   string `json:"email"`
+		Clients int    `json:"clients"`
+	}
+	if err := json.Unmarshal(out.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Email != "dev@example.com" || data.Clients != 1 {
+		t.Errorf("unexpected user data: %+v", data)
+	}
+}
+
+func TestInvalidAPIKey(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	c := startTools(t, sim)
+	sim.APIKey = "rotated-key"
+
+	out := callJSON(t, c, "auth_validate", nil)
+	if out.OK || out.Error == nil || out.Error.Category != "AUTH" {
+		t.Fatalf("expected an auth error, got %+v", out)
+	}
+}
+
+func TestProjectCreateImportAndList(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	sim.ProcessPolls = 0
+	c := startTools(t, sim)
+
+	created := callJSON(t, c, "project_create", map[string]interface{}{"name": "demo", "region": apitest.DefaultRegion})
+	if !created.OK {
+		t.Fatalf("project_create failed: %+v", created.Error)
+	}
+	var project struct {
+		ProjectID string `json:"projectId"`
+	}
+	if err := json.Unmarshal(created.Data, &project); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sim.Project(project.ProjectID); !ok {
+		t.Fatalf("project %q was not created on the server", project.ProjectID)
+	}
+
+	yaml := "services:\n  - hostname: app\n    type: nodejs@20\n  - hostname: db\n    type: postgresql@16\n    mode: NON_HA\n"
+	imported := callJSON(t, c, "project_import", map[string]interface{}{"project_id": project.ProjectID, "yaml": yaml})
+	if !imported.OK {
+		t.Fatalf("project_import failed: %+v", imported.Error)
+	}
+
+	listed := callJSON(t, c, "service_list", map[string]interface{}{"project_id": project.ProjectID})
+	var services struct {
+		Total int `json:"total"`
+		Items []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(listed.Data, &services); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, item := range services.Items {
+		got[item.Name] = item.Type
+	}
+	if services.Total != 2 || got["app"] != "nodejs@20" || got["db"] != "postgresql@16" {
+		t.Errorf("unexpected services: %+v", services)
+	}
+}
+
+func TestProjectNotFound(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	c := startTools(t, sim)
+
+	out := callJSON(t, c, "project_info", map[string]interface{}{"project_id": "missing"})
+	if out.OK || out.Error == nil || out.Error.Code != "PROJECT_NOT_FOUND" {
+		t.Fatalf("expected PROJECT_NOT_FOUND, got %+v", out)
+	}
+}
+
+func TestInjectedServerErrorIsReported(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	project := sim.AddProject("demo")
+	sim.InjectError(apitest.InjectedError{
+		Method:     "GET",
+		Path:       "/api/rest/public/project/" + project.ID,
+		StatusCode: 400,
+		Code:       "projectLocked",
+		Message:    "Project is locked",
+	})
+	c := startTools(t, sim)
+
+	text, isError := callText(t, c, "project_info", map[string]interface{}{"project_id": project.ID})
+	if !isError || !strings.Contains(text, "projectLocked") {
+		t.Fatalf("expected the API error code in the output, got:\n%s", text)
+	}
+}
+
+func TestInvalidFormat(t *testing.T) {
+	sim := apitest.NewServer()
+	defer sim.Close()
+	c := startTools(t, sim)
+
+	text, isError := callText(t, c, "auth_validate", map[string]interface{}{"format": "xml"})
+	if !isError || !strings.Contains(text, "INVALID_FORMAT") {
+		t.Fatalf("expected INVALID_FORMAT, got:\n%s", text)
+	}
+}
@@ -2,9 +2,11 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 )
 
 // ParamValidator defines validation rules for a parameter
@@ -52,39 +54,71 @@ var CommonValidators = struct {
 func RequireParam(request mcp.CallToolRequest, validator ParamValidator) (string, *mcp.CallToolResult) {
 	value, err := request.RequireString(validator.ParamName)
 	if err != nil {
-		return "", ErrorResponse(validator.ErrorCode, validator.ErrorMsg, validator.Resolution)
+		return "", ToolErrorResponse(zerrors.NewValidationError(validator.ErrorCode, validator.ErrorMsg, validator.Resolution))
 	}
 	return value, nil
 }
 
-// GetClientID retrieves the client ID from the current user
-func GetClientID(ctx context.Context, client *api.Client) (string, *mcp.CallToolResult) {
+// orgOption adds the optional organization parameter to a tool
+func orgOption() mcp.ToolOption {
+	return mcp.WithString("client_id",
+		mcp.Description("Organization to use: client ID or account name (default: configured default organization, see 'org_list')"),
+	)
+}
+
+// GetClientID resolves the organization (client) ID for a call.
+// org may be a client ID or account name; when empty the default organization is used.
+func GetClientID(ctx context.Context, client api.ZeropsAPI, org string) (string, *mcp.CallToolResult) {
+	if org == "" {
+		clientID, err := client.GetClientID(ctx)
+		if err != nil {
+			return "", clientIDError(err)
+		}
+		return clientID, nil
+	}
+
 	user, err := client.GetCurrentUser(ctx)
 	if err != nil {
 		return "", HandleAPIError(err)
 	}
 
-	if len(user.ClientUserList) == 0 {
-		return "", ErrorResponse(
+	clientUser, err := user.FindClient(org)
+	if err != nil {
+		return "", clientIDError(err)
+	}
+	return clientUser.ClientID, nil
+}
+
+// clientIDError converts organization lookup failures
+func clientIDError(err error) *mcp.CallToolResult {
+	switch {
+	case errors.Is(err, api.ErrNoClient):
+		return ToolErrorResponse(zerrors.New(zerrors.CategoryAuth,
 			"NO_CLIENT",
 			"No client associations found for user",
 			"Contact Zerops support to resolve account issues",
-		)
+		))
+	case errors.Is(err, api.ErrUnknownOrg):
+		return ToolErrorResponse(zerrors.NewValidationError(
+			"UNKNOWN_ORG",
+			err.Error(),
+			"Use a client ID or account name from 'org_list'",
+		).WithNextTool("org_list"))
+	default:
+		return HandleAPIError(err)
 	}
-
-	return user.ClientUserList[0].ClientID, nil
 }
 
 // ValidateProjectAccess checks if a project exists and is accessible
-func ValidateProjectAccess(ctx context.Context, client *api.Client, projectID string) (*api.Project, *mcp.CallToolResult) {
+func ValidateProjectAccess(ctx context.Context, client api.ZeropsAPI, projectID string) (*api.Project, *mcp.CallToolResult) {
 	project, err := client.GetProject(ctx, projectID)
 	if err != nil {
 		if isNotFoundError(err) {
-			return nil, ErrorResponse(
+			return nil, ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"PROJECT_NOT_FOUND",
 				fmt.Sprintf("Project '%s' not found", projectID),
 				"Check the project ID or use 'project_list' to see available projects",
-			)
+			))
 		}
 		return nil, HandleAPIError(err)
 	}
@@ -92,15 +126,15 @@ func ValidateProjectAccess(ctx context.Context, client *api.Client, projectID st
 }
 
 // ValidateServiceAccess checks if a service exists and is accessible
-func ValidateServiceAccess(ctx context.Context, client *api.Client, projectID, serviceID string) (*api.ServiceDetails, *mcp.CallToolResult) {
+func ValidateServiceAccess(ctx context.Context, client api.ZeropsAPI, projectID, serviceID string) (*api.ServiceDetails, *mcp.CallToolResult) {
 	service, err := client.GetService(ctx, serviceID)
 	if err != nil {
 		if isNotFoundError(err) {
-			return nil, ErrorResponse(
+			return nil, ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SERVICE_NOT_FOUND",
 				fmt.Sprintf("Service '%s' not found in project", serviceID),
 				"Check the service ID or use 'service_list' to see available services",
-			)
+			))
 		}
 		return nil, HandleAPIError(err)
 	}
@@ -110,21 +144,21 @@ func ValidateServiceAccess(ctx context.Context, client *api.Client, projectID, s
 // ValidateServiceName checks if a service name is valid
 func ValidateServiceName(name string) *mcp.CallToolResult {
 	if len(name) < 3 || len(name) > 30 {
-		return ErrorResponse(
+		return ToolErrorResponse(zerrors.NewValidationError(
 			"INVALID_SERVICE_NAME",
 			"Service name must be 3-30 characters",
 			"Use a name between 3 and 30 characters",
-		)
+		))
 	}
 
 	// Check for valid characters (only lowercase letters and numbers)
 	for _, ch := range name {
 		if !((ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9')) {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SERVICE_NAME",
 				"Service name can only contain lowercase letters and numbers",
 				"Use only lowercase letters (a-z) and numbers (0-9) in the service name",
-			)
+			))
 		}
 	}
 
@@ -133,26 +167,5 @@ func ValidateServiceName(name string) *mcp.CallToolResult {
 
 // isNotFoundError checks if an error is a 404 not found
 func isNotFoundError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	return contains(errStr, "404") || contains(errStr, "not found")
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && len(substr) > 0 && 
-		(s == substr || (len(s) > len(substr) && containsAt(s, substr, 0)))
+	return zerrors.IsNotFound(err)
 }
-
-func containsAt(s, substr string, start int) bool {
-	if start+len(substr) > len(s) {
-		return false
-	}
-	for i := 0; i < len(substr); i++ {
-		if s[start+i] != substr[i] {
-			return false
-		}
-	}
-	return true
-}
\ No newline at end of file
@@ -8,11 +8,12 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/zeropsio/zerops-mcp-v3/internal/api"
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
 	"github.com/zeropsio/zerops-mcp-v3/internal/zcli"
 )
 
 // RegisterWorkflowTools registers all workflow tools
-func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper *zcli.ZCLIWrapper) {
+func RegisterWorkflowTools(s *server.MCPServer, client api.ZeropsAPI, zcliWrapper *zcli.ZCLIWrapper) {
 	// Register workflow_create_app tool
 	workflowCreateAppTool := mcp.NewTool(
 		"workflow_create_app",
@@ -38,43 +39,44 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		mcp.WithObject("env_vars",
 			mcp.Description("Environment variables for the application"),
 		),
+		orgOption(),
 	)
 
-	s.AddTool(workflowCreateAppTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, workflowCreateAppTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projectName, err := request.RequireString("project_name")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_NAME",
 				"Project name is required",
 				"Provide a valid project name",
-			), nil
+			)), nil
 		}
 
 		appType, err := request.RequireString("app_type")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_APP_TYPE",
 				"Application type is required",
 				"Choose from: nodejs, php-laravel, python-django, static-react, go, dotnet",
-			), nil
+			)), nil
 		}
 
 		appHostname, err := request.RequireString("app_hostname")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_APP_HOSTNAME",
 				"Application hostname is required",
 				"Provide a valid hostname (lowercase letters and numbers only)",
-			), nil
+			)), nil
 		}
 
 		// Validate hostname
 		if !isValidServiceName(appHostname) {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_HOSTNAME_FORMAT",
 				fmt.Sprintf("Hostname '%s' contains invalid characters", appHostname),
 				"Use only lowercase letters and numbers (e.g., 'app', 'api1')",
-			), nil
+			)), nil
 		}
 
 		region := request.GetString("region", "prg1")
@@ -100,6 +102,11 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 			}
 		}
 
+		clientID, errResult := GetClientID(ctx, client, request.GetString("client_id", ""))
+		if errResult != nil {
+			return errResult, nil
+		}
+
 		var response strings.Builder
 		response.WriteString(fmt.Sprintf("🚀 Creating %s application project '%s'\n\n", appType, projectName))
 
@@ -108,14 +115,14 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		project, err := client.CreateProject(ctx, api.CreateProjectRequest{
 			Name:     projectName,
 			RegionID: region,
+			ClientID: clientID,
 		})
 		if err != nil {
-			return ErrorResponseWithNext(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"PROJECT_CREATE_FAILED",
 				fmt.Sprintf("Failed to create project: %v", err),
 				"Check project name and try again",
-				"project_create",
-			), nil
+			).WithNextTool("project_create")), nil
 		}
 		response.WriteString(fmt.Sprintf("✅ Project created with ID: %s\n\n", project.ID))
 
@@ -171,12 +178,11 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		if err != nil {
 			// Try to delete the project to clean up
 			client.DeleteProject(ctx, project.ID) //nolint:errcheck
-			return ErrorResponseWithNext(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"IMPORT_FAILED",
 				fmt.Sprintf("Failed to create services: %v", err),
 				"The project was created but service import failed. Try manually with 'project_import'",
-				"project_import",
-			), nil
+			).WithNextTool("project_import")), nil
 		}
 		
 		// Count created services
@@ -244,25 +250,28 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		mcp.WithBoolean("include_data",
 			mcp.Description("Include data in cloned databases (default: false)"),
 		),
+		mcp.WithString("client_id",
+			mcp.Description("Organization for the new project: client ID or account name (default: same as source)"),
+		),
 	)
 
-	s.AddTool(workflowCloneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, workflowCloneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		sourceProjectID, err := request.RequireString("source_project_id")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_SOURCE_PROJECT",
 				"Source project ID is required",
 				"Provide a valid project ID to clone from",
-			), nil
+			)), nil
 		}
 
 		newProjectName, err := request.RequireString("new_project_name")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_PROJECT_NAME",
 				"New project name is required",
 				"Provide a name for the new project",
-			), nil
+			)), nil
 		}
 
 		includeData := request.GetBool("include_data", false)
@@ -270,11 +279,11 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		// Get source project details
 		sourceProject, err := client.GetProject(ctx, sourceProjectID)
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SOURCE_PROJECT_NOT_FOUND",
 				fmt.Sprintf("Failed to get source project: %v", err),
 				"Check the project ID and try again",
-			), nil
+			)), nil
 		}
 
 		region := request.GetString("region", "prg1") // Default to prg1 if not specified
@@ -282,11 +291,21 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		// Get services from source project
 		services, err := client.ListServices(ctx, sourceProjectID)
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SERVICES_FETCH_FAILED",
 				fmt.Sprintf("Failed to get source project services: %v", err),
 				"Check the project ID and permissions",
-			), nil
+			)), nil
+		}
+
+		// Clone into the source organization unless another one is given
+		clientID := sourceProject.ClientID
+		if org := request.GetString("client_id", ""); org != "" {
+			var errResult *mcp.CallToolResult
+			clientID, errResult = GetClientID(ctx, client, org)
+			if errResult != nil {
+				return errResult, nil
+			}
 		}
 
 		steps := []string{}
@@ -295,13 +314,14 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		newProject, err := client.CreateProject(ctx, api.CreateProjectRequest{
 			Name:     newProjectName,
 			RegionID: region,
+			ClientID: clientID,
 		})
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"PROJECT_CREATION_FAILED",
 				fmt.Sprintf("Failed to create new project: %v", err),
 				"Check the project name and try again",
-			), nil
+			)), nil
 		}
 		steps = append(steps, fmt.Sprintf("✓ Created new project '%s' (ID: %s)", newProjectName, newProject.ID))
 
@@ -358,11 +378,11 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		if err != nil {
 			// Cleanup: delete the new project
 			client.DeleteProject(ctx, newProject.ID)
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.New(zerrors.CategoryAPI,
 				"SERVICE_IMPORT_FAILED",
 				fmt.Sprintf("Failed to import services: %v", err),
 				"Service configuration may be incompatible",
-			), nil
+			)), nil
 		}
 		// Count actual services (excluding system ones)
 		serviceCount := 0
@@ -376,7 +396,7 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		// Prepare notes about what wasn't cloned
 		var notes strings.Builder
 		notes.WriteString("\nImportant notes:\n")
-		notes.WriteString("- Environment variables were NOT cloned (set them manually)\n")
+		notes.WriteString("- Environment variables were NOT cloned (set them with 'env_set')\n")
 		notes.WriteString("- Service data was NOT cloned")
 		if includeData {
 			notes.WriteString(" (data cloning not yet implemented)\n")
@@ -418,14 +438,14 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 		),
 	)
 
-	s.AddTool(workflowDiagnoseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addTool(s, workflowDiagnoseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		issueType, err := request.RequireString("issue_type")
 		if err != nil {
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"INVALID_ISSUE_TYPE",
 				"Issue type is required",
 				"Choose from: deployment, service, vpn, connection, performance",
-			), nil
+			)), nil
 		}
 
 		projectID := request.GetString("project_id", "")
@@ -547,9 +567,9 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 			response.WriteString("      - macOS: brew install zeropsio/tap/zcli\n")
 			response.WriteString("      - Linux: See Zerops documentation\n\n")
 			
-			response.WriteString("   b) Check sudo permissions:\n")
-			response.WriteString("      - VPN operations require sudo\n")
-			response.WriteString("      - You may need to enter your password\n\n")
+			response.WriteString("   b) Check root privileges:\n")
+			response.WriteString("      - VPN operations run as root and the server cannot prompt for a password\n")
+			response.WriteString("      - Run zerops-vpn-helper once, or allow passwordless sudo for zcli\n\n")
 			
 			response.WriteString("   c) Common VPN errors:\n")
 			response.WriteString("      - 'Already connected': Disconnect first with vpn_disconnect\n")
@@ -615,11 +635,11 @@ func RegisterWorkflowTools(s *server.MCPServer, client *api.Client, zcliWrapper
 			response.WriteString("   - Implement application-level caching\n")
 
 		default:
-			return ErrorResponse(
+			return ToolErrorResponse(zerrors.NewValidationError(
 				"UNKNOWN_ISSUE_TYPE",
 				fmt.Sprintf("Unknown issue type: %s", issueType),
 				"Choose from: deployment, service, vpn, connection, performance",
-			), nil
+			)), nil
 		}
 
 		response.WriteString("\n\n💡 Additional resources:\n")
@@ -0,0 +1,169 @@
+// Package vpnhelper lets the MCP server bring the Zerops VPN up and down without
+// interactive sudo. A small helper daemon, installed once with root privileges,
+// listens on a Unix socket and runs zcli's VPN commands on the server's behalf.
+package vpnhelper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// DefaultSocketPath is where the helper listens unless configured otherwise
+const DefaultSocketPath = "/var/run/zerops-vpn-helper.sock"
+
+// Commands understood by the helper
+const (
+	CommandUp     = "up"
+	CommandDown   = "down"
+	CommandStatus = "status"
+)
+
+// projectIDPattern restricts project IDs to what Zerops issues, so nothing else
+// reaches the privileged zcli command line, not even something that looks like a flag
+var projectIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// Request is a single command sent to the helper
+type Request struct {
+	Command   string `json:"command"`
+	ProjectID string `json:"projectId,omitempty"`
+}
+
+// Response is the helper's reply to a request
+type Response struct {
+	OK        bool   `json:"ok"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ExitCode  int    `json:"exitCode,omitempty"`  // exit code of a failed zcli command
+	ProjectID string `json:"projectId,omitempty"` // project the helper last brought up
+	Version   string `json:"version,omitempty"`
+}
+
+// Validate checks that a request is one the helper will run
+func (r Request) Validate() error {
+	switch r.Command {
+	case CommandUp:
+		if !projectIDPattern.MatchString(r.ProjectID) {
+			return fmt.Errorf("invalid project ID %q", r.ProjectID)
+		}
+	case CommandDown, CommandStatus:
+		if r.ProjectID != "" {
+			return fmt.Errorf("%s takes no project ID", r.Command)
+		}
+	default:
+		return fmt.Errorf("unknown command %q", r.Command)
+	}
+	return nil
+}
+
+// Client talks to the helper over its socket
+type Client struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+// NewClient creates a client for the helper listening on socketPath
+func NewClient(socketPath string) *Client {
+	return &Client{
+		socketPath: socketPath,
+		timeout:    2 * time.Minute, // vpn up waits for the tunnel
+	}
+}
+
+// SocketPath returns the socket the client connects to
+func (c *Client) SocketPath() string {
+	return c.socketPath
+}
+
+// Available reports whether a helper is listening and answering
+func (c *Client) Available(ctx context.Context) bool {
+	info, err := os.Stat(c.socketPath)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err = c.Status(ctx)
+	return err == nil
+}
+
+// Up brings the VPN up for a project
+func (c *Client) Up(ctx context.Context, projectID string) (string, error) {
+	resp, err := c.call(ctx, Request{Command: CommandUp, ProjectID: projectID})
+	if err != nil {
+		return "", zcliError(resp, err, "vpn", "up", "--projectId", projectID)
+	}
+	return resp.Output, nil
+}
+
+// Down brings the VPN down
+func (c *Client) Down(ctx context.Context) (string, error) {
+	resp, err := c.call(ctx, Request{Command: CommandDown})
+	if err != nil {
+		return "", zcliError(resp, err, "vpn", "down")
+	}
+	return resp.Output, nil
+}
+
+// Status asks the helper which project it last brought up
+func (c *Client) Status(ctx context.Context) (*Response, error) {
+	return c.call(ctx, Request{Command: CommandStatus})
+}
+
+// call sends a request and waits for the response
+func (c *Client) call(ctx context.Context, req Request) (*Response, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("VPN helper is not reachable at %s: %w", c.socketPath, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request to VPN helper: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read VPN helper response: %w", err)
+	}
+	if !resp.OK {
+		return &resp, fmt.Errorf("VPN helper: %s\nOutput: %s", resp.Error, resp.Output)
+	}
+	return &resp, nil
+}
+
+// zcliError reports a zcli command the helper ran and that failed like one the
+// server ran itself, so both are handled the same way
+func zcliError(resp *Response, err error, args ...string) error {
+	if resp == nil || resp.ExitCode == 0 {
+		return err
+	}
+	return &zerrors.ZCLIError{
+		Command:  strings.Join(args[:2], " "),
+		Args:     args,
+		Err:      err,
+		Stdout:   resp.Output,
+		ExitCode: resp.ExitCode,
+	}
+}
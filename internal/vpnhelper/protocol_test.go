@@ -0,0 +1,40 @@
+package vpnhelper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr string
+	}{
+		{"up", Request{Command: CommandUp, ProjectID: "abc_DEF-123"}, ""},
+		{"down", Request{Command: CommandDown}, ""},
+		{"status", Request{Command: CommandStatus}, ""},
+		{"up without project", Request{Command: CommandUp}, "invalid project ID"},
+		{"up with a flag", Request{Command: CommandUp, ProjectID: "--help"}, "invalid project ID"},
+		{"up with spaces", Request{Command: CommandUp, ProjectID: "abc; rm -rf /"}, "invalid project ID"},
+		{"up with long project", Request{Command: CommandUp, ProjectID: strings.Repeat("a", 65)}, "invalid project ID"},
+		{"down with project", Request{Command: CommandDown, ProjectID: "abc"}, "takes no project ID"},
+		{"status with project", Request{Command: CommandStatus, ProjectID: "abc"}, "takes no project ID"},
+		{"unknown command", Request{Command: "exec"}, "unknown command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package vpnhelper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server runs VPN commands for clients connecting to the helper socket. It only
+// ever runs 'zcli vpn up --projectId <id>' and 'zcli vpn down'.
+type Server struct {
+	ZCLI    string        // zcli binary; defaults to "zcli" from PATH
+	Timeout time.Duration // limit for a single zcli command
+	Version string
+	Logger  *log.Logger
+
+	mu        sync.Mutex // one VPN transition at a time
+	stateMu   sync.Mutex // guards projectID, so status never waits for a transition
+	projectID string
+}
+
+// Serve answers requests on l until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { l.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle answers the single request sent on a connection
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	var req Request
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		resp.Error = "invalid request: " + err.Error()
+	} else if err := req.Validate(); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp = s.execute(ctx, req)
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// execute runs a validated request
+func (s *Server) execute(ctx context.Context, req Request) Response {
+	if req.Command == CommandStatus {
+		return Response{OK: true, ProjectID: s.currentProject(), Version: s.Version}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	args := []string{"vpn", "down"}
+	if req.Command == CommandUp {
+		args = []string{"vpn", "up", "--projectId", req.ProjectID}
+	}
+	s.logf("running zcli %s", strings.Join(args, " "))
+	output, err := s.run(ctx, args)
+	if err != nil {
+		s.logf("zcli %s failed: %v", args[1], err)
+		resp := Response{Error: err.Error(), Output: output, ProjectID: s.currentProject(), ExitCode: -1}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			resp.ExitCode = exitErr.ExitCode()
+		}
+		return resp
+	}
+
+	projectID := ""
+	if req.Command == CommandUp {
+		projectID = req.ProjectID
+	}
+	s.stateMu.Lock()
+	s.projectID = projectID
+	s.stateMu.Unlock()
+	return Response{OK: true, Output: output, ProjectID: projectID, Version: s.Version}
+}
+
+// currentProject returns the project the helper last brought up
+func (s *Server) currentProject() string {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return s.projectID
+}
+
+// run executes zcli with the given arguments
+func (s *Server) run(ctx context.Context, args []string) (string, error) {
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 2 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	zcli := s.ZCLI
+	if zcli == "" {
+		zcli = "zcli"
+	}
+	out, err := exec.CommandContext(ctx, zcli, args...).CombinedOutput()
+	return string(out), err
+}
+
+// logf logs through the configured logger, if any
+func (s *Server) logf(format string, args ...any) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	}
+}
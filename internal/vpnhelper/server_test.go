@@ -0,0 +1,96 @@
+package vpnhelper
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startServer serves s on a socket in a temporary directory, with a fake zcli
+// that runs script
+func startServer(t *testing.T, s *Server, script string) *Client {
+	t.Helper()
+	dir := t.TempDir()
+	s.ZCLI = filepath.Join(dir, "zcli")
+	if err := os.WriteFile(s.ZCLI, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	socket := filepath.Join(dir, "helper.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.Serve(ctx, l)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return NewClient(socket)
+}
+
+func TestServerUpAndDown(t *testing.T) {
+	c := startServer(t, &Server{Version: "test"}, `echo "zcli $*"`)
+	ctx := context.Background()
+
+	output, err := c.Up(ctx, "project-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "zcli vpn up --projectId project-1\n" {
+		t.Errorf("output = %q", output)
+	}
+	status, err := c.Status(ctx)
+	if err != nil || status.ProjectID != "project-1" || status.Version != "test" {
+		t.Errorf("status after up = %+v, %v", status, err)
+	}
+
+	if _, err := c.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := c.Status(ctx); err != nil || status.ProjectID != "" {
+		t.Errorf("status after down = %+v, %v", status, err)
+	}
+}
+
+func TestServerReportsZCLIExitCode(t *testing.T) {
+	c := startServer(t, &Server{}, "echo 'not logged in'; exit 3")
+
+	if _, err := c.Up(context.Background(), "project-1"); err == nil {
+		t.Fatal("Up succeeded although zcli failed")
+	}
+	resp, err := c.call(context.Background(), Request{Command: CommandDown})
+	if err == nil || resp.ExitCode != 3 || resp.Output != "not logged in\n" {
+		t.Errorf("response = %+v, %v; want exit code 3 with the zcli output", resp, err)
+	}
+}
+
+func TestServerStatusDuringTransition(t *testing.T) {
+	c := startServer(t, &Server{}, "sleep 2")
+
+	upDone := make(chan error, 1)
+	go func() {
+		_, err := c.Up(context.Background(), "project-1")
+		upDone <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	if !c.Available(context.Background()) {
+		t.Error("helper not available while vpn up runs")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("status took %v while vpn up ran", elapsed)
+	}
+	if err := <-upDone; err != nil {
+		t.Errorf("Up: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package zcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables zcli reads the location of its state and the token to
+// log in with from
+const (
+	dataFileEnv = "ZEROPS_CLI_DATA_FILE_PATH"
+	logFileEnv  = "ZEROPS_CLI_LOG_FILE_PATH"
+	tokenEnv    = "ZEROPS_TOKEN"
+)
+
+// Account is the Zerops login zcli uses for a profile
+type Account struct {
+	Profile string
+	Token   string
+	APIURL  string
+}
+
+// LoginState describes the zcli login of the profile a call runs under
+type LoginState struct {
+	Profile   string `json:"profile,omitempty"`
+	ConfigDir string `json:"configDir,omitempty"`
+	Isolated  bool   `json:"isolated"`  // zcli runs with a config directory of its own
+	Mismatch  bool   `json:"mismatch"`  // zcli was logged in with another token
+	Refreshed bool   `json:"refreshed"` // 'zcli login' ran for this call
+}
+
+// Message describes the login state in one sentence
+func (l LoginState) Message() string {
+	switch {
+	case !l.Isolated:
+		return "zcli uses its own login, which may belong to a different account than the server's API key"
+	case l.Mismatch:
+		return fmt.Sprintf("zcli was logged in with a different token; logged in again with the API key of profile %s", l.Profile)
+	case l.Refreshed:
+		return fmt.Sprintf("Logged zcli in with the API key of profile %s", l.Profile)
+	default:
+		return fmt.Sprintf("zcli is logged in with the API key of profile %s", l.Profile)
+	}
+}
+
+// SetAccounts makes zcli run with a separate config directory per profile, logged
+// in with that profile's API key. current returns the profile of a call.
+func (z *ZCLIWrapper) SetAccounts(accounts []Account, current func(ctx context.Context) string) {
+	z.accounts = make(map[string]Account, len(accounts))
+	for _, account := range accounts {
+		z.accounts[account.Profile] = account
+	}
+	z.currentProfile = current
+}
+
+// account returns the account of the profile a call runs under, if configured
+func (z *ZCLIWrapper) account(ctx context.Context) (Account, bool) {
+	if z.currentProfile == nil {
+		return Account{}, false
+	}
+	account, ok := z.accounts[z.currentProfile(ctx)]
+	return account, ok && account.Token != ""
+}
+
+// ConfigDir returns the zcli config directory of a profile
+func ConfigDir(profile string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "zerops-mcp", "zcli", url.PathEscape(profile))
+}
+
+// command builds a zcli command that uses the config directory of the call's profile
+func (z *ZCLIWrapper) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "zcli", args...)
+	if account, ok := z.account(ctx); ok {
+		dir := ConfigDir(account.Profile)
+		cmd.Env = append(os.Environ(),
+			dataFileEnv+"="+filepath.Join(dir, "cli.data"),
+			logFileEnv+"="+filepath.Join(dir, "zerops.log"),
+		)
+	}
+	return cmd
+}
+
+// EnsureLogin makes sure zcli is logged in with the API key of the call's profile,
+// running 'zcli login' when it is not logged in or uses another token. Without
+// configured accounts zcli keeps the user's own login.
+func (z *ZCLIWrapper) EnsureLogin(ctx context.Context) (LoginState, error) {
+	account, ok := z.account(ctx)
+	if !ok {
+		return LoginState{}, nil
+	}
+
+	dir := ConfigDir(account.Profile)
+	state := LoginState{Profile: account.Profile, ConfigDir: dir, Isolated: true}
+
+	z.loginMu.Lock()
+	defer z.loginMu.Unlock()
+
+	stored := storedToken(filepath.Join(dir, "cli.data"))
+	if stored == account.Token {
+		return state, nil
+	}
+	state.Mismatch = stored != ""
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return state, fmt.Errorf("failed to create zcli config directory: %w", err)
+	}
+	// The token goes through the environment, so it never shows in the process list
+	args := []string{"login"}
+	if account.APIURL != "" {
+		args = append(args, "--regionUrl", strings.TrimRight(account.APIURL, "/")+"/api/rest/public/region/zcli")
+	}
+	ctx, cancel := z.withTimeout(ctx, args)
+	defer cancel()
+	cmd := z.command(ctx, args...)
+	cmd.Env = append(cmd.Env, tokenEnv+"="+account.Token)
+	output, err := z.runCommand(ctx, cmd, args)
+	if err != nil {
+		return state, fmt.Errorf("zcli login failed: %w\nOutput: %s", err, strings.ReplaceAll(output, account.Token, "***"))
+	}
+	state.Refreshed = true
+	return state, nil
+}
+
+// storedToken reads the token zcli saved in its data file
+func storedToken(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var stored struct {
+		Token string
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return ""
+	}
+	return stored.Token
+}
+
+// VPNLogin describes which zcli login VPN commands run with under a privilege
+// from VPNPrivilege. The helper and sudo run zcli as root with root's own login;
+// only a server running as root itself applies the profile's login.
+func (z *ZCLIWrapper) VPNLogin(ctx context.Context, privilege string) string {
+	account, isolated := z.account(ctx)
+	switch privilege {
+	case PrivilegeHelper, PrivilegeSudo:
+		if isolated {
+			return fmt.Sprintf("VPN commands use root's zcli login, not the API key of profile %s; if they fail with an authorization error, run 'sudo zcli login <token>' with that key", account.Profile)
+		}
+		return "VPN commands use root's zcli login; if they fail with an authorization error, run 'sudo zcli login <token>'"
+	case PrivilegeRoot:
+		if isolated {
+			return fmt.Sprintf("VPN commands use the API key of profile %s", account.Profile)
+		}
+		return "VPN commands use root's zcli login"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,95 @@
+package zcli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeZCLI puts a zcli on PATH that records its arguments and the token it got,
+// and saves that token as its login
+func fakeZCLI(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	record := filepath.Join(dir, "record")
+	script := "#!/bin/sh\n" +
+		"echo \"$* token=$ZEROPS_TOKEN\" >> " + record + "\n" +
+		"printf '{\"Token\":\"%s\"}' \"$ZEROPS_TOKEN\" > \"$ZEROPS_CLI_DATA_FILE_PATH\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "zcli"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	return record
+}
+
+// wrapperWithAccount returns a wrapper whose calls run under the given account
+func wrapperWithAccount(account Account) *ZCLIWrapper {
+	z := New(false)
+	z.SetAccounts([]Account{account}, func(context.Context) string { return account.Profile })
+	return z
+}
+
+func TestEnsureLogin(t *testing.T) {
+	record := fakeZCLI(t)
+	z := wrapperWithAccount(Account{Profile: "prod", Token: "secret-token", APIURL: "https://api.example.com/"})
+	ctx := context.Background()
+
+	state, err := z.EnsureLogin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Isolated || !state.Refreshed || state.Mismatch {
+		t.Errorf("first login state = %+v, want a fresh isolated login", state)
+	}
+
+	calls, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "login --regionUrl https://api.example.com/api/rest/public/region/zcli token=secret-token\n"
+	if string(calls) != want {
+		t.Errorf("zcli calls = %q, want %q", calls, want)
+	}
+
+	if state, err := z.EnsureLogin(ctx); err != nil || state.Refreshed {
+		t.Errorf("second login state = %+v, %v; want the stored login reused", state, err)
+	}
+
+	z = wrapperWithAccount(Account{Profile: "prod", Token: "other-token"})
+	if state, err := z.EnsureLogin(ctx); err != nil || !state.Mismatch || !state.Refreshed {
+		t.Errorf("login with another token = %+v, %v; want a mismatch and a new login", state, err)
+	}
+}
+
+func TestEnsureLoginWithoutAccounts(t *testing.T) {
+	record := fakeZCLI(t)
+
+	state, err := New(false).EnsureLogin(context.Background())
+	if err != nil || state.Isolated {
+		t.Errorf("state = %+v, %v; want the user's own login", state, err)
+	}
+	if _, err := os.Stat(record); !os.IsNotExist(err) {
+		t.Error("zcli login ran without configured accounts")
+	}
+}
+
+func TestVPNLogin(t *testing.T) {
+	ctx := context.Background()
+	isolated := wrapperWithAccount(Account{Profile: "prod", Token: "secret-token"})
+
+	if got := isolated.VPNLogin(ctx, PrivilegeHelper); !strings.Contains(got, "root's zcli login") || !strings.Contains(got, "prod") {
+		t.Errorf("helper login = %q, want root's login named against profile prod", got)
+	}
+	if got := isolated.VPNLogin(ctx, PrivilegeRoot); strings.Contains(got, "root's") {
+		t.Errorf("root login = %q, want the profile's login", got)
+	}
+	if got := New(false).VPNLogin(ctx, PrivilegeSudo); !strings.Contains(got, "root's zcli login") {
+		t.Errorf("sudo login = %q, want root's login", got)
+	}
+	if got := isolated.VPNLogin(ctx, PrivilegeNone); got != "" {
+		t.Errorf("login without privilege = %q, want none", got)
+	}
+}
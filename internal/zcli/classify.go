@@ -0,0 +1,173 @@
+package zcli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// Failure codes assigned by Classify
+const (
+	FailureTimeout          = "ZCLI_TIMEOUT"
+	FailureCancelled        = "ZCLI_CANCELLED"
+	FailureSudoRequired     = "SUDO_REQUIRED"
+	FailureServiceSelection = "SERVICE_SELECTION_REQUIRED"
+	FailureGit              = "GIT_ERROR"
+	FailureLogStreaming     = "LOG_STREAMING_ERROR"
+	FailureProjectNotReady  = "PROJECT_NOT_READY"
+	FailureProjectNotFound  = "PROJECT_NOT_ACCESSIBLE"
+	FailureUnauthorized     = "ZCLI_UNAUTHORIZED"
+	FailureVPNConnected     = "VPN_ALREADY_CONNECTED"
+	FailureConnection       = "CONNECTION_ERROR"
+	FailureConfig           = "CONFIG_ERROR"
+	FailureServiceNotFound  = "SERVICE_NOT_FOUND"
+)
+
+// Failure is a recognized way a zcli command fails, with the advice tools give for it
+type Failure struct {
+	Code       string
+	Category   zerrors.Category
+	Message    string
+	Resolution string
+	NextTool   string
+}
+
+// failureRules recognizes zcli failures. Rules are tried in order and the first
+// match wins, so more specific rules come first.
+var failureRules = []struct {
+	failure Failure
+	match   func(e *zerrors.ZCLIError) bool
+}{
+	{
+		Failure{FailureTimeout, zerrors.CategoryTimeout, "zcli timed out",
+			"The command took longer than its timeout. Check the network and the VPN, or raise the timeout with ZEROPS_MCP_ZCLI_TIMEOUTS", "vpn_status"},
+		(*zerrors.ZCLIError).IsTimeout,
+	},
+	{
+		Failure{FailureCancelled, zerrors.CategoryDeployment, "zcli was cancelled",
+			"The tool call was cancelled and zcli was stopped", ""},
+		(*zerrors.ZCLIError).IsCancelled,
+	},
+	{
+		Failure{FailureSudoRequired, zerrors.CategoryVPN, "zcli needs root privileges",
+			"Configure passwordless sudo for zcli or start zerops-vpn-helper; see 'vpn_status' for the detected privileges", "vpn_status"},
+		(*zerrors.ZCLIError).IsSudoRequired,
+	},
+	{
+		Failure{FailureServiceSelection, zerrors.CategoryValidation, "Service name must be specified for deployment",
+			"Pass service_name with the hostname of the service to deploy. Use 'service_list' to see the services of the project", "service_list"},
+		pushOnly(outputContains("please, select a service", "interactive selection can be used only in terminal mode")),
+	},
+	{
+		Failure{FailureGit, zerrors.CategoryDeployment, "Git repository error",
+			"Ensure your git repository has at least one commit:\n1. git add .\n2. git commit -m \"Initial commit\"\n\nNote: zcli requires a git repository with commits, not just 'git init'", ""},
+		pushOnly(func(e *zerrors.ZCLIError) bool {
+			return e.ExitCode == 128 || outputContains("exit status 128", "not a git repository", "does not have any commits")(e)
+		}),
+	},
+	{
+		Failure{FailureLogStreaming, zerrors.CategoryDeployment, "Log streaming error during deployment",
+			"This is usually a temporary issue with log streaming. The deployment may still succeed.\nCheck deployment status with 'deploy_status' tool or check service logs", "deploy_status"},
+		pushOnly(outputContains("websocket: bad handshake")),
+	},
+	{
+		Failure{FailureProjectNotReady, zerrors.CategoryDeployment, "Project not ready for deployment",
+			"The project might be initializing or marked for deletion. Wait a moment and try again.\nEnsure services are fully initialized after import (wait 30+ seconds)", "project_info"},
+		outputContains("projectwillbedeleted", "no action allowed"),
+	},
+	{
+		Failure{FailureUnauthorized, zerrors.CategoryAuth, "zcli is not authorized",
+			"zcli's login was rejected. Check the API key with 'auth_validate'", "auth_validate"},
+		outputContains("unauthorized", "invalid token", "authorization failed"),
+	},
+	{
+		Failure{FailureProjectNotFound, zerrors.CategoryDeployment, "zcli cannot find the project",
+			"Check that the project belongs to the account of the API key with 'project_list'", "project_list"},
+		outputContains("project not found", "projectnotfound"),
+	},
+	{
+		Failure{FailureServiceNotFound, zerrors.CategoryDeployment, "zcli cannot find the service",
+			"Ensure the setup name in zerops.yml matches an existing service hostname. Use 'service_list' to see them", "service_list"},
+		outputContains("service stack not found", "servicestacknotfound", "service not found"),
+	},
+	{
+		Failure{FailureVPNConnected, zerrors.CategoryVPN, "VPN is already connected",
+			"Use 'vpn_disconnect' first to disconnect", "vpn_disconnect"},
+		outputContains("already connected"),
+	},
+	{
+		Failure{FailureConnection, zerrors.CategoryVPN, "Connection error",
+			"Ensure the VPN is connected and stable", "vpn_status"},
+		outputContains("connection refused", "no route to host", "i/o timeout", "connection reset"),
+	},
+	{
+		Failure{FailureConfig, zerrors.CategoryDeployment, "Configuration error",
+			"Check your zerops.yml file for syntax errors or missing required fields", "knowledge_validate_config"},
+		pushOnly(outputContains("zerops.yml", "zerops.yaml", "yaml:")),
+	},
+}
+
+// pushCommand is the zcli command that deploys; only its failures concern the
+// git repository, zerops.yml or the service to deploy
+const pushCommand = "service push"
+
+// pushOnly restricts a rule to failures of 'zcli service push'
+func pushOnly(match func(e *zerrors.ZCLIError) bool) func(e *zerrors.ZCLIError) bool {
+	return func(e *zerrors.ZCLIError) bool {
+		return e.Command == pushCommand && match(e)
+	}
+}
+
+// outputContains matches failures whose output contains any of the phrases, ignoring case
+func outputContains(phrases ...string) func(e *zerrors.ZCLIError) bool {
+	return func(e *zerrors.ZCLIError) bool {
+		output := strings.ToLower(e.Output())
+		for _, phrase := range phrases {
+			if strings.Contains(output, phrase) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Classify recognizes why a zcli command failed. ok is false when err is not a
+// zcli failure or matches no known failure.
+func Classify(err error) (Failure, bool) {
+	var zerr *zerrors.ZCLIError
+	if !errors.As(err, &zerr) {
+		return Failure{}, false
+	}
+	for _, rule := range failureRules {
+		if rule.match(zerr) {
+			return rule.failure, true
+		}
+	}
+	return Failure{}, false
+}
+
+// ToolError turns a classified failure into a tool error carrying the command's details
+func (f Failure) ToolError(err error) *zerrors.ToolError {
+	var zerr *zerrors.ZCLIError
+	hasDetails := errors.As(err, &zerr)
+
+	message := f.Message
+	if hasDetails && (zerr.IsTimeout() || zerr.IsCancelled()) {
+		message = zerr.Error()
+	}
+	toolErr := zerrors.New(f.Category, f.Code, message, f.Resolution)
+	if f.NextTool != "" {
+		toolErr.WithNextTool(f.NextTool)
+	}
+	if hasDetails {
+		toolErr.WithMetadata("command", fmt.Sprintf("zcli %s", zerr.Command))
+		toolErr.WithMetadata("exit_code", zerr.ExitCode)
+		toolErr.WithMetadata("duration", zerr.Duration.String())
+		if zerr.Stderr != "" {
+			toolErr.WithMetadata("stderr", zerr.Stderr)
+		}
+	}
+	return toolErr
+}
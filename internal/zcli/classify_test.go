@@ -0,0 +1,79 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+func TestClassify(t *testing.T) {
+	exit := errors.New("exit status 1")
+
+	tests := []struct {
+		name string
+		err  *zerrors.ZCLIError
+		want string
+	}{
+		{"timeout", &zerrors.ZCLIError{Command: "vpn up", Err: context.DeadlineExceeded}, FailureTimeout},
+		{"cancelled", &zerrors.ZCLIError{Command: "service push", Err: errors.Join(context.Canceled, exit)}, FailureCancelled},
+		{"sudo", &zerrors.ZCLIError{Command: "vpn up", Err: exit, Stderr: "sudo: a password is required"}, FailureSudoRequired},
+		{"service selection", &zerrors.ZCLIError{Command: "service push", Err: exit, Stdout: "Please, select a service"}, FailureServiceSelection},
+		{"git exit code", &zerrors.ZCLIError{Command: "service push", Err: exit, ExitCode: 128}, FailureGit},
+		{"git output", &zerrors.ZCLIError{Command: "service push", Err: exit, Stderr: "fatal: not a git repository"}, FailureGit},
+		{"unauthorized", &zerrors.ZCLIError{Command: "vpn up", Err: exit, Stderr: "Error: unauthorized"}, FailureUnauthorized},
+		{"project not found", &zerrors.ZCLIError{Command: "vpn up", Err: exit, Stdout: "projectNotFound"}, FailureProjectNotFound},
+		{"already connected", &zerrors.ZCLIError{Command: "vpn up", Err: exit, Stdout: "VPN is already connected"}, FailureVPNConnected},
+		{"connection", &zerrors.ZCLIError{Command: "service push", Err: exit, Stderr: "dial tcp: connection refused"}, FailureConnection},
+		{"config", &zerrors.ZCLIError{Command: "service push", Err: exit, Stderr: "yaml: line 3: mapping values are not allowed"}, FailureConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure, ok := Classify(fmt.Errorf("failed: %w", tt.err))
+			if !ok || failure.Code != tt.want {
+				t.Errorf("Classify = %q, %v; want %q", failure.Code, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyAppliesDeployRulesOnlyToPush(t *testing.T) {
+	exit := errors.New("exit status 128")
+
+	tests := []*zerrors.ZCLIError{
+		{Command: "vpn up", Err: exit, ExitCode: 128},
+		{Command: "vpn up", Err: exit, Stderr: "failed to read zerops.yml"},
+		{Command: "vpn down", Err: exit, Stdout: "please, select a service"},
+	}
+
+	for _, zerr := range tests {
+		if failure, ok := Classify(zerr); ok {
+			t.Errorf("zcli %s failing with %q classified as %s, want it left unclassified", zerr.Command, zerr.Output(), failure.Code)
+		}
+	}
+}
+
+func TestClassifyUnknownFailures(t *testing.T) {
+	if _, ok := Classify(errors.New("plain error")); ok {
+		t.Error("an error that is not a zcli failure was classified")
+	}
+	if _, ok := Classify(&zerrors.ZCLIError{Command: "vpn up", Err: errors.New("exit status 2"), Stdout: "something odd"}); ok {
+		t.Error("an unrecognized zcli failure was classified")
+	}
+}
+
+func TestFailureToolError(t *testing.T) {
+	zerr := &zerrors.ZCLIError{Command: "service push", Err: errors.New("exit status 128"), ExitCode: 128, Stderr: "fatal: not a git repository"}
+	failure, _ := Classify(zerr)
+
+	toolErr := failure.ToolError(zerr)
+	if toolErr.Code != FailureGit || toolErr.Message != failure.Message {
+		t.Errorf("tool error = %s %q, want %s %q", toolErr.Code, toolErr.Message, FailureGit, failure.Message)
+	}
+	if toolErr.Metadata["command"] != "zcli service push" || toolErr.Metadata["exit_code"] != 128 || toolErr.Metadata["stderr"] != zerr.Stderr {
+		t.Errorf("metadata = %v", toolErr.Metadata)
+	}
+}
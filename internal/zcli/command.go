@@ -0,0 +1,98 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+
+	zerrors "github.com/zeropsio/zerops-mcp-v3/internal/errors"
+)
+
+// DefaultTimeouts limits how long zcli commands may run, by command name
+var DefaultTimeouts = map[string]time.Duration{
+	"service push": 30 * time.Minute,
+	"vpn up":       2 * time.Minute,
+	"vpn down":     time.Minute,
+	"login":        time.Minute,
+	"version":      15 * time.Second,
+}
+
+// fallbackTimeout applies to commands without a timeout of their own
+const fallbackTimeout = 10 * time.Minute
+
+// commandGroups are zcli commands that take a subcommand
+var commandGroups = map[string]bool{
+	"service": true,
+	"project": true,
+	"vpn":     true,
+}
+
+// SetTimeouts overrides the timeouts of zcli commands, keyed by command name
+// such as "service push" or "vpn up"
+func (z *ZCLIWrapper) SetTimeouts(timeouts map[string]time.Duration) {
+	z.timeouts = timeouts
+}
+
+// Timeout returns how long a zcli command may run
+func (z *ZCLIWrapper) Timeout(command string) time.Duration {
+	if d, ok := z.timeouts[command]; ok && d > 0 {
+		return d
+	}
+	if d, ok := DefaultTimeouts[command]; ok {
+		return d
+	}
+	return fallbackTimeout
+}
+
+// withTimeout limits ctx to the timeout of the command given by args
+func (z *ZCLIWrapper) withTimeout(ctx context.Context, args []string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, z.Timeout(commandName(args)))
+}
+
+// commandName returns the zcli command of an argument list, e.g. "service push"
+func commandName(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	if commandGroups[args[0]] && len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+		return args[0] + " " + args[1]
+	}
+	return args[0]
+}
+
+// redactArgs hides a token passed to 'zcli login' on the command line. The
+// server passes its own through the environment, but callers of Execute may not.
+func redactArgs(args []string) []string {
+	redacted := append([]string(nil), args...)
+	for i, arg := range redacted {
+		if arg == "login" && i+1 < len(redacted) && !strings.HasPrefix(redacted[i+1], "-") {
+			redacted[i+1] = "***"
+			break
+		}
+	}
+	return redacted
+}
+
+// commandError describes a failed zcli command. When ctx ended first, the
+// error wraps its cause so timeouts and cancellations can be told apart.
+func commandError(ctx context.Context, args []string, err error, stdout, stderr string, duration time.Duration) *zerrors.ZCLIError {
+	zerr := &zerrors.ZCLIError{
+		Command:  commandName(args),
+		Args:     redactArgs(args),
+		Err:      err,
+		Stdout:   stdout,
+		Stderr:   stderr,
+		ExitCode: -1,
+		Duration: duration,
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		zerr.ExitCode = exitErr.ExitCode()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		zerr.Err = errors.Join(ctxErr, err)
+	}
+	return zerr
+}
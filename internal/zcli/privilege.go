@@ -0,0 +1,89 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/vpnhelper"
+)
+
+// Ways VPN commands get the root privileges they need, in order of preference
+const (
+	PrivilegeHelper = "helper" // the zerops-vpn-helper daemon runs them
+	PrivilegeRoot   = "root"   // the server itself runs as root
+	PrivilegeSudo   = "sudo"   // sudo runs zcli without asking for a password
+	PrivilegeNone   = "none"
+)
+
+// ErrPrivilegeRequired is returned by VPN commands when no non-interactive way to
+// run them as root is available
+var ErrPrivilegeRequired = errors.New("VPN commands need root privileges, but no VPN helper is running and sudo requires a password")
+
+// SetVPNHelper sets the socket of the VPN helper; empty disables the helper
+func (z *ZCLIWrapper) SetVPNHelper(socketPath string) {
+	if socketPath == "" {
+		z.helper = nil
+		return
+	}
+	z.helper = vpnhelper.NewClient(socketPath)
+}
+
+// VPNHelperSocket returns the socket of the configured VPN helper, if any
+func (z *ZCLIWrapper) VPNHelperSocket() string {
+	if z.helper == nil {
+		return ""
+	}
+	return z.helper.SocketPath()
+}
+
+// VPNPrivilege detects how VPN commands can be run as root without a prompt
+func (z *ZCLIWrapper) VPNPrivilege(ctx context.Context) string {
+	switch {
+	case z.helper != nil && z.helper.Available(ctx):
+		return PrivilegeHelper
+	case os.Geteuid() == 0:
+		return PrivilegeRoot
+	case sudoNonInteractive(ctx):
+		return PrivilegeSudo
+	default:
+		return PrivilegeNone
+	}
+}
+
+// vpnCommand runs 'zcli vpn up' for a project or 'zcli vpn down' with root
+// privileges, never prompting for a password
+func (z *ZCLIWrapper) vpnCommand(ctx context.Context, command, projectID string) (string, error) {
+	args := []string{"vpn", "down"}
+	if command == vpnhelper.CommandUp {
+		args = []string{"vpn", "up", "--projectId", projectID}
+	}
+
+	switch z.VPNPrivilege(ctx) {
+	case PrivilegeHelper:
+		if command == vpnhelper.CommandUp {
+			return z.helper.Up(ctx, projectID)
+		}
+		return z.helper.Down(ctx)
+	case PrivilegeRoot:
+		// Run directly, zcli uses the profile's config directory; log in there first
+		if _, err := z.EnsureLogin(ctx); err != nil {
+			return "", err
+		}
+		return z.Execute(ctx, args...)
+	case PrivilegeSudo:
+		return z.ExecuteSudo(ctx, args...)
+	default:
+		return "", ErrPrivilegeRequired
+	}
+}
+
+// sudoNonInteractive reports whether sudo may run zcli without a password. 'sudo -n -l'
+// only checks the sudoers policy, so nothing runs and nothing prompts.
+func sudoNonInteractive(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "sudo", "-n", "-l", "zcli").Run() == nil
+}
@@ -0,0 +1,143 @@
+package zcli
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Stage is a deploy milestone recognized in zcli push output
+type Stage string
+
+// Deploy milestones in pipeline order
+const (
+	StagePackaging Stage = "packaging"
+	StageUploading Stage = "uploading"
+	StageUploaded  Stage = "uploaded"
+	StageBuilding  Stage = "building"
+	StageDeployed  Stage = "deployed"
+)
+
+// stagePatterns recognizes milestones in zcli push output, in pipeline order
+var stagePatterns = []struct {
+	stage       Stage
+	description string
+	re          *regexp.Regexp
+}{
+	{StagePackaging, "Packaging application", regexp.MustCompile(`(?i)creating package|packing (the )?(application|files)`)},
+	{StageUploading, "Uploading package", regexp.MustCompile(`(?i)uploading (the )?package`)},
+	{StageUploaded, "Package uploaded", regexp.MustCompile(`(?i)package (was )?uploaded|upload (finished|done|completed)`)},
+	{StageBuilding, "Build started", regexp.MustCompile(`(?i)deploying service|build (pipeline )?(started|running)|(running|starting) (the )?build`)},
+	{StageDeployed, "Application deployed", regexp.MustCompile(`(?i)service deployed|(deploy|push)(ment)? (finished|successful|completed)|successfully deployed`)},
+}
+
+// Description returns a human-readable description of the stage
+func (s Stage) Description() string {
+	for _, p := range stagePatterns {
+		if p.stage == s {
+			return p.description
+		}
+	}
+	return string(s)
+}
+
+// Order returns the position of the stage in the deploy pipeline
+func (s Stage) Order() int {
+	for i, p := range stagePatterns {
+		if p.stage == s {
+			return i
+		}
+	}
+	return len(stagePatterns)
+}
+
+// OutputLine is a single line printed by zcli
+type OutputLine struct {
+	Stream string `json:"stream"` // "stdout" or "stderr"
+	Text   string `json:"text"`
+}
+
+// PushProgress reports a line of zcli push output and the milestone it marks, if any
+type PushProgress struct {
+	Line  OutputLine `json:"line"`
+	Stage Stage      `json:"stage,omitempty"`
+}
+
+// StageTracker detects milestones in output lines. Each milestone is reported once;
+// stdout and stderr are read concurrently, so milestones may arrive out of order.
+type StageTracker struct {
+	seen map[Stage]bool
+}
+
+// Observe returns the milestone a line reaches, if any
+func (t *StageTracker) Observe(line string) (Stage, bool) {
+	for i := len(stagePatterns) - 1; i >= 0; i-- {
+		p := stagePatterns[i]
+		if t.seen[p.stage] || !p.re.MatchString(line) {
+			continue
+		}
+		if t.seen == nil {
+			t.seen = make(map[Stage]bool)
+		}
+		t.seen[p.stage] = true
+		return p.stage, true
+	}
+	return "", false
+}
+
+// ansiPattern matches terminal escape sequences zcli uses for colors and spinners
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// lineWriter collects command output and reports each complete line as it is written.
+// Carriage returns end a line too, so spinner updates are reported as they happen.
+type lineWriter struct {
+	stream  string
+	mu      *sync.Mutex // shared by the stdout and stderr writers of a command
+	output  bytes.Buffer
+	partial []byte
+	last    string
+	onLine  func(OutputLine)
+}
+
+// Write implements io.Writer
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.output.Write(p)
+	if w.onLine == nil {
+		return len(p), nil
+	}
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexAny(w.partial, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.emit(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush reports a trailing line without a newline
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.onLine != nil && len(w.partial) > 0 {
+		w.emit(string(w.partial))
+	}
+	w.partial = nil
+}
+
+// emit reports a non-empty line with escape sequences removed, skipping
+// repeats of the previous line such as redrawn spinners
+func (w *lineWriter) emit(text string) {
+	text = strings.TrimSpace(ansiPattern.ReplaceAllString(text, ""))
+	if text == "" || text == w.last {
+		return
+	}
+	w.last = text
+	w.onLine(OutputLine{Stream: w.stream, Text: text})
+}
@@ -0,0 +1,108 @@
+package zcli
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestStageTrackerObserve(t *testing.T) {
+	lines := []string{
+		"Creating package",
+		"Uploading package",
+		"Uploading package 50%",
+		"Package was uploaded",
+		"Deploying service",
+		"Build pipeline started",
+		"npm run build",
+		"Service deployed",
+	}
+
+	var tracker StageTracker
+	var stages []Stage
+	for _, line := range lines {
+		if stage, ok := tracker.Observe(line); ok {
+			stages = append(stages, stage)
+		}
+	}
+
+	want := []Stage{StagePackaging, StageUploading, StageUploaded, StageBuilding, StageDeployed}
+	if !slices.Equal(stages, want) {
+		t.Errorf("stages = %v, want %v", stages, want)
+	}
+}
+
+func TestStageTrackerPrefersLaterStage(t *testing.T) {
+	var tracker StageTracker
+
+	// "upload finished" must not be read as the earlier uploading stage
+	if stage, ok := tracker.Observe("upload finished, deployment successful"); !ok || stage != StageDeployed {
+		t.Errorf("Observe = %q, %v; want %q", stage, ok, StageDeployed)
+	}
+	if stage, ok := tracker.Observe("upload finished"); !ok || stage != StageUploaded {
+		t.Errorf("Observe = %q, %v; want %q", stage, ok, StageUploaded)
+	}
+}
+
+func TestStageOrderAndDescription(t *testing.T) {
+	if StagePackaging.Order() >= StageDeployed.Order() {
+		t.Error("packaging should come before deployed")
+	}
+	if Stage("unknown").Order() != len(stagePatterns) {
+		t.Error("unknown stages should sort last")
+	}
+	if StageUploaded.Description() != "Package uploaded" || Stage("custom").Description() != "custom" {
+		t.Errorf("descriptions = %q, %q", StageUploaded.Description(), Stage("custom").Description())
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []OutputLine
+	w := &lineWriter{stream: "stdout", mu: &sync.Mutex{}, onLine: func(line OutputLine) {
+		lines = append(lines, line)
+	}}
+
+	chunks := []string{
+		"Creating pack",
+		"age\n",
+		"\x1b[32m⠋ Uploading\x1b[0m\r\x1b[32m⠋ Uploading\x1b[0m\r",
+		"\n\n  \n",
+		"done",
+	}
+	for _, chunk := range chunks {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write = %d, %v", n, err)
+		}
+	}
+	w.flush()
+
+	var texts []string
+	for _, line := range lines {
+		if line.Stream != "stdout" {
+			t.Errorf("line %q reported on %q", line.Text, line.Stream)
+		}
+		texts = append(texts, line.Text)
+	}
+	want := []string{"Creating package", "⠋ Uploading", "done"}
+	if !slices.Equal(texts, want) {
+		t.Errorf("lines = %q, want %q", texts, want)
+	}
+
+	var raw string
+	for _, chunk := range chunks {
+		raw += chunk
+	}
+	if w.output.String() != raw {
+		t.Errorf("output = %q, want the raw output", w.output.String())
+	}
+}
+
+func TestLineWriterWithoutCallbackOnlyCollects(t *testing.T) {
+	w := &lineWriter{stream: "stderr", mu: &sync.Mutex{}}
+	w.Write([]byte("error: boom\npartial"))
+	w.flush()
+
+	if w.output.String() != "error: boom\npartial" || w.partial != nil {
+		t.Errorf("output = %q, partial = %q", w.output.String(), w.partial)
+	}
+}
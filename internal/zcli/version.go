@@ -0,0 +1,93 @@
+package zcli
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Support levels of a zcli version
+const (
+	VersionSupported   = "supported"
+	VersionUntested    = "untested"
+	VersionUnsupported = "unsupported"
+	VersionUnknown     = "unknown"
+)
+
+// versionMatrix lists which zcli releases the server works with, oldest first.
+// A range covers versions from min up to, but not including, the next range.
+var versionMatrix = []struct {
+	min    [3]int
+	status string
+	note   string
+}{
+	{[3]int{0, 0, 0}, VersionUnsupported, "zcli before v1 uses other commands ('zcli push <project> <service>', 'zcli vpn start'); upgrade to v1"},
+	{[3]int{1, 0, 0}, VersionSupported, "'zcli service push', 'zcli vpn up --projectId' and 'zcli login --regionUrl' are used"},
+	{[3]int{2, 0, 0}, VersionUntested, "newer major version; commands may have changed"},
+}
+
+// versionPattern finds a semantic version in 'zcli version' output
+var versionPattern = regexp.MustCompile(`v?(\d+)\.(\d+)\.(\d+)`)
+
+// VersionSupport describes how well the installed zcli is supported
+type VersionSupport struct {
+	Version string `json:"version"`
+	Status  string `json:"status"`
+	Note    string `json:"note,omitempty"`
+}
+
+// Message describes the support level in one sentence
+func (v VersionSupport) Message() string {
+	switch v.Status {
+	case VersionSupported:
+		return fmt.Sprintf("zcli %s is supported", v.Version)
+	case VersionUnknown:
+		return fmt.Sprintf("Could not determine the zcli version from %q", v.Version)
+	default:
+		return fmt.Sprintf("zcli %s is %s: %s", v.Version, v.Status, v.Note)
+	}
+}
+
+// CheckVersion looks up the output of 'zcli version' in the supported-versions matrix
+func CheckVersion(output string) VersionSupport {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return VersionSupport{Version: output, Status: VersionUnknown}
+	}
+	var v [3]int
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+
+	support := VersionSupport{Version: fmt.Sprintf("v%d.%d.%d", v[0], v[1], v[2]), Status: VersionUnknown}
+	for _, entry := range versionMatrix {
+		if compareVersions(v, entry.min) >= 0 {
+			support.Status = entry.status
+			support.Note = entry.note
+		}
+	}
+	return support
+}
+
+// VersionSupport reports the installed zcli version and whether it is supported
+func (z *ZCLIWrapper) VersionSupport(ctx context.Context) (VersionSupport, error) {
+	output, err := z.Version(ctx)
+	if err != nil {
+		return VersionSupport{Status: VersionUnknown}, err
+	}
+	return CheckVersion(output), nil
+}
+
+// compareVersions compares two versions component by component
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
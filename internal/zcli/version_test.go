@@ -0,0 +1,46 @@
+package zcli
+
+import "testing"
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		output      string
+		wantVersion string
+		wantStatus  string
+	}{
+		{"zcli version v1.0.30 (linux/amd64)", "v1.0.30", VersionSupported},
+		{"1.2.0", "v1.2.0", VersionSupported},
+		{"zcli v0.12.4", "v0.12.4", VersionUnsupported},
+		{"zcli v2.0.0-beta", "v2.0.0", VersionUntested},
+		{"v10.1.1", "v10.1.1", VersionUntested},
+		{"development build", "development build", VersionUnknown},
+	}
+
+	for _, tt := range tests {
+		got := CheckVersion(tt.output)
+		if got.Version != tt.wantVersion || got.Status != tt.wantStatus {
+			t.Errorf("CheckVersion(%q) = %s %s, want %s %s", tt.output, got.Version, got.Status, tt.wantVersion, tt.wantStatus)
+		}
+		if got.Status != VersionSupported && got.Status != VersionUnknown && got.Note == "" {
+			t.Errorf("CheckVersion(%q) has no note explaining the %s status", tt.output, got.Status)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b [3]int
+		want int
+	}{
+		{[3]int{1, 0, 0}, [3]int{1, 0, 0}, 0},
+		{[3]int{1, 2, 0}, [3]int{1, 10, 0}, -1},
+		{[3]int{2, 0, 0}, [3]int{1, 99, 99}, 1},
+		{[3]int{1, 0, 1}, [3]int{1, 0, 0}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package zcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sysClassNet is where Linux exposes network interfaces
+var sysClassNet = "/sys/class/net"
+
+// handshakeTimeout is how old the latest WireGuard handshake may be before the
+// tunnel is considered stale. zcli tunnels send keepalives, so a healthy tunnel
+// handshakes every two minutes.
+const handshakeTimeout = 3 * time.Minute
+
+// Ways the tunnel state can be detected
+const (
+	DetectInterface = "interface" // the interface zcli vpn up created, recorded in the session
+	DetectSysfs     = "sysfs"     // WireGuard device named after Zerops in /sys/class/net
+	DetectSession   = "session"   // nothing to inspect; only the session file is known
+)
+
+// Tunnel states
+const (
+	TunnelUp      = "up"
+	TunnelDown    = "down"
+	TunnelUnknown = "unknown" // the tunnel cannot be inspected
+)
+
+// VPNSession records which project the tunnel was brought up for. It is persisted
+// so the project is still known after the server restarts.
+type VPNSession struct {
+	ProjectID   string    `json:"projectId"`
+	Interface   string    `json:"interface,omitempty"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// TunnelStatus is the detected state of the VPN tunnel
+type TunnelStatus struct {
+	State         string      `json:"state"`
+	Up            bool        `json:"up"`
+	Interface     string      `json:"interface,omitempty"`
+	Method        string      `json:"method,omitempty"`
+	LastHandshake *time.Time  `json:"lastHandshake,omitempty"`
+	Stale         bool        `json:"stale"`
+	Session       *VPNSession `json:"session,omitempty"`
+}
+
+// Message describes the tunnel state in one sentence
+func (t TunnelStatus) Message() string {
+	switch {
+	case t.State == TunnelUnknown && t.Session != nil:
+		return fmt.Sprintf("VPN state is unknown (a session for project %s is recorded but its tunnel cannot be inspected)", t.Session.ProjectID)
+	case t.State == TunnelUnknown:
+		return "VPN state is unknown (the tunnel cannot be inspected)"
+	case !t.Up && t.Session != nil:
+		return fmt.Sprintf("VPN is not connected (a session for project %s is recorded but its tunnel interface was not found)", t.Session.ProjectID)
+	case !t.Up:
+		return "VPN is not connected"
+	case t.Stale && t.LastHandshake == nil:
+		return fmt.Sprintf("VPN interface %s is up but has never completed a handshake", t.Interface)
+	case t.Stale:
+		return fmt.Sprintf("VPN interface %s is up but the last handshake was %s ago", t.Interface, time.Since(*t.LastHandshake).Round(time.Second))
+	default:
+		return fmt.Sprintf("VPN is connected via %s", t.Interface)
+	}
+}
+
+// DNSProbe is the result of resolving a project service through the VPN's DNS
+type DNSProbe struct {
+	Hostname  string        `json:"hostname"`
+	Addresses []string      `json:"addresses,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// OK reports whether the hostname resolved
+func (p DNSProbe) OK() bool {
+	return p.Error == ""
+}
+
+// DefaultVPNStatePath returns the default location of the VPN session file
+func DefaultVPNStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "zerops-mcp", "vpn.json")
+}
+
+// Tunnel detects the state of the VPN tunnel. Only the interface zcli vpn up
+// created counts: the one recorded in the session, or on Linux a WireGuard
+// device named after Zerops when no interface was recorded.
+func (z *ZCLIWrapper) Tunnel(ctx context.Context) TunnelStatus {
+	session, _ := z.LoadSession()
+	status := TunnelStatus{State: TunnelUnknown, Session: session}
+
+	switch {
+	case session != nil && session.Interface != "":
+		status.Method = DetectInterface
+		if up, err := interfaceUp(session.Interface); err == nil {
+			status.State = TunnelDown
+			if up {
+				status.State = TunnelUp
+				status.Interface = session.Interface
+			}
+		}
+	case runtime.GOOS == "linux":
+		status.Method = DetectSysfs
+		if ifaces, err := wireguardInterfaces(); err == nil {
+			status.State = TunnelDown
+			if iface := zeropsInterface(ifaces); iface != "" {
+				status.State = TunnelUp
+				status.Interface = iface
+			} else if session != nil && len(ifaces) > 0 {
+				// Another WireGuard device may be the session's tunnel
+				status.State = TunnelUnknown
+			}
+		}
+	case session == nil:
+		// Nothing was connected by this server and there is no interface to check
+		status.State = TunnelDown
+		status.Method = DetectSession
+	default:
+		status.Method = DetectSession
+	}
+	status.Up = status.State == TunnelUp
+
+	if status.Up {
+		if handshake, ok := latestHandshake(ctx, status.Interface); ok {
+			status.LastHandshake = handshake
+			status.Stale = handshake == nil || time.Since(*handshake) > handshakeTimeout
+		}
+	}
+	return status
+}
+
+// ProbeDNS resolves a service hostname of the connected project through the VPN's DNS.
+// Both the short hostname and its .zerops form are tried.
+func (z *ZCLIWrapper) ProbeDNS(ctx context.Context, hostname string) DNSProbe {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	probe := DNSProbe{Hostname: hostname}
+	var lastErr error
+	for _, name := range []string{hostname + ".zerops", hostname} {
+		addrs, err := net.DefaultResolver.LookupHost(ctx, name)
+		if err == nil && len(addrs) > 0 {
+			probe.Hostname = name
+			probe.Addresses = addrs
+			probe.Duration = time.Since(start)
+			return probe
+		}
+		lastErr = err
+	}
+	probe.Duration = time.Since(start)
+	if lastErr != nil {
+		probe.Error = lastErr.Error()
+	} else {
+		probe.Error = "no addresses returned"
+	}
+	return probe
+}
+
+// LoadSession reads the persisted VPN session; it returns nil when there is none
+func (z *ZCLIWrapper) LoadSession() (*VPNSession, error) {
+	if z.statePath == "" {
+		return nil, nil
+	}
+	z.sessionMu.RLock()
+	defer z.sessionMu.RUnlock()
+	data, err := os.ReadFile(z.statePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VPN state: %w", err)
+	}
+	var session VPNSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse VPN state %s: %w", z.statePath, err)
+	}
+	if session.ProjectID == "" {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// saveSession persists the VPN session
+func (z *ZCLIWrapper) saveSession(session VPNSession) error {
+	if z.statePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	z.sessionMu.Lock()
+	defer z.sessionMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(z.statePath), 0o700); err != nil {
+		return fmt.Errorf("failed to write VPN state: %w", err)
+	}
+	if err := os.WriteFile(z.statePath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write VPN state: %w", err)
+	}
+	return nil
+}
+
+// clearSession removes the persisted VPN session
+func (z *ZCLIWrapper) clearSession() error {
+	if z.statePath == "" {
+		return nil
+	}
+	z.sessionMu.Lock()
+	defer z.sessionMu.Unlock()
+	if err := os.Remove(z.statePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove VPN state: %w", err)
+	}
+	return nil
+}
+
+// wireguardInterfaces lists WireGuard devices from sysfs
+func wireguardInterfaces() ([]string, error) {
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return nil, err
+	}
+	var ifaces []string
+	for _, entry := range entries {
+		uevent, err := os.ReadFile(filepath.Join(sysClassNet, entry.Name(), "uevent"))
+		if err != nil || !strings.Contains(string(uevent), "DEVTYPE=wireguard") {
+			continue
+		}
+		operstate, _ := os.ReadFile(filepath.Join(sysClassNet, entry.Name(), "operstate"))
+		if strings.TrimSpace(string(operstate)) == "down" {
+			continue
+		}
+		ifaces = append(ifaces, entry.Name())
+	}
+	return ifaces, nil
+}
+
+// netInterfaces lists the network interfaces of the host
+var netInterfaces = net.Interfaces
+
+// interfaceNames returns the names of the network interfaces of the host
+func interfaceNames() ([]string, error) {
+	ifaces, err := netInterfaces()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ifaces))
+	for _, iface := range ifaces {
+		names = append(names, iface.Name)
+	}
+	return names, nil
+}
+
+// interfaceUp reports whether a network interface exists and is up
+func interfaceUp(name string) (bool, error) {
+	ifaces, err := netInterfaces()
+	if err != nil {
+		return false, err
+	}
+	for _, iface := range ifaces {
+		if iface.Name == name {
+			return iface.Flags&net.FlagUp != 0, nil
+		}
+	}
+	return false, nil
+}
+
+// zeropsInterface returns the interface named after Zerops, if any
+func zeropsInterface(ifaces []string) string {
+	for _, iface := range ifaces {
+		if strings.Contains(iface, "zerops") {
+			return iface
+		}
+	}
+	return ""
+}
+
+// createdInterface returns the interface that appeared between two listings,
+// preferring one named after Zerops. Without a new interface it falls back to
+// an existing one named after Zerops.
+func createdInterface(before, after []string) string {
+	existing := make(map[string]bool, len(before))
+	for _, name := range before {
+		existing[name] = true
+	}
+	var created []string
+	for _, name := range after {
+		if !existing[name] {
+			created = append(created, name)
+		}
+	}
+	if iface := zeropsInterface(created); iface != "" {
+		return iface
+	}
+	if len(created) > 0 {
+		sort.Strings(created)
+		return created[0]
+	}
+	return zeropsInterface(after)
+}
+
+// latestHandshake reads the most recent peer handshake of an interface using
+// 'wg show'. ok is false when it cannot be read (wg missing or not permitted);
+// a nil time means no handshake has happened yet.
+func latestHandshake(ctx context.Context, iface string) (handshake *time.Time, ok bool) {
+	out, err := exec.CommandContext(ctx, "wg", "show", iface, "latest-handshakes").Output()
+	if err != nil {
+		return nil, false
+	}
+	var latest int64
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if ts, err := strconv.ParseInt(fields[1], 10, 64); err == nil && ts > latest {
+			latest = ts
+		}
+	}
+	if latest == 0 {
+		return nil, true
+	}
+	t := time.Unix(latest, 0)
+	return &t, true
+}
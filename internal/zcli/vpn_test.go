@@ -0,0 +1,145 @@
+package zcli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fakeInterfaces replaces the host's interfaces for the duration of a test
+func fakeInterfaces(t *testing.T, ifaces []net.Interface, err error) {
+	t.Helper()
+	previous := netInterfaces
+	netInterfaces = func() ([]net.Interface, error) { return ifaces, err }
+	t.Cleanup(func() { netInterfaces = previous })
+}
+
+// fakeSysfs replaces /sys/class/net with devices of the given types
+func fakeSysfs(t *testing.T, devices map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, devtype := range devices {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "uevent"), []byte("DEVTYPE="+devtype+"\nINTERFACE="+name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous := sysClassNet
+	sysClassNet = dir
+	t.Cleanup(func() { sysClassNet = previous })
+}
+
+// wrapperWithSession returns a wrapper whose persisted session is session, if any
+func wrapperWithSession(t *testing.T, session *VPNSession) *ZCLIWrapper {
+	t.Helper()
+	z := NewWithConfig(false, 0)
+	z.SetStatePath(filepath.Join(t.TempDir(), "vpn.json"))
+	if session != nil {
+		if err := z.saveSession(*session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return z
+}
+
+func TestCreatedInterface(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after []string
+		want          string
+	}{
+		{"new interface", []string{"lo", "eth0", "wg0"}, []string{"lo", "eth0", "wg0", "utun4"}, "utun4"},
+		{"prefers the one named after Zerops", []string{"lo"}, []string{"lo", "docker0", "zerops"}, "zerops"},
+		{"existing Zerops interface", []string{"lo", "zerops"}, []string{"lo", "zerops"}, "zerops"},
+		{"unrelated WireGuard device is not taken", []string{"lo", "wg0"}, []string{"lo", "wg0"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := createdInterface(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: createdInterface = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTunnelUsesRecordedInterface(t *testing.T) {
+	session := &VPNSession{ProjectID: "project-1", Interface: "utun4", ConnectedAt: time.Now()}
+
+	fakeInterfaces(t, []net.Interface{{Name: "lo", Flags: net.FlagUp}, {Name: "utun4", Flags: net.FlagUp}}, nil)
+	tunnel := wrapperWithSession(t, session).Tunnel(context.Background())
+	if tunnel.State != TunnelUp || tunnel.Interface != "utun4" || tunnel.Method != DetectInterface {
+		t.Errorf("Tunnel = %+v, want utun4 up", tunnel)
+	}
+
+	// Another WireGuard device does not stand in for the recorded one
+	fakeInterfaces(t, []net.Interface{{Name: "lo", Flags: net.FlagUp}, {Name: "wg0", Flags: net.FlagUp}}, nil)
+	if tunnel := wrapperWithSession(t, session).Tunnel(context.Background()); tunnel.State != TunnelDown || tunnel.Up {
+		t.Errorf("Tunnel = %+v, want down once the recorded interface is gone", tunnel)
+	}
+
+	fakeInterfaces(t, nil, errors.New("netlink: permission denied"))
+	if tunnel := wrapperWithSession(t, session).Tunnel(context.Background()); tunnel.State != TunnelUnknown || tunnel.Up {
+		t.Errorf("Tunnel = %+v, want unknown when interfaces cannot be listed", tunnel)
+	}
+}
+
+func TestTunnelWithoutRecordedInterface(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		session := &VPNSession{ProjectID: "project-1", ConnectedAt: time.Now()}
+		if tunnel := wrapperWithSession(t, session).Tunnel(context.Background()); tunnel.State != TunnelUnknown {
+			t.Errorf("Tunnel = %+v, want unknown when only the session is known", tunnel)
+		}
+		return
+	}
+
+	tests := []struct {
+		name    string
+		devices map[string]string
+		session *VPNSession
+		want    string
+	}{
+		{"Zerops WireGuard device", map[string]string{"eth0": "", "zerops": "wireguard"}, nil, TunnelUp},
+		{"unrelated WireGuard device", map[string]string{"wg0": "wireguard"}, nil, TunnelDown},
+		{"unrelated device with a session", map[string]string{"wg0": "wireguard"}, &VPNSession{ProjectID: "project-1"}, TunnelUnknown},
+		{"no WireGuard device", map[string]string{"eth0": ""}, &VPNSession{ProjectID: "project-1"}, TunnelDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeSysfs(t, tt.devices)
+			tunnel := wrapperWithSession(t, tt.session).Tunnel(context.Background())
+			if tunnel.State != tt.want || tunnel.Up != (tt.want == TunnelUp) {
+				t.Errorf("Tunnel = %+v, want %s", tunnel, tt.want)
+			}
+		})
+	}
+}
+
+func TestTunnelStatusMessage(t *testing.T) {
+	handshake := time.Now().Add(-10 * time.Minute)
+	session := &VPNSession{ProjectID: "project-1"}
+
+	tests := []struct {
+		status TunnelStatus
+		want   string
+	}{
+		{TunnelStatus{State: TunnelDown}, "VPN is not connected"},
+		{TunnelStatus{State: TunnelUnknown}, "VPN state is unknown (the tunnel cannot be inspected)"},
+		{TunnelStatus{State: TunnelUnknown, Session: session}, "VPN state is unknown (a session for project project-1 is recorded but its tunnel cannot be inspected)"},
+		{TunnelStatus{State: TunnelUp, Up: true, Interface: "zerops"}, "VPN is connected via zerops"},
+		{TunnelStatus{State: TunnelUp, Up: true, Interface: "zerops", Stale: true}, "VPN interface zerops is up but has never completed a handshake"},
+		{TunnelStatus{State: TunnelUp, Up: true, Interface: "zerops", Stale: true, LastHandshake: &handshake}, "VPN interface zerops is up but the last handshake was 10m0s ago"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.Message(); got != tt.want {
+			t.Errorf("Message() = %q, want %q", got, tt.want)
+		}
+	}
+}
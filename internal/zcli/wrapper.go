@@ -1,28 +1,44 @@
 package zcli
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/zeropsio/zerops-mcp-v3/internal/vpnhelper"
 )
 
 // ZCLIWrapper wraps the zcli command line tool
 type ZCLIWrapper struct {
-	debug              bool
-	vpnWaitTime        time.Duration
-	connectedProjectID string // Track the connected project ID
+	debug       bool
+	vpnWaitTime time.Duration
+	statePath   string // VPN session file; empty disables persistence
+	stopTimeout time.Duration
+	sessionMu   sync.RWMutex // guards the VPN session file
+	helper      *vpnhelper.Client
+	timeouts    map[string]time.Duration // per-command overrides of DefaultTimeouts
+
+	accounts       map[string]Account // zcli login per profile; empty keeps the user's own
+	currentProfile func(ctx context.Context) string
+	loginMu        sync.Mutex
 }
 
+// defaultStopTimeout is how long a cancelled zcli command may take to exit before it is killed
+const defaultStopTimeout = 5 * time.Second
+
 // New creates a new zcli wrapper
 func New(debug bool) *ZCLIWrapper {
 	return &ZCLIWrapper{
 		debug:       debug,
 		vpnWaitTime: 2 * time.Second, // default
+		statePath:   DefaultVPNStatePath(),
+		stopTimeout: defaultStopTimeout,
+		helper:      vpnhelper.NewClient(vpnhelper.DefaultSocketPath),
 	}
 }
 
@@ -31,25 +47,39 @@ func NewWithConfig(debug bool, vpnWaitTime time.Duration) *ZCLIWrapper {
 	return &ZCLIWrapper{
 		debug:       debug,
 		vpnWaitTime: vpnWaitTime,
+		statePath:   DefaultVPNStatePath(),
+		stopTimeout: defaultStopTimeout,
+		helper:      vpnhelper.NewClient(vpnhelper.DefaultSocketPath),
 	}
 }
 
+// SetStatePath sets the file the VPN session is persisted to; empty disables persistence
+func (z *ZCLIWrapper) SetStatePath(path string) {
+	z.statePath = path
+}
+
 // Execute runs a zcli command without sudo
 func (z *ZCLIWrapper) Execute(ctx context.Context, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, "zcli", args...)
-	return z.runCommand(cmd)
+	ctx, cancel := z.withTimeout(ctx, args)
+	defer cancel()
+	return z.runCommand(ctx, z.command(ctx, args...), args)
 }
 
-// ExecuteSudo runs a zcli command with sudo (for VPN operations)
+// ExecuteSudo runs a zcli command with sudo (for VPN operations). sudo never
+// prompts: without passwordless sudo for zcli the command fails.
 func (z *ZCLIWrapper) ExecuteSudo(ctx context.Context, args ...string) (string, error) {
-	cmdArgs := append([]string{"zcli"}, args...)
+	ctx, cancel := z.withTimeout(ctx, args)
+	defer cancel()
+	cmdArgs := append([]string{"-n", "zcli"}, args...)
 	cmd := exec.CommandContext(ctx, "sudo", cmdArgs...)
-	return z.runCommand(cmd)
+	return z.runCommand(ctx, cmd, args)
 }
 
-// VPNConnect connects to Zerops VPN
+// VPNConnect connects to Zerops VPN and records the interface zcli created for it
 func (z *ZCLIWrapper) VPNConnect(ctx context.Context, projectID string) error {
-	output, err := z.ExecuteSudo(ctx, "vpn", "up", "--projectId", projectID)
+	before, beforeErr := interfaceNames()
+
+	output, err := z.vpnCommand(ctx, vpnhelper.CommandUp, projectID)
 	if err != nil {
 		return fmt.Errorf("failed to connect VPN: %w\nOutput: %s", err, output)
 	}
@@ -57,67 +87,56 @@ func (z *ZCLIWrapper) VPNConnect(ctx context.Context, projectID string) error {
 	// Wait for connection to establish
 	time.Sleep(z.vpnWaitTime)
 
-	// Verify connection
-	if !z.IsVPNConnected(ctx) {
-		return fmt.Errorf("VPN connection failed to establish")
+	// The tunnel is the interface that appeared while connecting. When the
+	// interfaces cannot be listed, no interface is recorded and the state is unknown.
+	iface := ""
+	if after, err := interfaceNames(); err == nil && beforeErr == nil {
+		iface = createdInterface(before, after)
+		if iface == "" {
+			return fmt.Errorf("VPN connection failed to establish: zcli vpn up created no network interface")
+		}
 	}
 
-	// Store the connected project ID
-	z.connectedProjectID = projectID
-
-	return nil
+	// Record which project the tunnel belongs to
+	return z.saveSession(VPNSession{
+		ProjectID:   projectID,
+		Interface:   iface,
+		ConnectedAt: time.Now(),
+	})
 }
 
 // VPNDisconnect disconnects from Zerops VPN
 func (z *ZCLIWrapper) VPNDisconnect(ctx context.Context) error {
-	output, err := z.ExecuteSudo(ctx, "vpn", "down")
+	output, err := z.vpnCommand(ctx, vpnhelper.CommandDown, "")
 	if err != nil {
 		return fmt.Errorf("failed to disconnect VPN: %w\nOutput: %s", err, output)
 	}
-	// Clear the connected project ID
-	z.connectedProjectID = ""
-	return nil
+	return z.clearSession()
 }
 
-// IsVPNConnected checks if VPN is connected by looking for WireGuard interface
+// IsVPNConnected checks if the VPN tunnel is up and not stale
 func (z *ZCLIWrapper) IsVPNConnected(ctx context.Context) bool {
-	// Check if WireGuard interface exists (common method)
-	cmd := exec.CommandContext(ctx, "ifconfig", "utun4")
-	if err := cmd.Run(); err == nil {
-		return true
-	}
-	
-	// Alternative: check for zerops VPN config file
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		vpnConfig := filepath.Join(homeDir, ".zerops", "vpn", "wg0.conf")
-		if _, err := os.Stat(vpnConfig); err == nil {
-			// Config exists, might be connected
-			// This is not definitive but gives a hint
-			return true
-		}
-	}
-	
-	return false
+	tunnel := z.Tunnel(ctx)
+	return tunnel.Up && !tunnel.Stale
 }
 
 // VPNStatus gets detailed VPN status
 func (z *ZCLIWrapper) VPNStatus(ctx context.Context) (connected bool, projectID string, message string) {
-	// Since zcli doesn't have a status command, we check connectivity differently
-	connected = z.IsVPNConnected(ctx)
-	
-	if connected {
-		message = "VPN appears to be connected"
-		projectID = z.connectedProjectID
-	} else {
-		message = "VPN is not connected"
+	tunnel := z.Tunnel(ctx)
+	if tunnel.Up && tunnel.Session != nil {
+		projectID = tunnel.Session.ProjectID
 	}
-	
-	return connected, projectID, message
+	return tunnel.Up, projectID, tunnel.Message()
 }
 
 // Push deploys code to Zerops
 func (z *ZCLIWrapper) Push(ctx context.Context, projectID, serviceName, workDir, configPath string) (string, error) {
+	return z.PushStream(ctx, projectID, serviceName, workDir, configPath, nil)
+}
+
+// PushStream deploys code to Zerops, calling onProgress for every line zcli prints
+// as it is printed. Cancelling ctx stops zcli.
+func (z *ZCLIWrapper) PushStream(ctx context.Context, projectID, serviceName, workDir, configPath string, onProgress func(PushProgress)) (string, error) {
 	// zcli push is actually an alias for 'zcli service push'
 	args := []string{"service", "push"}
 	
@@ -139,10 +158,22 @@ func (z *ZCLIWrapper) Push(ctx context.Context, projectID, serviceName, workDir,
 		args = append(args, "--zeropsYamlPath", configPath)
 	}
 
-	cmd := exec.CommandContext(ctx, "zcli", args...)
+	ctx, cancel := z.withTimeout(ctx, args)
+	defer cancel()
+	cmd := z.command(ctx, args...)
 	// Don't set cmd.Dir - let zcli handle the working directory via --workingDir flag
 	
-	return z.runCommand(cmd)
+	if onProgress == nil {
+		return z.runCommand(ctx, cmd, args)
+	}
+	tracker := &StageTracker{}
+	return z.streamCommand(ctx, cmd, args, func(line OutputLine) {
+		progress := PushProgress{Line: line}
+		if stage, ok := tracker.Observe(line.Text); ok {
+			progress.Stage = stage
+		}
+		onProgress(progress)
+	})
 }
 
 // ValidateConfig validates a zerops.yml configuration
@@ -169,18 +200,36 @@ func (z *ZCLIWrapper) IsInstalled() bool {
 }
 
 // runCommand executes a command and returns output
-func (z *ZCLIWrapper) runCommand(cmd *exec.Cmd) (string, error) {
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+func (z *ZCLIWrapper) runCommand(ctx context.Context, cmd *exec.Cmd, args []string) (string, error) {
+	return z.streamCommand(ctx, cmd, args, nil)
+}
+
+// streamCommand executes a command, calling onLine for every output line as it is
+// printed, and returns its output. When ctx, the command's context, ends, zcli is
+// interrupted and killed if it does not exit within the stop timeout. Failures are
+// returned as *errors.ZCLIError; args are the zcli arguments of the command.
+func (z *ZCLIWrapper) streamCommand(ctx context.Context, cmd *exec.Cmd, args []string, onLine func(OutputLine)) (string, error) {
+	var mu sync.Mutex
+	stdout := &lineWriter{stream: "stdout", mu: &mu, onLine: onLine}
+	stderr := &lineWriter{stream: "stderr", mu: &mu, onLine: onLine}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = z.stopTimeout
 
 	if z.debug {
-		fmt.Printf("Running: %s\n", strings.Join(cmd.Args, " "))
+		// stdout carries the MCP stdio transport, so debug output goes to the log (stderr)
+		log.Printf("[DEBUG] Running: %s", strings.Join(redactArgs(cmd.Args), " "))
 	}
 
+	start := time.Now()
 	err := cmd.Run()
-	output := stdout.String()
-	errOutput := stderr.String()
+	stdout.flush()
+	stderr.flush()
+	output := stdout.output.String()
+	errOutput := stderr.output.String()
 
 	if err != nil {
 		// Combine stdout and stderr for error cases
@@ -188,7 +237,7 @@ func (z *ZCLIWrapper) runCommand(cmd *exec.Cmd) (string, error) {
 		if errOutput != "" {
 			fullOutput += "\n" + errOutput
 		}
-		return fullOutput, err
+		return fullOutput, commandError(ctx, args, err, output, errOutput, time.Since(start))
 	}
 
 	return output, nil
@@ -196,11 +245,12 @@ func (z *ZCLIWrapper) runCommand(cmd *exec.Cmd) (string, error) {
 
 // GetConnectedProjectID returns the project ID if VPN is connected
 func (z *ZCLIWrapper) GetConnectedProjectID(ctx context.Context) (string, error) {
-	if !z.IsVPNConnected(ctx) {
+	tunnel := z.Tunnel(ctx)
+	if tunnel.State == TunnelDown {
 		return "", fmt.Errorf("VPN is not connected")
 	}
-	if z.connectedProjectID == "" {
+	if tunnel.Session == nil {
 		return "", fmt.Errorf("could not determine connected project ID")
 	}
-	return z.connectedProjectID, nil
-}
\ No newline at end of file
+	return tunnel.Session.ProjectID, nil
+}